@@ -69,6 +69,10 @@ func main() {
 	} else {
 		logger.Info("S3 Sync Disabled")
 	}
+	util.VulnerabilityDatabasePath = os.Getenv("OSV_DATABASE_PATH")
+	if util.VulnerabilityDatabasePath != "" {
+		logger.Info("Loading the vulnerability database from " + util.VulnerabilityDatabasePath)
+	}
 
 	mgr, err := controller.NewManager(restConfig, mopts)
 	if err != nil {
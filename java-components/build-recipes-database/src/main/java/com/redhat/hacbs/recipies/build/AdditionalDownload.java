@@ -4,6 +4,11 @@ public class AdditionalDownload {
 
     private String uri;
 
+    /**
+     * The sha256 of the downloaded file, required for every type except rpm. Rpms are installed by name from the
+     * package repositories of the builder image, so there is no file to check before the install, and the package
+     * manager verifies them against the repository signatures instead.
+     */
     private String sha256;
 
     /**
@@ -23,10 +28,26 @@ public class AdditionalDownload {
      *
      * executable
      * tar
+     * tar.gz
+     * tar.bz2
+     * tar.xz
+     * zip
      * rpm
+     * maven
      */
     private String type;
 
+    /**
+     * Only applies to archive types; the number of leading path components removed when extracting
+     */
+    private int stripComponents;
+
+    /**
+     * Only applies to maven type; the artifact to download in the form
+     * groupId:artifactId[:extension[:classifier]]:version
+     */
+    private String gav;
+
     public String getUri() {
         return uri;
     }
@@ -81,6 +102,76 @@ public class AdditionalDownload {
         return this;
     }
 
+    public int getStripComponents() {
+        return stripComponents;
+    }
+
+    public AdditionalDownload setStripComponents(int stripComponents) {
+        this.stripComponents = stripComponents;
+        return this;
+    }
+
+    public String getGav() {
+        return gav;
+    }
+
+    public AdditionalDownload setGav(String gav) {
+        this.gav = gav;
+        return this;
+    }
+
+    /**
+     * Checks that the download has everything required for its type. Everything except rpms needs a sha256, see
+     * {@link #sha256} for why rpms are the exception.
+     *
+     * @throws IllegalArgumentException if the download is not valid
+     */
+    public void validate() {
+        if (type == null) {
+            throw new IllegalArgumentException("No type specified for package " + uri);
+        }
+        switch (type) {
+            case "rpm":
+                if (packageName == null || packageName.isEmpty()) {
+                    throw new IllegalArgumentException("Package name not specified for rpm type");
+                }
+                return;
+            case "executable":
+                if (fileName == null || fileName.isEmpty()) {
+                    throw new IllegalArgumentException("File name not specified for package " + uri);
+                }
+                break;
+            case "tar":
+            case "tar.gz":
+            case "tar.bz2":
+            case "tar.xz":
+            case "zip":
+                if (binaryPath == null || binaryPath.isEmpty()) {
+                    throw new IllegalArgumentException("Binary path not specified for package " + uri);
+                }
+                if (stripComponents < 0) {
+                    throw new IllegalArgumentException("Strip components cannot be negative for package " + uri);
+                }
+                break;
+            case "maven":
+                if (fileName == null || fileName.isEmpty()) {
+                    throw new IllegalArgumentException("File name not specified for maven artifact " + gav);
+                }
+                if (gav == null || !gav.matches("[^:]+(:[^:]+){2,4}")) {
+                    throw new IllegalArgumentException("Invalid maven artifact " + gav);
+                }
+                break;
+            default:
+                throw new IllegalArgumentException("Unknown file type " + type + " for package " + uri);
+        }
+        if (!type.equals("maven") && (uri == null || uri.isEmpty())) {
+            throw new IllegalArgumentException("Uri not specified for " + type + " package");
+        }
+        if (sha256 == null || !sha256.matches("[a-f0-9]{64}")) {
+            throw new IllegalArgumentException("Missing or invalid sha256 for package " + (uri == null ? gav : uri));
+        }
+    }
+
     @Override
     public String toString() {
         return "AdditionalDownload{" +
@@ -90,6 +181,8 @@ public class AdditionalDownload {
                 ", binaryPath='" + binaryPath + '\'' +
                 ", packageName='" + packageName + '\'' +
                 ", type='" + type + '\'' +
+                ", stripComponents=" + stripComponents +
+                ", gav='" + gav + '\'' +
                 '}';
     }
 }
@@ -0,0 +1,51 @@
+package com.redhat.hacbs.recipies.build;
+
+/**
+ * A unified diff that is applied to the source before the build, either inline or downloaded from a URL.
+ */
+public class BuildPatch {
+
+    private String diff;
+
+    private String url;
+
+    /**
+     * Required if the patch is downloaded from a URL
+     */
+    private String sha256;
+
+    public String getDiff() {
+        return diff;
+    }
+
+    public BuildPatch setDiff(String diff) {
+        this.diff = diff;
+        return this;
+    }
+
+    public String getUrl() {
+        return url;
+    }
+
+    public BuildPatch setUrl(String url) {
+        this.url = url;
+        return this;
+    }
+
+    public String getSha256() {
+        return sha256;
+    }
+
+    public BuildPatch setSha256(String sha256) {
+        this.sha256 = sha256;
+        return this;
+    }
+
+    @Override
+    public String toString() {
+        return "BuildPatch{" +
+                "url='" + url + '\'' +
+                ", sha256='" + sha256 + '\'' +
+                '}';
+    }
+}
@@ -48,6 +48,31 @@ public class BuildRecipeInfo {
 
     List<String> allowedDifferences = new ArrayList<>();
 
+    /**
+     * Options that control how the source is cloned.
+     */
+    GitCloneOptions gitOptions;
+
+    /**
+     * Patches that are applied in order to the source after checkout.
+     */
+    List<BuildPatch> patches = new ArrayList<>();
+
+    /**
+     * Environment variables for the build, these must be allowed by the JBSConfig.
+     */
+    List<BuildEnvVar> env = new ArrayList<>();
+
+    /**
+     * Secrets mounted into the build, these must be allowed by the JBSConfig.
+     */
+    List<SecretMount> secretMounts = new ArrayList<>();
+
+    /**
+     * Overrides the settings that make the build environment reproducible.
+     */
+    ReproducibleBuildSettings reproducible;
+
     public List<String> getAdditionalArgs() {
         return additionalArgs;
     }
@@ -174,6 +199,51 @@ public class BuildRecipeInfo {
         return this;
     }
 
+    public GitCloneOptions getGitOptions() {
+        return gitOptions;
+    }
+
+    public BuildRecipeInfo setGitOptions(GitCloneOptions gitOptions) {
+        this.gitOptions = gitOptions;
+        return this;
+    }
+
+    public List<BuildPatch> getPatches() {
+        return patches;
+    }
+
+    public BuildRecipeInfo setPatches(List<BuildPatch> patches) {
+        this.patches = patches;
+        return this;
+    }
+
+    public List<BuildEnvVar> getEnv() {
+        return env;
+    }
+
+    public BuildRecipeInfo setEnv(List<BuildEnvVar> env) {
+        this.env = env;
+        return this;
+    }
+
+    public List<SecretMount> getSecretMounts() {
+        return secretMounts;
+    }
+
+    public BuildRecipeInfo setSecretMounts(List<SecretMount> secretMounts) {
+        this.secretMounts = secretMounts;
+        return this;
+    }
+
+    public ReproducibleBuildSettings getReproducible() {
+        return reproducible;
+    }
+
+    public BuildRecipeInfo setReproducible(ReproducibleBuildSettings reproducible) {
+        this.reproducible = reproducible;
+        return this;
+    }
+
     @Override
     public String toString() {
         return "BuildRecipeInfo{" +
@@ -190,6 +260,11 @@ public class BuildRecipeInfo {
                 ", additionalDownloads=" + additionalDownloads +
                 ", additionalBuilds=" + additionalBuilds +
                 ", allowedDifferences=" + allowedDifferences +
+                ", gitOptions=" + gitOptions +
+                ", patches=" + patches +
+                ", env=" + env +
+                ", secretMounts=" + secretMounts +
+                ", reproducible=" + reproducible +
                 '}';
     }
 }
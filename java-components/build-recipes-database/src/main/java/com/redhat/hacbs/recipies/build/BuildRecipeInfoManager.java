@@ -22,12 +22,28 @@ public class BuildRecipeInfoManager implements RecipeManager<BuildRecipeInfo> {
         if (buildRecipeInfo == null) {
             return new BuildRecipeInfo(); //can happen with empty files
         }
+        validate(buildRecipeInfo, file);
         return buildRecipeInfo;
     }
 
     @Override
     public void write(BuildRecipeInfo data, Path file)
             throws IOException {
+        validate(data, file);
         MAPPER.writeValue(file.toFile(), data);
     }
+
+    /**
+     * Invalid recipes are rejected when they are read or written, rather than failing when the build runs
+     */
+    private static void validate(BuildRecipeInfo buildRecipeInfo, Path file) throws IOException {
+        if (buildRecipeInfo.getAdditionalDownloads() == null) {
+            return;
+        }
+        try {
+            buildRecipeInfo.getAdditionalDownloads().forEach(AdditionalDownload::validate);
+        } catch (IllegalArgumentException e) {
+            throw new IOException("Invalid build recipe " + file + ": " + e.getMessage(), e);
+        }
+    }
 }
@@ -0,0 +1,56 @@
+package com.redhat.hacbs.recipies.build;
+
+public class GitCloneOptions {
+
+    /**
+     * If this is set the repository is fetched with the given depth, and the commit is fetched directly
+     * rather than cloning the full history.
+     */
+    private int depth;
+
+    /**
+     * If this is true only the context path of the build is checked out.
+     */
+    private boolean sparseCheckout;
+
+    /**
+     * If this is true git LFS content is pulled after checkout.
+     */
+    private boolean lfs;
+
+    public int getDepth() {
+        return depth;
+    }
+
+    public GitCloneOptions setDepth(int depth) {
+        this.depth = depth;
+        return this;
+    }
+
+    public boolean isSparseCheckout() {
+        return sparseCheckout;
+    }
+
+    public GitCloneOptions setSparseCheckout(boolean sparseCheckout) {
+        this.sparseCheckout = sparseCheckout;
+        return this;
+    }
+
+    public boolean isLfs() {
+        return lfs;
+    }
+
+    public GitCloneOptions setLfs(boolean lfs) {
+        this.lfs = lfs;
+        return this;
+    }
+
+    @Override
+    public String toString() {
+        return "GitCloneOptions{" +
+                "depth=" + depth +
+                ", sparseCheckout=" + sparseCheckout +
+                ", lfs=" + lfs +
+                '}';
+    }
+}
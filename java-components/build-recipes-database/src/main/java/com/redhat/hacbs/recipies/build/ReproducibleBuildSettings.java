@@ -0,0 +1,89 @@
+package com.redhat.hacbs.recipies.build;
+
+/**
+ * Overrides the settings that make the build environment stable, so the output is reproducible.
+ * SOURCE_DATE_EPOCH is always set to the commit time.
+ */
+public class ReproducibleBuildSettings {
+
+    /**
+     * The locale used for LANG and LC_ALL, defaults to en_US.UTF-8
+     */
+    private String locale;
+
+    /**
+     * The time zone, defaults to UTC
+     */
+    private String timeZone;
+
+    /**
+     * The umask, defaults to 0022
+     */
+    private String umask;
+
+    /**
+     * Don't set the output timestamp of the build tool to the commit time, for projects that set their own
+     */
+    private boolean disableOutputTimestamp;
+
+    /**
+     * Build twice and compare the output, even if the JBSConfig does not enable the reproducibility check
+     */
+    private boolean check;
+
+    public String getLocale() {
+        return locale;
+    }
+
+    public ReproducibleBuildSettings setLocale(String locale) {
+        this.locale = locale;
+        return this;
+    }
+
+    public String getTimeZone() {
+        return timeZone;
+    }
+
+    public ReproducibleBuildSettings setTimeZone(String timeZone) {
+        this.timeZone = timeZone;
+        return this;
+    }
+
+    public String getUmask() {
+        return umask;
+    }
+
+    public ReproducibleBuildSettings setUmask(String umask) {
+        this.umask = umask;
+        return this;
+    }
+
+    public boolean isDisableOutputTimestamp() {
+        return disableOutputTimestamp;
+    }
+
+    public ReproducibleBuildSettings setDisableOutputTimestamp(boolean disableOutputTimestamp) {
+        this.disableOutputTimestamp = disableOutputTimestamp;
+        return this;
+    }
+
+    public boolean isCheck() {
+        return check;
+    }
+
+    public ReproducibleBuildSettings setCheck(boolean check) {
+        this.check = check;
+        return this;
+    }
+
+    @Override
+    public String toString() {
+        return "ReproducibleBuildSettings{" +
+                "locale='" + locale + '\'' +
+                ", timeZone='" + timeZone + '\'' +
+                ", umask='" + umask + '\'' +
+                ", disableOutputTimestamp=" + disableOutputTimestamp +
+                ", check=" + check +
+                '}';
+    }
+}
@@ -0,0 +1,37 @@
+package com.redhat.hacbs.recipies.build;
+
+public class SecretMount {
+
+    private String secretName;
+
+    /**
+     * The directory the secret is mounted at
+     */
+    private String mountPath;
+
+    public String getSecretName() {
+        return secretName;
+    }
+
+    public SecretMount setSecretName(String secretName) {
+        this.secretName = secretName;
+        return this;
+    }
+
+    public String getMountPath() {
+        return mountPath;
+    }
+
+    public SecretMount setMountPath(String mountPath) {
+        this.mountPath = mountPath;
+        return this;
+    }
+
+    @Override
+    public String toString() {
+        return "SecretMount{" +
+                "secretName='" + secretName + '\'' +
+                ", mountPath='" + mountPath + '\'' +
+                '}';
+    }
+}
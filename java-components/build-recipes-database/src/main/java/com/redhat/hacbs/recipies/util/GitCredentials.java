@@ -1,5 +1,7 @@
 package com.redhat.hacbs.recipies.util;
 
+import java.net.URLDecoder;
+import java.nio.charset.StandardCharsets;
 import java.util.HashMap;
 import java.util.Map;
 import java.util.logging.Logger;
@@ -39,14 +41,22 @@ public class GitCredentials extends CredentialsProvider {
                     Logger.getLogger("git-credentials").severe("Invalid git credentials format");
                     continue;
                 }
-                String username = parts[0];
-                String password = parts[1];
+                String username = decode(parts[0]);
+                String password = decode(parts[1]);
                 hostToUsername.put(host, username);
                 hostToPassword.put(host, password);
             }
         }
     }
 
+    /**
+     * The credential store percent encodes the username and password, this decodes them. Unlike form encoding a '+'
+     * is not a space.
+     */
+    static String decode(String value) {
+        return URLDecoder.decode(value.replace("+", "%2B"), StandardCharsets.UTF_8);
+    }
+
     @Override
     public boolean isInteractive() {
         return false;
@@ -0,0 +1,14 @@
+package com.redhat.hacbs.recipies.util;
+
+import org.junit.jupiter.api.Assertions;
+import org.junit.jupiter.api.Test;
+
+class GitCredentialsTest {
+
+    @Test
+    void testDecode() {
+        Assertions.assertEquals("user", GitCredentials.decode("user"));
+        Assertions.assertEquals("p@ss:w/rd% +", GitCredentials.decode("p%40ss%3Aw%2Frd%25%20+"));
+        Assertions.assertEquals("p@ss:w/rd% +", GitCredentials.decode("p%40ss%3Aw%2Frd%25%20%2B"));
+    }
+}
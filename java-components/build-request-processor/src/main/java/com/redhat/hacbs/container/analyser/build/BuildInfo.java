@@ -4,6 +4,11 @@ import java.util.ArrayList;
 import java.util.List;
 
 import com.redhat.hacbs.recipies.build.AdditionalDownload;
+import com.redhat.hacbs.recipies.build.BuildEnvVar;
+import com.redhat.hacbs.recipies.build.BuildPatch;
+import com.redhat.hacbs.recipies.build.GitCloneOptions;
+import com.redhat.hacbs.recipies.build.ReproducibleBuildSettings;
+import com.redhat.hacbs.recipies.build.SecretMount;
 
 public class BuildInfo {
 
@@ -36,6 +41,16 @@ public class BuildInfo {
     int additionalMemory;
     List<String> allowedDifferences = new ArrayList<>();
 
+    GitCloneOptions gitOptions;
+
+    List<BuildPatch> patches = new ArrayList<>();
+
+    List<BuildEnvVar> env = new ArrayList<>();
+
+    List<SecretMount> secretMounts = new ArrayList<>();
+
+    ReproducibleBuildSettings reproducible;
+
     List<String> gavs = new ArrayList<>();
 
     String digest;
@@ -132,6 +147,51 @@ public class BuildInfo {
         return this;
     }
 
+    public GitCloneOptions getGitOptions() {
+        return gitOptions;
+    }
+
+    public BuildInfo setGitOptions(GitCloneOptions gitOptions) {
+        this.gitOptions = gitOptions;
+        return this;
+    }
+
+    public List<BuildPatch> getPatches() {
+        return patches;
+    }
+
+    public BuildInfo setPatches(List<BuildPatch> patches) {
+        this.patches = patches;
+        return this;
+    }
+
+    public List<BuildEnvVar> getEnv() {
+        return env;
+    }
+
+    public BuildInfo setEnv(List<BuildEnvVar> env) {
+        this.env = env;
+        return this;
+    }
+
+    public List<SecretMount> getSecretMounts() {
+        return secretMounts;
+    }
+
+    public BuildInfo setSecretMounts(List<SecretMount> secretMounts) {
+        this.secretMounts = secretMounts;
+        return this;
+    }
+
+    public ReproducibleBuildSettings getReproducible() {
+        return reproducible;
+    }
+
+    public BuildInfo setReproducible(ReproducibleBuildSettings reproducible) {
+        this.reproducible = reproducible;
+        return this;
+    }
+
     public List<String> getGavs() {
         return gavs;
     }
@@ -172,6 +232,11 @@ public class BuildInfo {
                 ", disableSubmodules=" + disableSubmodules +
                 ", additionalMemory=" + additionalMemory +
                 ", allowedDifferences=" + allowedDifferences +
+                ", gitOptions=" + gitOptions +
+                ", patches=" + patches +
+                ", env=" + env +
+                ", secretMounts=" + secretMounts +
+                ", reproducible=" + reproducible +
                 ", image=" + image +
                 ", digest=" + digest +
                 ", gavs=" + gavs +
@@ -132,6 +132,11 @@ public class InvocationBuilder {
             info.setAdditionalDownloads(buildRecipeInfo.getAdditionalDownloads());
             info.setAdditionalMemory(buildRecipeInfo.getAdditionalMemory());
             info.setAllowedDifferences(buildRecipeInfo.getAllowedDifferences());
+            info.setGitOptions(buildRecipeInfo.getGitOptions());
+            info.setPatches(buildRecipeInfo.getPatches());
+            info.setEnv(buildRecipeInfo.getEnv());
+            info.setSecretMounts(buildRecipeInfo.getSecretMounts());
+            info.setReproducible(buildRecipeInfo.getReproducible());
         }
         //now we need to figure out what possible build recipes we can try
         //we work through from lowest Java version to highest
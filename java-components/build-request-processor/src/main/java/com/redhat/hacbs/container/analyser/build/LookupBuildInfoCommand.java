@@ -99,6 +99,9 @@ public class LookupBuildInfoCommand implements Runnable {
     @CommandLine.Option(names = "--tool-versions", description = "Available Tool Versions")
     String toolVersions;
 
+    @CommandLine.Option(names = "--source-path", description = "A pre-existing checkout to analyse instead of cloning the git repository, used for non git SCM types")
+    Path sourcePath;
+
     @Inject
     Instance<ResultsUpdater> resultsUpdater;
 
@@ -148,18 +151,29 @@ public class LookupBuildInfoCommand implements Runnable {
         Map<String, List<String>> availableTools = parseToolVersions();
         InvocationBuilder builder = new InvocationBuilder(buildRecipeInfo, availableTools, version);
         boolean versionCorrect = false;
-        var path = Files.createTempDirectory("checkout");
-        try (var clone = Git.cloneRepository()
-                .setCredentialsProvider(
-                        new GitCredentials())
-                .setURI(scmUrl)
-                .setDirectory(path.toFile()).call()) {
-            clone.reset().setMode(HARD).setRef(scmTag).call();
+        Path path;
+        Git clone = null;
+        //the commit time is unknown for non git checkouts, zero means the cache does not filter by time
+        long time = 0;
+        if (sourcePath != null) {
+            path = sourcePath;
+        } else {
+            path = Files.createTempDirectory("checkout");
+            clone = Git.cloneRepository()
+                    .setCredentialsProvider(
+                            new GitCredentials())
+                    .setURI(scmUrl)
+                    .setDirectory(path.toFile()).call();
+        }
+        try {
+            if (clone != null) {
+                clone.reset().setMode(HARD).setRef(scmTag).call();
+                time = clone.getRepository().parseCommit(clone.getRepository().resolve(scmTag)).getCommitTime() * 1000L;
+            }
             boolean skipTests = !privateRepo;
             if (buildRecipeInfo != null && buildRecipeInfo.isRunTests()) {
                 skipTests = false;
             }
-            long time = clone.getRepository().parseCommit(clone.getRepository().resolve(scmTag)).getCommitTime() * 1000L;
             if (isNotBlank(context)) {
                 path = path.resolve(context);
             }
@@ -363,6 +377,10 @@ public class LookupBuildInfoCommand implements Runnable {
                 resultsUpdater.get().updateResults(taskRun, Map.of("BUILD_INFO",
                         ResultsUpdater.MAPPER.writeValueAsString(info)));
             }
+        } finally {
+            if (clone != null) {
+                clone.close();
+            }
         }
     }
 
@@ -13,6 +13,9 @@ import org.cyclonedx.BomParserFactory;
 import org.cyclonedx.exception.ParseException;
 import org.cyclonedx.model.Bom;
 import org.cyclonedx.model.Component;
+import org.cyclonedx.model.ExternalReference;
+import org.cyclonedx.model.Hash;
+import org.cyclonedx.model.Metadata;
 import org.cyclonedx.model.Property;
 
 import com.redhat.hacbs.classfile.tracker.TrackingData;
@@ -104,6 +107,86 @@ public class SBomGenerator {
         return bom;
     }
 
+    /**
+     * Generates the SBOM for a rebuilt DependencyBuild. This lists the artifacts that were produced, the build time
+     * dependencies that were resolved through the cache, the builder image and the source commit.
+     */
+    public static Bom generateBuildSBom(List<BuildArtifact> produced, Set<TrackingData> buildDependencies, String scmUri,
+            String commit, String builderImage) {
+        Bom bom = generateSBom(buildDependencies, null);
+        for (var i : bom.getComponents()) {
+            //these were only used to run the build, they are not part of what was produced
+            i.setScope(Component.Scope.EXCLUDED);
+            i.getProperties().add(property("build:role", "build-dependency"));
+        }
+
+        for (var i : produced) {
+            Component component = new Component();
+            component.setType(Component.Type.LIBRARY);
+            component.setGroup(i.group());
+            component.setName(i.name());
+            component.setVersion(i.version());
+            String purl = i.purl();
+            component.setPurl(purl);
+            component.setBomRef(purl);
+            component.setPublisher("rebuilt");
+            component.setHashes(List.of(new Hash(Hash.Algorithm.SHA_256, i.sha256()), new Hash(Hash.Algorithm.SHA1, i.sha1())));
+            component.setProperties(new ArrayList<>(List.of(property("build:role", "produced"))));
+            bom.getComponents().add(component);
+        }
+
+        if (builderImage != null) {
+            Component image = new Component();
+            image.setType(Component.Type.CONTAINER);
+            image.setName(builderImage);
+            image.setScope(Component.Scope.EXCLUDED);
+            image.setProperties(new ArrayList<>(List.of(property("build:role", "builder-image"))));
+            bom.getComponents().add(image);
+        }
+
+        Component source = new Component();
+        source.setType(Component.Type.APPLICATION);
+        source.setName(scmUri);
+        source.setVersion(commit);
+        ExternalReference vcs = new ExternalReference();
+        vcs.setType(ExternalReference.Type.VCS);
+        vcs.setUrl(scmUri);
+        source.addExternalReference(vcs);
+        Metadata metadata = new Metadata();
+        metadata.setComponent(source);
+        List<Property> properties = new ArrayList<>();
+        properties.add(property("build:scm-uri", scmUri));
+        properties.add(property("build:scm-commit", commit));
+        if (builderImage != null) {
+            properties.add(property("build:builder-image", builderImage));
+        }
+        metadata.setProperties(properties);
+        bom.setMetadata(metadata);
+        return bom;
+    }
+
+    private static Property property(String name, String value) {
+        Property property = new Property();
+        property.setName(name);
+        property.setValue(value);
+        return property;
+    }
+
+    /**
+     * An artifact produced by the build, identified by its file within the deployment.
+     */
+    public record BuildArtifact(String group, String name, String version, String type, String classifier, String sha1,
+            String sha256) {
+
+        public String purl() {
+            String purl = String.format("pkg:maven/%s/%s@%s?type=%s", group, name, version, type);
+            if (classifier != null) {
+                purl += "&classifier=" + classifier;
+            }
+            return purl;
+        }
+    }
+
     static class Identifier {
         final String name;
         final String groupId;
@@ -7,18 +7,22 @@ import java.io.IOException;
 import java.io.InputStream;
 import java.net.URL;
 import java.nio.charset.StandardCharsets;
+import java.nio.file.FileSystems;
 import java.nio.file.FileVisitResult;
 import java.nio.file.Files;
 import java.nio.file.Path;
 import java.nio.file.SimpleFileVisitor;
 import java.nio.file.attribute.BasicFileAttributes;
 import java.util.ArrayList;
+import java.util.Comparator;
 import java.util.HashMap;
 import java.util.HashSet;
 import java.util.List;
 import java.util.Map;
 import java.util.Optional;
 import java.util.Set;
+import java.util.TreeMap;
+import java.util.TreeSet;
 import java.util.function.BiConsumer;
 import java.util.stream.Stream;
 
@@ -41,6 +45,8 @@ import com.redhat.hacbs.container.analyser.dependencies.SBomGenerator;
 import com.redhat.hacbs.container.analyser.deploy.containerregistry.ContainerRegistryDeployer;
 import com.redhat.hacbs.container.analyser.deploy.git.Git;
 import com.redhat.hacbs.container.analyser.deploy.mavenrepository.MavenRepositoryDeployer;
+import com.redhat.hacbs.container.analyser.license.LicenseDetector;
+import com.redhat.hacbs.container.analyser.license.SpdxLicenses;
 import com.redhat.hacbs.container.results.ResultsUpdater;
 import com.redhat.hacbs.recipies.util.FileUtil;
 import com.redhat.hacbs.resources.model.v1alpha1.dependencybuildstatus.Contaminates;
@@ -66,6 +72,19 @@ public class DeployCommand implements Runnable {
     @CommandLine.Option(names = "--allowed-sources", defaultValue = "redhat,rebuilt", split = ",")
     Set<String> allowedSources;
 
+    /**
+     * Contaminants the JBSConfig contaminant policy allows, as contaminant-pattern[@artifact-pattern]. Allowed
+     * contaminants are reported, but the contaminated artifacts are still deployed.
+     */
+    @CommandLine.Option(names = "--allowed-contaminants", split = ",")
+    List<String> allowedContaminants = new ArrayList<>();
+
+    /**
+     * Licenses that stop the build being deployed, as SPDX identifiers
+     */
+    @CommandLine.Option(names = "--denied-licenses", split = ",")
+    List<String> deniedLicenses = new ArrayList<>();
+
     @CommandLine.Option(required = true, names = "--path")
     Path deploymentPath;
 
@@ -87,6 +106,12 @@ public class DeployCommand implements Runnable {
     @CommandLine.Option(required = true, names = "--scm-commit")
     String commit;
 
+    /**
+     * The digests of the patches applied to the source before the build, in the order they were applied
+     */
+    @CommandLine.Option(names = "--patches", split = ",")
+    List<String> patches = new ArrayList<>();
+
     @CommandLine.Option(names = "--registry-host", defaultValue = "quay.io")
     String host;
     @CommandLine.Option(names = "--registry-port", defaultValue = "443")
@@ -136,11 +161,15 @@ public class DeployCommand implements Runnable {
 
     @CommandLine.Option(names = "--build-id")
     String buildId;
+
+    @CommandLine.Option(names = "--builder-image")
+    String builderImage;
     // Testing only ; used to disable image deployment
     protected boolean imageDeployment = true;
 
     protected String imageName;
     protected String imageDigest;
+    protected String buildSbom;
 
     @Inject
     BootstrapMavenContext mvnCtx;
@@ -183,6 +212,12 @@ public class DeployCommand implements Runnable {
                         if (!allowedSources.contains(i.source)) {
                             Log.errorf("%s was contaminated by %s from %s", name, i.gav, i.source);
                             if (ALLOWED_CONTAMINANTS.stream().noneMatch(a -> file.getFileName().toString().endsWith(a))) {
+                                if (isAllowedContaminant(i.gav, gav)) {
+                                    Log.infof("%s contaminant %s is allowed by the contaminant policy", name, i.gav);
+                                    gav.ifPresent(g -> allowedContaminatedGavs.computeIfAbsent(i.gav, s -> new HashSet<>())
+                                            .add(g.getGroupId() + ":" + g.getArtifactId() + ":" + g.getVersion()));
+                                    continue;
+                                }
                                 gav.ifPresent(g -> contaminatedGavs.computeIfAbsent(i.gav, s -> new HashSet<>())
                                         .add(g.getGroupId() + ":" + g.getArtifactId() + ":" + g.getVersion()));
                                 int index = name.lastIndexOf("/");
@@ -215,13 +250,17 @@ public class DeployCommand implements Runnable {
                 try {
                     String fileName = file.getFileName().toString();
                     Path temp = file.getParent().resolve(fileName + ".temp");
+                    Map<String, String> attributes = new HashMap<>(Map.of("scm-uri", scmUri, "scm-commit", commit, "hermetic",
+                            Boolean.toString(hermetic), "build-id", buildId));
+                    if (!patches.isEmpty()) {
+                        attributes.put("patches", String.join(",", patches));
+                    }
                     ClassFileTracker.addTrackingDataToJar(Files.newInputStream(file),
                             new TrackingData(
                                     gav.getGroupId() + ":" + gav.getArtifactId() + ":"
                                             + gav.getVersion(),
                                     "rebuilt",
-                                    Map.of("scm-uri", scmUri, "scm-commit", commit, "hermetic",
-                                            Boolean.toString(hermetic), "build-id", buildId)),
+                                    attributes),
                             Files.newOutputStream(temp), false);
                     Files.delete(file);
                     Files.move(temp, file);
@@ -271,7 +310,14 @@ public class DeployCommand implements Runnable {
             for (var i : contaminatedGavs.entrySet()) {
                 gavs.removeAll(i.getValue());
             }
-            generateBuildSbom();
+            generateBuildSbom(gavs);
+            Map<String, Set<String>> licenses = detectLicenses(gavs);
+            Map<String, Set<String>> denied = deniedLicenses(licenses);
+            if (!denied.isEmpty()) {
+                //nothing is deployed, the controller fails the build from the reported licenses
+                Log.errorf("Artifacts have denied licenses: %s", denied);
+                gavs.clear();
+            }
 
             if (isNotEmpty(mvnRepo) && mvnPassword.isEmpty()) {
                 Log.infof("Maven repository specified as %s and no password specified", mvnRepo);
@@ -310,15 +356,23 @@ public class DeployCommand implements Runnable {
                     throw t;
                 }
             } else {
-                Log.errorf("Skipped deploying from task run %s as all artifacts were contaminated", taskRun);
+                Log.errorf("Skipped deploying from task run %s as all artifacts were contaminated or have denied licenses",
+                        taskRun);
             }
             if (imageDigest != null) {
                 System.out.println(IMAGE_DIGEST_OUTPUT + "sha256:" + imageDigest);
             }
             if (taskRun != null) {
 
+                //allowed contaminants are reported as well, the controller records them as allowed
+                Map<String, Set<String>> reportedGavs = new HashMap<>();
+                for (var i : List.of(contaminatedGavs, allowedContaminatedGavs)) {
+                    for (var e : i.entrySet()) {
+                        reportedGavs.computeIfAbsent(e.getKey(), s -> new HashSet<>()).addAll(e.getValue());
+                    }
+                }
                 List<Contaminates> newContaminates = new ArrayList<>();
-                for (var i : contaminatedGavs.entrySet()) {
+                for (var i : reportedGavs.entrySet()) {
                     Contaminates contaminates = new Contaminates();
                     contaminates.setContaminatedArtifacts(new ArrayList<>(i.getValue()));
                     contaminates.setGav(i.getKey());
@@ -327,11 +381,16 @@ public class DeployCommand implements Runnable {
                 String serialisedContaminants = ResultsUpdater.MAPPER.writeValueAsString(newContaminates);
                 Log.infof("Updating results %s with contaminants %s and deployed resources %s",
                         taskRun, serialisedContaminants, gavs);
-                resultsUpdater.updateResults(taskRun, Map.of(
+                Map<String, String> results = new HashMap<>(Map.of(
                         "CONTAMINANTS", serialisedContaminants,
                         "DEPLOYED_RESOURCES", String.join(",", gavs),
                         "IMAGE_URL", imageName == null ? "" : imageName,
                         "IMAGE_DIGEST", imageDigest == null ? "" : "sha256:" + imageDigest));
+                if (buildSbom != null) {
+                    results.put("SBOM", buildSbom);
+                }
+                results.put("LICENSES", ResultsUpdater.MAPPER.writeValueAsString(licenses));
+                resultsUpdater.updateResults(taskRun, results);
             }
         } catch (Exception e) {
             Log.error("Deployment failed", e);
@@ -339,38 +398,161 @@ public class DeployCommand implements Runnable {
         }
     }
 
-    private void generateBuildSbom() {
-        if (buildInfoPath == null) {
-            Log.infof("Not generating build sbom, path not set");
-            return;
+    /**
+     * Returns the licenses of each GAV that are denied
+     */
+    Map<String, Set<String>> deniedLicenses(Map<String, Set<String>> licenses) {
+        Map<String, Set<String>> ret = new TreeMap<>();
+        for (var e : licenses.entrySet()) {
+            for (var license : e.getValue()) {
+                if (SpdxLicenses.isDenied(license, deniedLicenses)) {
+                    ret.computeIfAbsent(e.getKey(), s -> new TreeSet<>()).add(license);
+                }
+            }
+        }
+        return ret;
+    }
+
+    /**
+     * Checks the contaminant against the contaminant policy, a pattern without an artifact pattern allows the
+     * contaminant in every artifact.
+     */
+    boolean isAllowedContaminant(String contaminant, Optional<Gav> artifact) {
+        for (var i : allowedContaminants) {
+            String[] parts = i.split("@", 2);
+            if (!globMatches(parts[0], contaminant)) {
+                continue;
+            }
+            if (parts.length == 1 || parts[1].isEmpty()) {
+                return true;
+            }
+            if (artifact.isPresent() && globMatches(parts[1],
+                    artifact.get().getGroupId() + ":" + artifact.get().getArtifactId() + ":" + artifact.get().getVersion())) {
+                return true;
+            }
+        }
+        return false;
+    }
+
+    /**
+     * Matches a GAV against a glob pattern in the same way the controller does, a GAV has no '/' so a '*' matches any
+     * part of it.
+     */
+    private static boolean globMatches(String pattern, String gav) {
+        try {
+            return FileSystems.getDefault().getPathMatcher("glob:" + pattern).matches(Path.of(gav));
+        } catch (RuntimeException e) {
+            Log.errorf(e, "Invalid contaminant pattern %s", pattern);
+            return false;
         }
-        Log.infof("Generating build sbom from %s", buildInfoPath);
+    }
+
+    private void generateBuildSbom(Set<String> gavs) {
         Set<TrackingData> data = new HashSet<>();
+        List<SBomGenerator.BuildArtifact> produced = new ArrayList<>();
         try {
-            Files.walkFileTree(buildInfoPath, new SimpleFileVisitor<>() {
+            if (buildInfoPath == null) {
+                Log.infof("Build info path not set, build dependencies will not be included in the build sbom");
+            } else {
+                Log.infof("Generating build sbom from %s", buildInfoPath);
+                Files.walkFileTree(buildInfoPath, new SimpleFileVisitor<>() {
+                    @Override
+                    public FileVisitResult visitFile(Path file, BasicFileAttributes attrs) throws IOException {
+                        try (InputStream inputStream = Files.newInputStream(file)) {
+                            Set<TrackingData> ret = ClassFileTracker.readTrackingDataFromFile(inputStream,
+                                    file.getFileName().toString());
+                            if (!ret.isEmpty()) {
+                                Log.infof("Found file at %s", file);
+                                data.addAll(ret);
+                            }
+                            return FileVisitResult.CONTINUE;
+                        }
+                    }
+                });
+            }
+            //the artifacts we are about to deploy, contaminated ones have already been removed
+            Files.walkFileTree(deploymentPath, new SimpleFileVisitor<>() {
                 @Override
                 public FileVisitResult visitFile(Path file, BasicFileAttributes attrs) throws IOException {
-                    try (InputStream inputStream = Files.newInputStream(file)) {
-                        Set<TrackingData> ret = ClassFileTracker.readTrackingDataFromFile(inputStream,
-                                file.getFileName().toString());
-                        if (!ret.isEmpty()) {
-                            Log.infof("Found file at %s", file);
-                            data.addAll(ret);
-                        }
+                    Optional<Gav> gav = getGav(deploymentPath.relativize(file).toString());
+                    if (gav.isEmpty() || !gavs.contains(
+                            gav.get().getGroupId() + ":" + gav.get().getArtifactId() + ":" + gav.get().getVersion())) {
                         return FileVisitResult.CONTINUE;
                     }
+                    String fileName = file.getFileName().toString();
+                    String type = fileName.substring(fileName.lastIndexOf('.') + 1);
+                    String prefix = gav.get().getArtifactId() + "-" + gav.get().getVersion() + "-";
+                    String classifier = null;
+                    if (fileName.startsWith(prefix)) {
+                        classifier = fileName.substring(prefix.length(), fileName.length() - type.length() - 1);
+                    }
+                    try (InputStream sha1 = Files.newInputStream(file); InputStream sha256 = Files.newInputStream(file)) {
+                        produced.add(new SBomGenerator.BuildArtifact(gav.get().getGroupId(), gav.get().getArtifactId(),
+                                gav.get().getVersion(), type, classifier, HashUtil.sha1(sha1),
+                                HashUtil.hashStream(sha256, "SHA-256")));
+                    }
+                    return FileVisitResult.CONTINUE;
                 }
             });
-            var sbom = SBomGenerator.generateSBom(data, null);
-            var json = BomGeneratorFactory.createJson(CycloneDxSchema.Version.VERSION_12, sbom);
-            String sbomStr = json.toJsonString();
-            Log.infof("Build Sbom \n%s", sbomStr);
-            Files.writeString(logsPath.resolve("build-sbom.json"), sbomStr, StandardCharsets.UTF_8);
+            produced.sort(Comparator.comparing(SBomGenerator.BuildArtifact::purl));
+            var sbom = SBomGenerator.generateBuildSBom(produced, data, scmUri, commit, builderImage);
+            var json = BomGeneratorFactory.createJson(CycloneDxSchema.Version.VERSION_14, sbom);
+            buildSbom = json.toJsonString();
+            Log.infof("Build Sbom \n%s", buildSbom);
+            if (logsPath != null) {
+                Files.writeString(logsPath.resolve("build-sbom.json"), buildSbom, StandardCharsets.UTF_8);
+            }
         } catch (IOException e) {
             Log.errorf(e, "Failed to generate build sbom");
         }
     }
 
+    /**
+     * Detects the licenses of each GAV that is being deployed. If the artifacts of a GAV do not declare a license
+     * the licenses found in the source tree are used.
+     */
+    private Map<String, Set<String>> detectLicenses(Set<String> gavs) {
+        Map<String, Set<String>> licenses = new TreeMap<>();
+        for (var gav : gavs) {
+            licenses.put(gav, new TreeSet<>());
+        }
+        try {
+            Files.walkFileTree(deploymentPath, new SimpleFileVisitor<>() {
+                @Override
+                public FileVisitResult visitFile(Path file, BasicFileAttributes attrs) throws IOException {
+                    String name = deploymentPath.relativize(file).toString();
+                    Optional<Gav> gav = getGav(name);
+                    if (gav.isEmpty()) {
+                        return FileVisitResult.CONTINUE;
+                    }
+                    Set<String> gavLicenses = licenses
+                            .get(gav.get().getGroupId() + ":" + gav.get().getArtifactId() + ":" + gav.get().getVersion());
+                    if (gavLicenses == null) {
+                        return FileVisitResult.CONTINUE;
+                    }
+                    try (InputStream inputStream = Files.newInputStream(file)) {
+                        if (name.endsWith(DOT_POM)) {
+                            gavLicenses.addAll(LicenseDetector.detectInPom(inputStream));
+                        } else {
+                            gavLicenses.addAll(LicenseDetector.detectInJar(inputStream));
+                        }
+                    }
+                    return FileVisitResult.CONTINUE;
+                }
+            });
+            Set<String> sourceLicenses = LicenseDetector.detectInSourceTree(sourcePath);
+            for (var i : licenses.values()) {
+                if (i.isEmpty()) {
+                    i.addAll(sourceLicenses);
+                }
+            }
+        } catch (IOException e) {
+            Log.errorf(e, "Failed to detect licenses");
+        }
+        Log.infof("Licenses: %s", licenses);
+        return licenses;
+    }
+
     private void cleanBrokenSymlinks(Path sourcePath) throws IOException {
         Files.walkFileTree(sourcePath, new SimpleFileVisitor<>() {
             @Override
@@ -394,7 +576,10 @@ public class DeployCommand implements Runnable {
         if (imageDeployment) {
             ContainerRegistryDeployer deployer = new ContainerRegistryDeployer(host, port, owner, token.orElse(""), repository,
                     insecure, prependTag);
-            deployer.deployArchive(deploymentPath, sourcePath, logsPath, gavs, imageId, buildId,
+            //the provenance is checked before builds from shared registries are reused
+            Map<String, String> labels = Map.of("io.jvmbuildservice.scm-uri", scmUri, "io.jvmbuildservice.scm-commit", commit,
+                    "io.jvmbuildservice.hermetic", Boolean.toString(hermetic), "io.jvmbuildservice.build-id", buildId);
+            deployer.deployArchive(deploymentPath, sourcePath, logsPath, gavs, imageId, buildId, labels,
                     new BiConsumer<String, String>() {
                         @Override
                         public void accept(String s, String hash) {
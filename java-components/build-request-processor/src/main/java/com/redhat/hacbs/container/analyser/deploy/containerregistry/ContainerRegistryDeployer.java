@@ -10,6 +10,7 @@ import java.nio.file.attribute.BasicFileAttributes;
 import java.util.ArrayDeque;
 import java.util.Deque;
 import java.util.List;
+import java.util.Map;
 import java.util.Optional;
 import java.util.Set;
 import java.util.concurrent.ExecutionException;
@@ -82,15 +83,18 @@ public class ContainerRegistryDeployer {
         Log.infof("Prepend tag is %s", prependTag);
     }
 
+    /**
+     * @param labels Additional labels for the image, used to record the provenance of the build
+     */
     public void deployArchive(Path deployDir, Path sourcePath, Path logsPath, Set<String> gavs, String imageId, String buildId,
-            BiConsumer<String, String> imageNameHashCallback) throws Exception {
+            Map<String, String> labels, BiConsumer<String, String> imageNameHashCallback) throws Exception {
         Log.debugf("Using Container registry %s:%d/%s/%s", host, port, owner, repository);
 
         // Read the tar to get the gavs and files
         DeployData imageData = new DeployData(deployDir, gavs);
 
         // Create the image layers
-        createImages(imageData, sourcePath, logsPath, imageId, buildId, imageNameHashCallback);
+        createImages(imageData, sourcePath, logsPath, imageId, buildId, labels, imageNameHashCallback);
     }
 
     public void tagArchive(String imageDigest, List<String> gavNames) throws Exception {
@@ -217,7 +221,7 @@ public class ContainerRegistryDeployer {
     }
 
     private void createImages(DeployData imageData, Path sourcePath, Path logsPath,
-            String imageId, String buildId, BiConsumer<String, String> imageNameHashCallback)
+            String imageId, String buildId, Map<String, String> labels, BiConsumer<String, String> imageNameHashCallback)
             throws InvalidImageReferenceException, InterruptedException, RegistryException, IOException,
             CacheDirectoryCreationException, ExecutionException {
 
@@ -243,6 +247,9 @@ public class ContainerRegistryDeployer {
 
         containerBuilder.addLabel("io.jvmbuildservice.gavs",
                 gavs.stream().map(Gav::stringForm).collect(Collectors.joining(",")));
+        for (var label : labels.entrySet()) {
+            containerBuilder.addLabel(label.getKey(), label.getValue());
+        }
         List<Path> layers = getLayers(imageData.getArtifactsPath(), sourcePath, logsPath);
         for (Path layer : layers) {
             containerBuilder = containerBuilder.addLayer(List.of(layer), imageRoot);
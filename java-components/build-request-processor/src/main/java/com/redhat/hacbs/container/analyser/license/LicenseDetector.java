@@ -0,0 +1,215 @@
+package com.redhat.hacbs.container.analyser.license;
+
+import java.io.ByteArrayInputStream;
+import java.io.IOException;
+import java.io.InputStream;
+import java.nio.charset.StandardCharsets;
+import java.nio.file.FileVisitResult;
+import java.nio.file.Files;
+import java.nio.file.Path;
+import java.nio.file.SimpleFileVisitor;
+import java.nio.file.attribute.BasicFileAttributes;
+import java.util.LinkedHashSet;
+import java.util.List;
+import java.util.Locale;
+import java.util.Set;
+import java.util.jar.Manifest;
+import java.util.regex.Pattern;
+import java.util.zip.ZipEntry;
+import java.util.zip.ZipInputStream;
+
+import org.apache.maven.model.io.xpp3.MavenXpp3Reader;
+
+import io.quarkus.logging.Log;
+
+/**
+ * Detects the licenses of a source tree and of the artifacts it produced.
+ * <p>
+ * Licenses are taken from the pom {@code <licenses>} section, license files, the {@code Bundle-License} manifest
+ * header and {@code SPDX-License-Identifier} source headers. All results are normalized to SPDX identifiers.
+ */
+public class LicenseDetector {
+
+    /**
+     * Only the start of a source file is checked for a SPDX header
+     */
+    static final int HEADER_LENGTH = 4096;
+
+    static final Set<String> SOURCE_EXTENSIONS = Set.of("java", "kt", "groovy", "scala", "js", "c", "h", "cpp");
+
+    static final Set<String> SKIPPED_DIRECTORIES = Set.of(".git", "target", "build", "node_modules");
+
+    private static final Pattern SPDX_HEADER = Pattern
+            .compile("SPDX-License-Identifier:\\s*(.+?)\\s*(?:\\*/|-->)?\\s*$", Pattern.MULTILINE);
+    private static final Pattern WHITESPACE = Pattern.compile("\\s+");
+
+    /**
+     * Only the start of a license file is checked for its title
+     */
+    static final int TITLE_LENGTH = 1024;
+
+    /**
+     * Titles and phrases that identify a license file, checked in order. The GNU licenses quote each other in their
+     * text, so they are only identified by their title.
+     */
+    private static final List<TextMatch> TEXT_MATCHES = List.of(
+            TextMatch.titled("AGPL-3.0-only", "gnu affero general public license,? version 3\\b"),
+            TextMatch.titled("LGPL-2.1-only", "gnu lesser general public license,? version 2\\.1\\b"),
+            TextMatch.titled("LGPL-3.0-only", "gnu lesser general public license,? version 3\\b"),
+            TextMatch.titled("GPL-2.0-only WITH Classpath-exception-2.0",
+                    "gnu general public license(?: \\(gpl\\))?,? version 2(?![.\\d])", "classpath exception"),
+            TextMatch.titled("GPL-2.0-only", "gnu general public license(?: \\(gpl\\))?,? version 2(?![.\\d])"),
+            TextMatch.titled("GPL-3.0-only", "gnu general public license(?: \\(gpl\\))?,? version 3\\b"),
+            TextMatch.titled("Apache-2.0", "apache license,? version 2\\.0\\b"),
+            new TextMatch("EPL-2.0", null, "eclipse public license - v 2.0"),
+            new TextMatch("EPL-2.0", null, "eclipse public license v2.0"),
+            new TextMatch("EPL-1.0", null, "eclipse public license - v 1.0"),
+            new TextMatch("EPL-1.0", null, "eclipse public license v1.0"),
+            new TextMatch("MPL-2.0", null, "mozilla public license", "2.0"),
+            new TextMatch("CDDL-1.1", null, "common development and distribution license", "version 1.1"),
+            new TextMatch("CDDL-1.0", null, "common development and distribution license"),
+            new TextMatch("Unlicense", null, "this is free and unencumbered software released into the public domain"),
+            new TextMatch("MIT", null, "permission is hereby granted, free of charge"),
+            new TextMatch("BSD-3-Clause", null, "redistribution and use in source and binary forms", "neither the name"),
+            new TextMatch("BSD-2-Clause", null, "redistribution and use in source and binary forms"));
+
+    /**
+     * Detects the licenses in the text of a license file
+     */
+    public static Set<String> detectInText(String text) {
+        Set<String> ret = new LinkedHashSet<>();
+        String normalized = WHITESPACE.matcher(text.toLowerCase(Locale.ROOT)).replaceAll(" ").trim();
+        String head = normalized.substring(0, Math.min(normalized.length(), TITLE_LENGTH));
+        for (var match : TEXT_MATCHES) {
+            if (match.matches(head, normalized)) {
+                ret.add(match.id());
+                break;
+            }
+        }
+        var matcher = SPDX_HEADER.matcher(text);
+        while (matcher.find()) {
+            ret.addAll(SpdxLicenses.normalize(matcher.group(1)));
+        }
+        return ret;
+    }
+
+    /**
+     * Detects the licenses declared in a pom
+     */
+    public static Set<String> detectInPom(InputStream pom) {
+        Set<String> ret = new LinkedHashSet<>();
+        try {
+            var model = new MavenXpp3Reader().read(pom, false);
+            for (var license : model.getLicenses()) {
+                ret.addAll(SpdxLicenses.normalize(license.getName(), license.getUrl()));
+            }
+        } catch (Exception e) {
+            Log.debugf(e, "Unable to read licenses from pom");
+        }
+        return ret;
+    }
+
+    /**
+     * Detects the licenses of a jar from its license files, manifest and embedded pom
+     */
+    public static Set<String> detectInJar(InputStream jar) throws IOException {
+        Set<String> ret = new LinkedHashSet<>();
+        try (ZipInputStream zip = new ZipInputStream(jar)) {
+            ZipEntry entry;
+            while ((entry = zip.getNextEntry()) != null) {
+                String name = entry.getName();
+                if (entry.isDirectory() || !name.startsWith("META-INF/")) {
+                    continue;
+                }
+                String fileName = name.substring(name.lastIndexOf('/') + 1);
+                if (name.equals("META-INF/MANIFEST.MF")) {
+                    String bundleLicense = new Manifest(zip).getMainAttributes().getValue("Bundle-License");
+                    if (bundleLicense != null) {
+                        for (var license : bundleLicense.split(",")) {
+                            ret.addAll(SpdxLicenses.normalize(license.split(";")[0]));
+                        }
+                    }
+                } else if (name.startsWith("META-INF/maven/") && fileName.equals("pom.xml")) {
+                    //the reader closes the stream, so the pom is read first
+                    ret.addAll(detectInPom(new ByteArrayInputStream(zip.readAllBytes())));
+                } else if (isLicenseFile(fileName)) {
+                    ret.addAll(detectInText(new String(zip.readAllBytes(), StandardCharsets.UTF_8)));
+                }
+            }
+        }
+        return ret;
+    }
+
+    /**
+     * Detects the licenses of a source tree from the root pom, license files and source headers
+     */
+    public static Set<String> detectInSourceTree(Path sourcePath) throws IOException {
+        Set<String> ret = new LinkedHashSet<>();
+        Path pom = sourcePath.resolve("pom.xml");
+        if (Files.exists(pom)) {
+            try (var in = Files.newInputStream(pom)) {
+                ret.addAll(detectInPom(in));
+            }
+        }
+        Files.walkFileTree(sourcePath, new SimpleFileVisitor<>() {
+            @Override
+            public FileVisitResult preVisitDirectory(Path dir, BasicFileAttributes attrs) {
+                if (!dir.equals(sourcePath) && SKIPPED_DIRECTORIES.contains(dir.getFileName().toString())) {
+                    return FileVisitResult.SKIP_SUBTREE;
+                }
+                return FileVisitResult.CONTINUE;
+            }
+
+            @Override
+            public FileVisitResult visitFile(Path file, BasicFileAttributes attrs) {
+                String fileName = file.getFileName().toString();
+                try {
+                    if (isLicenseFile(fileName)) {
+                        ret.addAll(detectInText(Files.readString(file, StandardCharsets.ISO_8859_1)));
+                    } else if (SOURCE_EXTENSIONS.contains(fileName.substring(fileName.lastIndexOf('.') + 1))) {
+                        try (var in = Files.newInputStream(file)) {
+                            var matcher = SPDX_HEADER
+                                    .matcher(new String(in.readNBytes(HEADER_LENGTH), StandardCharsets.UTF_8));
+                            while (matcher.find()) {
+                                ret.addAll(SpdxLicenses.normalize(matcher.group(1)));
+                            }
+                        }
+                    }
+                } catch (IOException e) {
+                    //broken symlinks are common in source trees, they are ignored
+                    Log.debugf(e, "Unable to check %s for licenses", file);
+                }
+                return FileVisitResult.CONTINUE;
+            }
+        });
+        return ret;
+    }
+
+    static boolean isLicenseFile(String fileName) {
+        String upper = fileName.toUpperCase(Locale.ROOT);
+        return upper.startsWith("LICENSE") || upper.startsWith("LICENCE") || upper.startsWith("COPYING");
+    }
+
+    /**
+     * @param title a pattern that must be found at the start of the text, or null
+     * @param phrases phrases that must all be present in the text
+     */
+    record TextMatch(String id, Pattern title, String... phrases) {
+
+        static TextMatch titled(String id, String title, String... phrases) {
+            return new TextMatch(id, Pattern.compile(title), phrases);
+        }
+
+        boolean matches(String head, String text) {
+            if (title != null && !title.matcher(head).find()) {
+                return false;
+            }
+            for (var phrase : phrases) {
+                if (!text.contains(phrase)) {
+                    return false;
+                }
+            }
+            return true;
+        }
+    }
+}
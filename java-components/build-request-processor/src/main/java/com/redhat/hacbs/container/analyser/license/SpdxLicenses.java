@@ -0,0 +1,228 @@
+package com.redhat.hacbs.container.analyser.license;
+
+import java.util.ArrayList;
+import java.util.Collection;
+import java.util.HashMap;
+import java.util.LinkedHashSet;
+import java.util.List;
+import java.util.Locale;
+import java.util.Map;
+import java.util.Set;
+import java.util.regex.Pattern;
+
+/**
+ * Normalizes the license names and URLs found in poms, manifests and source headers to SPDX identifiers.
+ * <p>
+ * Licenses that are not recognised are returned as a {@code LicenseRef-} identifier, so they are still visible
+ * and can be added to a denylist.
+ */
+public class SpdxLicenses {
+
+    public static final String LICENSE_REF_PREFIX = "LicenseRef-";
+
+    static final List<String> IDENTIFIERS = List.of(
+            "0BSD", "AGPL-3.0-only", "AGPL-3.0-or-later", "Apache-1.1", "Apache-2.0", "BSD-2-Clause", "BSD-3-Clause",
+            "BSL-1.0", "CC0-1.0", "CC-BY-4.0", "CDDL-1.0", "CDDL-1.1", "Classpath-exception-2.0", "EPL-1.0", "EPL-2.0",
+            "GPL-2.0-only", "GPL-2.0-or-later", "GPL-3.0-only", "GPL-3.0-or-later", "ISC", "LGPL-2.1-only",
+            "LGPL-2.1-or-later", "LGPL-3.0-only", "LGPL-3.0-or-later", "MIT", "MPL-1.1", "MPL-2.0", "Unlicense",
+            "UPL-1.0", "WTFPL", "Zlib");
+
+    private static final Pattern NON_ALPHANUMERIC = Pattern.compile("[^a-z0-9]+");
+    private static final Pattern INVALID_REF = Pattern.compile("[^A-Za-z0-9.-]+");
+    private static final Pattern WHITESPACE = Pattern.compile("\\s+");
+
+    private static final Map<String, String> ALIASES = new HashMap<>();
+
+    static {
+        for (var id : IDENTIFIERS) {
+            alias(id, id);
+        }
+        //names as they commonly appear in poms and manifests
+        alias("Apache-2.0", "Apache License 2.0", "Apache License, Version 2.0", "The Apache License, Version 2.0",
+                "The Apache Software License, Version 2.0", "Apache 2", "Apache 2.0", "Apache License Version 2.0",
+                "ASL 2.0", "ASF 2.0", "Apache Software License - Version 2.0", "Apache Public License 2.0",
+                "apache.org/licenses/LICENSE-2.0", "apache.org/licenses/LICENSE-2.0.txt", "opensource.org/licenses/Apache-2.0");
+        alias("Apache-1.1", "The Apache Software License, Version 1.1", "Apache License 1.1");
+        alias("MIT", "MIT License", "The MIT License", "The MIT License (MIT)", "opensource.org/licenses/MIT",
+                "opensource.org/licenses/mit-license.php");
+        alias("BSD-2-Clause", "BSD 2-Clause License", "The BSD 2-Clause License", "Simplified BSD License",
+                "opensource.org/licenses/BSD-2-Clause");
+        alias("BSD-3-Clause", "BSD", "BSD License", "BSD 3-Clause License", "The BSD 3-Clause License", "New BSD License",
+                "Revised BSD License", "The New BSD License", "Eclipse Distribution License - v 1.0", "EDL 1.0",
+                "Eclipse Distribution License v. 1.0", "opensource.org/licenses/BSD-3-Clause",
+                "eclipse.org/org/documents/edl-v10.php");
+        alias("EPL-1.0", "Eclipse Public License 1.0", "Eclipse Public License - v 1.0", "Eclipse Public License v1.0",
+                "eclipse.org/legal/epl-v10.html");
+        alias("EPL-2.0", "Eclipse Public License 2.0", "Eclipse Public License - v 2.0", "Eclipse Public License v2.0",
+                "Eclipse Public License v. 2.0", "eclipse.org/legal/epl-2.0", "eclipse.org/legal/epl-v20.html");
+        alias("LGPL-2.1-only", "GNU Lesser General Public License v2.1", "GNU Lesser General Public License, Version 2.1",
+                "LGPL 2.1", "LGPL-2.1", "LGPLv2.1", "gnu.org/licenses/old-licenses/lgpl-2.1.html");
+        alias("LGPL-2.1-or-later", "GNU Lesser General Public License v2.1 or later");
+        alias("LGPL-3.0-only", "GNU Lesser General Public License v3.0", "GNU Lesser General Public License, Version 3",
+                "LGPL 3.0", "LGPL-3.0", "LGPLv3", "gnu.org/licenses/lgpl-3.0.html", "gnu.org/licenses/lgpl.html");
+        alias("GPL-2.0-only", "GNU General Public License v2.0", "GNU General Public License, Version 2", "GPL 2.0",
+                "GPL-2.0", "GPLv2", "gnu.org/licenses/old-licenses/gpl-2.0.html");
+        alias("GPL-2.0-only WITH Classpath-exception-2.0", "GPL-2.0-only WITH Classpath-exception-2.0",
+                "GNU General Public License, version 2, with the Classpath Exception",
+                "GPL2 w/ CPE", "GPLv2 with Classpath Exception", "GNU General Public License v2.0 w/Classpath exception",
+                "openjdk.java.net/legal/gplv2+ce.html");
+        alias("GPL-3.0-only", "GNU General Public License v3.0", "GNU General Public License, Version 3", "GPL 3.0",
+                "GPL-3.0", "GPLv3", "gnu.org/licenses/gpl-3.0.html", "gnu.org/licenses/gpl.html");
+        alias("AGPL-3.0-only", "GNU Affero General Public License v3.0", "AGPL 3.0", "AGPL-3.0", "AGPLv3",
+                "gnu.org/licenses/agpl-3.0.html");
+        alias("MPL-2.0", "Mozilla Public License 2.0", "Mozilla Public License, Version 2.0", "MPL 2.0",
+                "mozilla.org/MPL/2.0");
+        alias("MPL-1.1", "Mozilla Public License 1.1", "MPL 1.1");
+        alias("CDDL-1.0", "Common Development and Distribution License 1.0", "CDDL 1.0", "CDDL",
+                "COMMON DEVELOPMENT AND DISTRIBUTION LICENSE (CDDL) Version 1.0", "opensource.org/licenses/CDDL-1.0");
+        alias("CDDL-1.1", "Common Development and Distribution License 1.1", "CDDL 1.1", "CDDL+GPL License",
+                "CDDL + GPLv2 with classpath exception", "glassfish.java.net/public/CDDL+GPL_1_1.html");
+        alias("CC0-1.0", "CC0", "Public Domain, per Creative Commons CC0", "creativecommons.org/publicdomain/zero/1.0");
+        alias("Unlicense", "The Unlicense", "unlicense.org");
+        alias("BSL-1.0", "Boost Software License 1.0", "Boost Software License - Version 1.0");
+        alias("UPL-1.0", "Universal Permissive License 1.0", "The Universal Permissive License (UPL), Version 1.0");
+    }
+
+    private static void alias(String id, String... names) {
+        for (var name : names) {
+            ALIASES.put(key(name), id);
+        }
+    }
+
+    private static String key(String name) {
+        String key = name.trim().toLowerCase(Locale.ROOT);
+        //URLs are matched without the scheme or a www prefix
+        key = key.replaceFirst("^https?://", "").replaceFirst("^www\\.", "");
+        return NON_ALPHANUMERIC.matcher(key).replaceAll("");
+    }
+
+    /**
+     * Normalizes a license name, URL or SPDX expression into SPDX identifiers.
+     *
+     * @param license The license as it was found
+     * @return The SPDX identifiers, an expression containing AND is split into its licenses as they all apply. An
+     *         expression containing OR is kept as a single normalized expression, as only one of the licenses applies.
+     */
+    public static Set<String> normalize(String license) {
+        Set<String> ret = new LinkedHashSet<>();
+        if (license == null || license.isBlank()) {
+            return ret;
+        }
+        String id = ALIASES.get(key(license));
+        if (id != null) {
+            ret.add(id);
+            return ret;
+        }
+        String expression = stripParentheses(WHITESPACE.matcher(license.trim()).replaceAll(" "));
+        List<String> alternatives = splitExpression(expression, "OR");
+        if (alternatives.size() > 1) {
+            List<String> choices = new ArrayList<>();
+            for (var alternative : alternatives) {
+                Set<String> ids = normalize(alternative);
+                choices.add(ids.size() > 1 ? "(" + join(ids, " AND ") + ")" : join(ids, " AND "));
+            }
+            ret.add(String.join(" OR ", choices));
+            return ret;
+        }
+        List<String> parts = splitExpression(expression, "AND");
+        if (parts.size() > 1) {
+            for (var part : parts) {
+                ret.addAll(normalize(part));
+            }
+            return ret;
+        }
+        ret.add(LICENSE_REF_PREFIX + INVALID_REF.matcher(expression).replaceAll("-"));
+        return ret;
+    }
+
+    /**
+     * Checks a normalized license against a denylist. An OR expression is only denied if every choice is denied, an
+     * AND expression is denied if any of its licenses are.
+     */
+    public static boolean isDenied(String license, Collection<String> denied) {
+        String expression = stripParentheses(license.trim());
+        List<String> alternatives = splitExpression(expression, "OR");
+        if (alternatives.size() > 1) {
+            return alternatives.stream().allMatch(a -> isDenied(a, denied));
+        }
+        List<String> parts = splitExpression(expression, "AND");
+        if (parts.size() > 1) {
+            return parts.stream().anyMatch(p -> isDenied(p, denied));
+        }
+        return denied.stream().anyMatch(expression::equalsIgnoreCase);
+    }
+
+    /**
+     * Joins licenses, wrapping any OR expressions so the precedence is kept
+     */
+    private static String join(Set<String> ids, String operator) {
+        List<String> ret = new ArrayList<>();
+        for (var id : ids) {
+            ret.add(ids.size() > 1 && splitExpression(id, "OR").size() > 1 ? "(" + id + ")" : id);
+        }
+        return String.join(operator, ret);
+    }
+
+    /**
+     * Splits an expression on an operator, ignoring operators inside parentheses
+     */
+    static List<String> splitExpression(String expression, String operator) {
+        List<String> ret = new ArrayList<>();
+        String token = " " + operator + " ";
+        String upper = expression.toUpperCase(Locale.ROOT);
+        int depth = 0;
+        int start = 0;
+        for (int i = 0; i < expression.length(); i++) {
+            char c = expression.charAt(i);
+            if (c == '(') {
+                depth++;
+            } else if (c == ')') {
+                depth--;
+            } else if (depth == 0 && upper.startsWith(token, i)) {
+                ret.add(expression.substring(start, i).trim());
+                start = i + token.length();
+                i = start - 1;
+            }
+        }
+        ret.add(expression.substring(start).trim());
+        return ret;
+    }
+
+    /**
+     * Removes parentheses that enclose the whole expression
+     */
+    static String stripParentheses(String expression) {
+        while (expression.startsWith("(") && expression.endsWith(")")) {
+            int depth = 0;
+            for (int i = 0; i < expression.length() - 1; i++) {
+                char c = expression.charAt(i);
+                if (c == '(') {
+                    depth++;
+                } else if (c == ')') {
+                    depth--;
+                }
+                if (depth == 0) {
+                    //the first parenthesis closes before the end
+                    return expression;
+                }
+            }
+            expression = expression.substring(1, expression.length() - 1).trim();
+        }
+        return expression;
+    }
+
+    /**
+     * Normalizes a license name together with its URL. The name is preferred, the URL is only used if the name
+     * is not recognised.
+     */
+    public static Set<String> normalize(String name, String url) {
+        Set<String> ret = normalize(name);
+        if (url != null && (ret.isEmpty() || ret.iterator().next().startsWith(LICENSE_REF_PREFIX))) {
+            String id = ALIASES.get(key(url));
+            if (id != null) {
+                return new LinkedHashSet<>(Set.of(id));
+            }
+        }
+        return ret;
+    }
+}
@@ -0,0 +1,134 @@
+package com.redhat.hacbs.container.verifier;
+
+import java.util.ArrayList;
+import java.util.List;
+import java.util.Map;
+import java.util.Set;
+import java.util.TreeMap;
+import java.util.regex.Pattern;
+
+/**
+ * A summary of the differences found when verifying the built artifacts against the upstream ones, grouped by
+ * artifact and category so it can be stored on the DependencyBuild.
+ *
+ * @param artifacts The report for each verified artifact
+ */
+public record VerificationReport(List<ArtifactReport> artifacts) {
+
+    public static final String CLASS_ADDED = "ClassAdded";
+    public static final String CLASS_REMOVED = "ClassRemoved";
+    public static final String CLASS_CHANGED = "ClassChanged";
+    public static final String METHOD_ADDED = "MethodAdded";
+    public static final String METHOD_REMOVED = "MethodRemoved";
+    public static final String METHOD_CHANGED = "MethodChanged";
+    public static final String FIELD_ADDED = "FieldAdded";
+    public static final String FIELD_REMOVED = "FieldRemoved";
+    public static final String FIELD_CHANGED = "FieldChanged";
+    public static final String ANNOTATION_CHANGED = "AnnotationChanged";
+    public static final String OTHER = "Other";
+
+    /**
+     * The class level properties that {@link com.redhat.hacbs.container.verifier.asm.JarInfo} reports as changes,
+     * anything else is a change to a member of the class.
+     */
+    private static final Set<String> CLASS_PROPERTIES = Set.of("version", "access", "name", "signature", "superName",
+            "interfaces", "outerClass", "outerMethod", "outerMethodDesc", "module");
+
+    private static final Set<String> MEMBER_TYPES = Set.of("class", "method", "field", "annotation", "recordComponent");
+
+    /**
+     * @param gav The coordinates of the artifact
+     * @param file The file that was verified, relative to the deployment directory
+     * @param passed If there were no differences other than the allowed ones
+     * @param differences The number of differences that were not allowed, by category
+     * @param allowedDifferences The number of differences each allowed differences pattern matched
+     */
+    public record ArtifactReport(String gav, String file, boolean passed, List<DifferenceCount> differences,
+            List<AllowedDifferenceCount> allowedDifferences) {
+    }
+
+    public record DifferenceCount(String category, int count) {
+    }
+
+    public record AllowedDifferenceCount(String pattern, int count) {
+    }
+
+    /**
+     * Returns the differences that are not matched by any of the allowed differences.
+     */
+    public static List<String> failures(List<String> differences, Map<String, Pattern> allowedDifferences) {
+        var ret = new ArrayList<String>();
+        for (var difference : differences) {
+            if (allowedDifferences.values().stream().noneMatch(p -> p.matcher(difference).find())) {
+                ret.add(difference);
+            }
+        }
+        return ret;
+    }
+
+    /**
+     * Creates the report for a single artifact.
+     *
+     * @param differences All the differences, including the allowed ones
+     * @param allowedDifferences The allowed differences patterns as configured, mapped to the compiled pattern
+     */
+    public static ArtifactReport artifact(String gav, String file, List<String> differences,
+            Map<String, Pattern> allowedDifferences) {
+        var categories = new TreeMap<String, Integer>();
+        var allowed = new TreeMap<String, Integer>();
+        for (var difference : differences) {
+            var matched = false;
+            for (var e : allowedDifferences.entrySet()) {
+                if (e.getValue().matcher(difference).find()) {
+                    allowed.merge(e.getKey(), 1, Integer::sum);
+                    matched = true;
+                }
+            }
+            if (!matched) {
+                categories.merge(category(difference), 1, Integer::sum);
+            }
+        }
+        return new ArtifactReport(gav, file, categories.isEmpty(),
+                categories.entrySet().stream().map(e -> new DifferenceCount(e.getKey(), e.getValue())).toList(),
+                allowed.entrySet().stream().map(e -> new AllowedDifferenceCount(e.getKey(), e.getValue())).toList());
+    }
+
+    /**
+     * Works out the category of a difference reported by
+     * {@link com.redhat.hacbs.container.verifier.asm.JarInfo#diffJar}. These are in the form
+     * {@code +:jar:type:value} or {@code +:jar:class:type:value} for additions (and the same with {@code -} for
+     * removals), and {@code ^:jar:class:property:old>new} for changes. A changed method signature shows up as the
+     * old method being removed and the new one added.
+     */
+    static String category(String difference) {
+        var parts = difference.split(":", 5);
+        if (parts.length < 4) {
+            return OTHER;
+        }
+        var kind = parts[0];
+        if (kind.equals("+") || kind.equals("-")) {
+            var type = MEMBER_TYPES.contains(parts[2]) ? parts[2] : parts[3];
+            var added = kind.equals("+");
+            return switch (type) {
+                case "class" -> added ? CLASS_ADDED : CLASS_REMOVED;
+                case "method" -> added ? METHOD_ADDED : METHOD_REMOVED;
+                case "field", "recordComponent" -> added ? FIELD_ADDED : FIELD_REMOVED;
+                case "annotation" -> ANNOTATION_CHANGED;
+                default -> OTHER;
+            };
+        } else if (kind.equals("^")) {
+            var property = parts[3];
+            if (CLASS_PROPERTIES.contains(property)) {
+                return CLASS_CHANGED;
+            } else if (property.contains("(")) {
+                //method keys are the name and descriptor
+                return METHOD_CHANGED;
+            } else if (property.startsWith("L") && property.endsWith(";")) {
+                //annotation keys are the annotation descriptor
+                return ANNOTATION_CHANGED;
+            }
+            return FIELD_CHANGED;
+        }
+        return OTHER;
+    }
+}
@@ -18,7 +18,9 @@ import java.nio.file.Path;
 import java.nio.file.SimpleFileVisitor;
 import java.nio.file.attribute.BasicFileAttributes;
 import java.util.ArrayList;
+import java.util.Comparator;
 import java.util.HashMap;
+import java.util.LinkedHashMap;
 import java.util.LinkedHashSet;
 import java.util.List;
 import java.util.Map;
@@ -27,6 +29,7 @@ import java.util.concurrent.Callable;
 import java.util.concurrent.ExecutorService;
 import java.util.concurrent.Executors;
 import java.util.concurrent.Future;
+import java.util.regex.Pattern;
 
 import jakarta.enterprise.inject.Instance;
 import jakarta.inject.Inject;
@@ -130,7 +133,8 @@ public class VerifyBuiltArtifactsCommand implements Callable<Integer> {
             var excludes = getExcludes();
 
             if (options.localOptions.originalFile != null && options.localOptions.newFile != null) {
-                var numErrors = handleJar(options.localOptions.originalFile, options.localOptions.newFile, excludes);
+                var numErrors = VerificationReport.failures(
+                        handleJar(options.localOptions.originalFile, options.localOptions.newFile), excludes);
                 return (!numErrors.isEmpty() && !reportOnly ? 1 : 0);
             }
 
@@ -141,6 +145,7 @@ public class VerifyBuiltArtifactsCommand implements Callable<Integer> {
 
             Log.debugf("Deploy path: %s", options.mavenOptions.deployPath);
             var futureResults = new HashMap<String, Future<List<String>>>();
+            var files = new HashMap<String, String>();
 
             Files.walkFileTree(options.mavenOptions.deployPath, new SimpleFileVisitor<>() {
                 @Override
@@ -155,11 +160,12 @@ public class VerifyBuiltArtifactsCommand implements Callable<Integer> {
                             var failures = executorService.submit(new Callable<List<String>>() {
                                 @Override
                                 public List<String> call() throws Exception {
-                                    return handleJar(file, coords, excludes);
+                                    return handleJar(file, coords);
                                 }
                             });
 
                             futureResults.put(coords, failures);
+                            files.put(coords, relativeFile.toString());
                         } catch (Exception e) {
                             throw new RuntimeException(e);
                         }
@@ -171,9 +177,12 @@ public class VerifyBuiltArtifactsCommand implements Callable<Integer> {
 
             boolean failed = false;
             var verificationResults = new HashMap<String, List<String>>();
+            var artifactReports = new ArrayList<VerificationReport.ArtifactReport>();
             for (var e : futureResults.entrySet()) {
-                List<String> results = e.getValue().get();
+                List<String> differences = e.getValue().get();
+                List<String> results = VerificationReport.failures(differences, excludes);
                 verificationResults.put(e.getKey(), results);
+                artifactReports.add(VerificationReport.artifact(e.getKey(), files.get(e.getKey()), differences, excludes));
                 if (results.isEmpty()) {
                     Log.infof("Passed: %s", e.getKey());
                 } else {
@@ -192,7 +201,10 @@ public class VerifyBuiltArtifactsCommand implements Callable<Integer> {
             if (taskRunName != null) {
                 var json = ResultsUpdater.MAPPER.writeValueAsString(verificationResults);
                 io.quarkus.logging.Log.infof("Writing verification results %s", json);
-                resultsUpdater.get().updateResults(taskRunName, Map.of("VERIFICATION_RESULTS", json));
+                artifactReports.sort(Comparator.comparing(VerificationReport.ArtifactReport::gav));
+                var report = ResultsUpdater.MAPPER.writeValueAsString(new VerificationReport(artifactReports));
+                resultsUpdater.get().updateResults(taskRunName,
+                        Map.of("VERIFICATION_RESULTS", json, "VERIFICATION_REPORT", report));
             }
             return (failed && !reportOnly ? 1 : 0);
         } catch (Exception e) {
@@ -213,15 +225,20 @@ public class VerifyBuiltArtifactsCommand implements Callable<Integer> {
         }
     }
 
-    private List<String> getExcludes() throws IOException {
-        var newExcludes = new ArrayList<String>();
+    /**
+     * Returns the excludes as they were configured, mapped to the pattern they are matched with.
+     */
+    private Map<String, Pattern> getExcludes() throws IOException {
+        var newExcludes = new LinkedHashMap<String, Pattern>();
         if (excludesFile != null) {
             if (!Files.isRegularFile(excludesFile) || !Files.isReadable(excludesFile)) {
                 throw new RuntimeException("Error reading excludes file " + excludesFile.toAbsolutePath());
             }
 
             var lines = Files.readAllLines(excludesFile);
-            newExcludes.addAll(lines);
+            for (var line : lines) {
+                newExcludes.put(line, Pattern.compile(line));
+            }
         }
 
         for (var exclude : excludes) {
@@ -230,7 +247,7 @@ public class VerifyBuiltArtifactsCommand implements Callable<Integer> {
                 return null;
             }
 
-            newExcludes.add(exclude.replaceAll("^([+-^])", "^\\\\$1"));
+            newExcludes.put(exclude, Pattern.compile(exclude.replaceAll("^([+-^])", "^\\\\$1")));
         }
 
         return newExcludes;
@@ -261,13 +278,17 @@ public class VerifyBuiltArtifactsCommand implements Callable<Integer> {
         }
     }
 
-    private List<String> handleJar(Path remoteFile, Path file, List<String> excludes) {
+    /**
+     * Returns all the differences between the jars, the excludes are applied by the caller so the report can
+     * include the differences they matched.
+     */
+    private List<String> handleJar(Path remoteFile, Path file) {
         var left = new JarInfo(remoteFile);
         var right = new JarInfo(file);
-        return left.diffJar(right, excludes);
+        return left.diffJar(right, List.of());
     }
 
-    private List<String> handleJar(Path file, String coords, List<String> excludes) {
+    private List<String> handleJar(Path file, String coords) {
         try {
             var optionalRemoteFile = resolveArtifact(coords, remoteRepositories, session, system);
 
@@ -278,7 +299,7 @@ public class VerifyBuiltArtifactsCommand implements Callable<Integer> {
 
             var remoteFile = optionalRemoteFile.get();
             Log.infof("Verifying %s (%s, %s)", coords, remoteFile.toAbsolutePath(), file.toAbsolutePath());
-            var errors = handleJar(remoteFile, file, excludes);
+            var errors = handleJar(remoteFile, file);
             int numFailures = errors.size();
 
             Log.debugf("Verification of %s %s", coords, numFailures > 0 ? "failed" : "passed");
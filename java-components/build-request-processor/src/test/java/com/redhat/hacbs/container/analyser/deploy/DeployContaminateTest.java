@@ -1,5 +1,7 @@
 package com.redhat.hacbs.container.analyser.deploy;
 
+import static org.junit.jupiter.api.Assertions.assertEquals;
+import static org.junit.jupiter.api.Assertions.assertFalse;
 import static org.junit.jupiter.api.Assertions.assertTrue;
 import static org.junit.jupiter.api.Assertions.fail;
 
@@ -12,6 +14,7 @@ import java.nio.file.Path;
 import java.nio.file.Paths;
 import java.util.List;
 import java.util.Map;
+import java.util.Optional;
 import java.util.Set;
 import java.util.jar.JarEntry;
 import java.util.jar.JarOutputStream;
@@ -108,14 +111,71 @@ public class DeployContaminateTest {
                 .contains("GAVs to deploy: [com.company.foo:foo-bar:3.25.8, com.company.foo:foo-baz:3.25.8")));
     }
 
+    @Test
+    public void testDeniedLicenses() {
+        TestDeployment testDeployment = new TestDeployment(null, resultsUpdater);
+        testDeployment.deniedLicenses = List.of("GPL-2.0-only");
+        //a dual licensed artifact can be used under the license that is not denied
+        assertEquals(Map.of("com.test:denied:1.0", Set.of("GPL-2.0-only")), testDeployment.deniedLicenses(Map.of(
+                "com.test:denied:1.0", Set.of("GPL-2.0-only", "MIT"),
+                "com.test:dual:1.0", Set.of("GPL-2.0-only OR Apache-2.0"))));
+    }
+
+    @Test
+    public void testDeployWithAllowedContaminant()
+            throws IOException, URISyntaxException {
+        Path onDiskRepo = createDeploymentRepo(Map.of(
+                FOO_BAR, "foobar-" + VERSION + ".jar",
+                FOO_BAZ, "foobaz-" + VERSION + ".jar"));
+        Path source = Files.createTempDirectory("hacbs");
+        Files.writeString(source.resolve("pom.xml"), "");
+
+        TestDeployment testDeployment = new TestDeployment(null, resultsUpdater);
+        testDeployment.deploymentPath = onDiskRepo.toAbsolutePath();
+        testDeployment.imageId = "test-image";
+        testDeployment.scmUri = REPO;
+        testDeployment.commit = COMMIT;
+        testDeployment.sourcePath = source.toAbsolutePath();
+        testDeployment.allowedSources = Set.of("redhat", "rebuilt"); // Default value
+        testDeployment.allowedContaminants = List.of("org.jboss.metadata:*@" + GROUP + ":" + FOO_BAR + ":*");
+
+        testDeployment.run();
+        List<LogRecord> logRecords = LogCollectingTestResource.current().getRecords();
+        assertTrue(logRecords.stream().anyMatch(r -> LogCollectingTestResource.format(r).contains(
+                "contaminant org.jboss.metadata:jboss-metadata-common:9.0.0.Final is allowed by the contaminant policy")));
+        assertTrue(logRecords.stream().noneMatch(r -> r.getMessage().contains("Removing")));
+        assertTrue(logRecords.stream().anyMatch(r -> LogCollectingTestResource.format(r)
+                .contains("GAVs to deploy: [com.company.foo:foo-bar:3.25.8, com.company.foo:foo-baz:3.25.8")));
+        assertTrue(Files.exists(onDiskRepo.resolve("com/company/foo/foo-bar/3.25.8/foobar-3.25.8.jar")));
+    }
+
+    @Test
+    public void testAllowedContaminantPatterns() {
+        TestDeployment testDeployment = new TestDeployment(null, resultsUpdater);
+        testDeployment.allowedContaminants = List.of("com.google.guava:*", "org.slf4j:slf4j-api:*@com.test:shaded:*");
+        Optional<Gav> shaded = Optional.of(Gav.create("com.test", "shaded", "1.0"));
+        Optional<Gav> other = Optional.of(Gav.create("com.test", "other", "1.0"));
+        assertTrue(testDeployment.isAllowedContaminant("com.google.guava:guava:31.1-jre", other));
+        assertTrue(testDeployment.isAllowedContaminant("com.google.guava:guava:31.1-jre", Optional.empty()));
+        assertTrue(testDeployment.isAllowedContaminant("org.slf4j:slf4j-api:2.0.7", shaded));
+        assertFalse(testDeployment.isAllowedContaminant("org.slf4j:slf4j-api:2.0.7", other));
+        assertFalse(testDeployment.isAllowedContaminant("org.slf4j:slf4j-api:2.0.7", Optional.empty()));
+        assertFalse(testDeployment.isAllowedContaminant("org.jboss:jboss:1.0", shaded));
+    }
+
     private Path createDeploymentRepo()
             throws IOException, URISyntaxException {
+        return createDeploymentRepo(ARTIFACT_FILE_MAP);
+    }
+
+    private Path createDeploymentRepo(Map<String, String> artifactFiles)
+            throws IOException, URISyntaxException {
         Path testData = Files.createTempDirectory("test-data");
         Path artifacts = Paths.get(testData.toString(), "artifacts").toAbsolutePath();
         Files.createDirectories(artifacts);
 
         // Add data to artifacts folder
-        for (Map.Entry<String, String> artifactFile : ARTIFACT_FILE_MAP.entrySet()) {
+        for (Map.Entry<String, String> artifactFile : artifactFiles.entrySet()) {
             String groupPath = GROUP.replace(DOT, File.separator);
             Path testDir = Paths.get(artifacts.toString(), groupPath, artifactFile.getKey(),
                     VERSION);
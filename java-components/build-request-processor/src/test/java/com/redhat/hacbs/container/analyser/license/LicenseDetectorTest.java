@@ -0,0 +1,122 @@
+package com.redhat.hacbs.container.analyser.license;
+
+import java.io.ByteArrayInputStream;
+import java.io.ByteArrayOutputStream;
+import java.io.IOException;
+import java.nio.charset.StandardCharsets;
+import java.nio.file.Files;
+import java.nio.file.Path;
+import java.util.Set;
+import java.util.zip.ZipEntry;
+import java.util.zip.ZipOutputStream;
+
+import org.junit.jupiter.api.Assertions;
+import org.junit.jupiter.api.Test;
+import org.junit.jupiter.api.io.TempDir;
+import org.junit.jupiter.params.ParameterizedTest;
+import org.junit.jupiter.params.provider.CsvSource;
+
+public class LicenseDetectorTest {
+
+    static final String POM = """
+            <project>
+              <modelVersion>4.0.0</modelVersion>
+              <groupId>com.test</groupId>
+              <artifactId>test</artifactId>
+              <version>1.0</version>
+              <licenses>
+                <license>
+                  <name>The Apache Software License, Version 2.0</name>
+                  <url>https://www.apache.org/licenses/LICENSE-2.0.txt</url>
+                </license>
+                <license>
+                  <name>Some License</name>
+                  <url>https://www.eclipse.org/legal/epl-2.0</url>
+                </license>
+              </licenses>
+            </project>
+            """;
+
+    @TempDir
+    Path source;
+
+    @Test
+    public void testNormalize() {
+        Assertions.assertEquals(Set.of("Apache-2.0"), SpdxLicenses.normalize("Apache License, Version 2.0"));
+        Assertions.assertEquals(Set.of("MIT"), SpdxLicenses.normalize("https://opensource.org/licenses/MIT"));
+        Assertions.assertEquals(Set.of("EPL-2.0 OR GPL-2.0-only WITH Classpath-exception-2.0"),
+                SpdxLicenses.normalize("(EPL-2.0 OR GPL-2.0-only WITH Classpath-exception-2.0)"));
+        Assertions.assertEquals(Set.of("MIT", "Apache-2.0"), SpdxLicenses.normalize("MIT AND Apache-2.0"));
+        Assertions.assertEquals(Set.of("(MIT AND (EPL-2.0 OR Apache-2.0)) OR GPL-2.0-only"),
+                SpdxLicenses.normalize("(MIT and (EPL-2.0 or Apache-2.0)) OR GPL-2.0-only"));
+        Assertions.assertEquals(Set.of("LicenseRef-My-Custom-License"), SpdxLicenses.normalize("My Custom License"));
+        Assertions.assertEquals(Set.of("EPL-2.0"), SpdxLicenses.normalize("Some License", "https://www.eclipse.org/legal/epl-2.0"));
+    }
+
+    @Test
+    public void testIsDenied() {
+        var denied = Set.of("gpl-2.0-only", "AGPL-3.0-only");
+        Assertions.assertTrue(SpdxLicenses.isDenied("GPL-2.0-only", denied));
+        Assertions.assertFalse(SpdxLicenses.isDenied("GPL-2.0-only OR Apache-2.0", denied));
+        Assertions.assertTrue(SpdxLicenses.isDenied("GPL-2.0-only OR AGPL-3.0-only", denied));
+        Assertions.assertTrue(SpdxLicenses.isDenied("(MIT AND GPL-2.0-only) OR AGPL-3.0-only", denied));
+        Assertions.assertFalse(SpdxLicenses.isDenied("(MIT AND GPL-2.0-only) OR Apache-2.0", denied));
+        Assertions.assertFalse(SpdxLicenses.isDenied("GPL-2.0-only WITH Classpath-exception-2.0", denied));
+    }
+
+    @Test
+    public void testDetectInText() {
+        Assertions.assertEquals(Set.of("Apache-2.0"), LicenseDetector.detectInText("""
+                                         Apache License
+                                   Version 2.0, January 2004
+                                http://www.apache.org/licenses/
+                """));
+        Assertions.assertEquals(Set.of("LGPL-2.1-only"), LicenseDetector.detectInText("""
+                                  GNU LESSER GENERAL PUBLIC LICENSE
+                                       Version 2.1, February 1999
+                """));
+        Assertions.assertEquals(Set.of("MIT"), LicenseDetector.detectInText(
+                "Permission is hereby granted, free of charge, to any person obtaining a copy"));
+    }
+
+    @ParameterizedTest
+    @CsvSource({ "GPL-2.0.txt, GPL-2.0-only", "GPL-3.0.txt, GPL-3.0-only", "LGPL-3.0.txt, LGPL-3.0-only",
+            "AGPL-3.0.txt, AGPL-3.0-only" })
+    public void testDetectGnuLicenses(String fixture, String license) throws IOException {
+        //the GNU licenses quote each other, so only the title identifies them
+        try (var in = getClass().getClassLoader().getResourceAsStream("licenses/" + fixture)) {
+            Assertions.assertEquals(Set.of(license),
+                    LicenseDetector.detectInText(new String(in.readAllBytes(), StandardCharsets.UTF_8)));
+        }
+    }
+
+    @Test
+    public void testDetectInJar() throws IOException {
+        ByteArrayOutputStream out = new ByteArrayOutputStream();
+        try (ZipOutputStream zip = new ZipOutputStream(out)) {
+            zip.putNextEntry(new ZipEntry("META-INF/MANIFEST.MF"));
+            zip.write("Manifest-Version: 1.0\nBundle-License: https://opensource.org/licenses/MIT\n\n"
+                    .getBytes(StandardCharsets.UTF_8));
+            zip.putNextEntry(new ZipEntry("META-INF/maven/com.test/test/pom.xml"));
+            zip.write(POM.getBytes(StandardCharsets.UTF_8));
+            zip.putNextEntry(new ZipEntry("META-INF/LICENSE.txt"));
+            zip.write("Redistribution and use in source and binary forms, with or without modification"
+                    .getBytes(StandardCharsets.UTF_8));
+            zip.putNextEntry(new ZipEntry("com/test/Test.class"));
+            zip.write(new byte[] { 1, 2, 3 });
+        }
+        Assertions.assertEquals(Set.of("MIT", "Apache-2.0", "EPL-2.0", "BSD-2-Clause"),
+                LicenseDetector.detectInJar(new ByteArrayInputStream(out.toByteArray())));
+    }
+
+    @Test
+    public void testDetectInSourceTree() throws IOException {
+        Files.writeString(source.resolve("pom.xml"), POM);
+        Files.createDirectories(source.resolve("src/main/java/com/test"));
+        Files.writeString(source.resolve("src/main/java/com/test/Test.java"),
+                "/*\n * SPDX-License-Identifier: GPL-3.0-only\n */\npackage com.test;\n");
+        Files.createDirectories(source.resolve("target"));
+        Files.writeString(source.resolve("target/Generated.java"), "// SPDX-License-Identifier: AGPL-3.0-only\n");
+        Assertions.assertEquals(Set.of("Apache-2.0", "EPL-2.0", "GPL-3.0-only"), LicenseDetector.detectInSourceTree(source));
+    }
+}
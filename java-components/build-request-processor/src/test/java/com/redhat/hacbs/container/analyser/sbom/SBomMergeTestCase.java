@@ -1,5 +1,6 @@
 package com.redhat.hacbs.container.analyser.sbom;
 
+import java.util.List;
 import java.util.Map;
 import java.util.Set;
 
@@ -51,4 +52,35 @@ public class SBomMergeTestCase {
         Assertions.assertEquals("central", test.getPublisher());
 
     }
+
+    @Test
+    public void testBuildSbom() {
+        var sbom = SBomGenerator.generateBuildSBom(
+                List.of(new SBomGenerator.BuildArtifact("com.test", "test", "1.0", "jar", null, "aaaa", "bbbb"),
+                        new SBomGenerator.BuildArtifact("com.test", "test", "1.0", "jar", "sources", "cccc", "dddd")),
+                Set.of(new TrackingData("com.test:dependency:2.0", "central", Map.of())),
+                "https://github.com/test/test.git", "abc123", "quay.io/test/builder@sha256:1234");
+        Assertions.assertEquals("https://github.com/test/test.git", sbom.getMetadata().getComponent().getName());
+        Assertions.assertEquals("abc123", sbom.getMetadata().getComponent().getVersion());
+        Component jar = null;
+        Component dependency = null;
+        Component builder = null;
+        for (var c : sbom.getComponents()) {
+            if ("pkg:maven/com.test/test@1.0?type=jar".equals(c.getPurl())) {
+                jar = c;
+            } else if (c.getName().equals("dependency")) {
+                dependency = c;
+            } else if (c.getType() == Component.Type.CONTAINER) {
+                builder = c;
+            }
+        }
+        Assertions.assertEquals(4, sbom.getComponents().size());
+        Assertions.assertNotNull(jar);
+        Assertions.assertEquals(2, jar.getHashes().size());
+        Assertions.assertEquals("bbbb", jar.getHashes().get(0).getValue());
+        Assertions.assertNotNull(dependency);
+        Assertions.assertEquals(Component.Scope.EXCLUDED, dependency.getScope());
+        Assertions.assertNotNull(builder);
+        Assertions.assertEquals("quay.io/test/builder@sha256:1234", builder.getName());
+    }
 }
@@ -0,0 +1,46 @@
+package com.redhat.hacbs.container.verifier;
+
+import static org.assertj.core.api.Assertions.assertThat;
+
+import java.util.List;
+import java.util.Map;
+import java.util.regex.Pattern;
+
+import org.junit.jupiter.api.Test;
+
+class VerificationReportTest {
+
+    @Test
+    void testCategory() {
+        assertThat(VerificationReport.category("+:test.jar:class:org/foo/Bar")).isEqualTo(VerificationReport.CLASS_ADDED);
+        assertThat(VerificationReport.category("-:test.jar:org/foo/Bar:method:baz()V"))
+                .isEqualTo(VerificationReport.METHOD_REMOVED);
+        assertThat(VerificationReport.category("+:test.jar:org/foo/Bar:field:baz"))
+                .isEqualTo(VerificationReport.FIELD_ADDED);
+        assertThat(VerificationReport.category("^:test.jar:org/foo/Bar:version:52>55"))
+                .isEqualTo(VerificationReport.CLASS_CHANGED);
+        assertThat(VerificationReport.category("^:test.jar:org/foo/Bar:baz()V:access:1>9"))
+                .isEqualTo(VerificationReport.METHOD_CHANGED);
+        assertThat(VerificationReport.category("unknown")).isEqualTo(VerificationReport.OTHER);
+    }
+
+    @Test
+    void testArtifact() {
+        var differences = List.of("+:test.jar:class:org/foo/Bar", "+:test.jar:class:org/foo/Baz",
+                "^:test.jar:org/foo/Qux:version:52>55");
+        var allowed = Map.of("^\\^:.*:version:", Pattern.compile("^\\^:.*:version:"));
+
+        var report = VerificationReport.artifact("org.foo:bar:1.0", "org/foo/bar/1.0/bar-1.0.jar", differences, allowed);
+        assertThat(report.passed()).isFalse();
+        assertThat(report.differences())
+                .containsExactly(new VerificationReport.DifferenceCount(VerificationReport.CLASS_ADDED, 2));
+        assertThat(report.allowedDifferences())
+                .containsExactly(new VerificationReport.AllowedDifferenceCount("^\\^:.*:version:", 1));
+        assertThat(VerificationReport.failures(differences, allowed)).hasSize(2);
+
+        report = VerificationReport.artifact("org.foo:bar:1.0", "org/foo/bar/1.0/bar-1.0.jar", differences.subList(2, 3),
+                allowed);
+        assertThat(report.passed()).isTrue();
+        assertThat(report.differences()).isEmpty();
+    }
+}
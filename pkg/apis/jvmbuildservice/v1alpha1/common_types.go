@@ -1,9 +1,11 @@
 package v1alpha1
 
 type SCMInfo struct {
-	SCMURL     string `json:"scmURL,omitempty"`
-	SCMType    string `json:"scmType,omitempty"`
-	Tag        string `json:"tag,omitempty"`
+	SCMURL string `json:"scmURL,omitempty"`
+	// One of git (the default), hg, svn or source-archive
+	SCMType string `json:"scmType,omitempty"`
+	Tag     string `json:"tag,omitempty"`
+	// The commit to build, for svn this is the revision and for source-archive it is the sha256 of the archive
 	CommitHash string `json:"commitHash,omitempty"`
 	Path       string `json:"path,omitempty"`
 	Private    bool   `json:"private,omitempty"`
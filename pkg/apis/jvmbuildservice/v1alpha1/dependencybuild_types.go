@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -14,6 +15,36 @@ const (
 	DependencyBuildStateComplete     = "DependencyBuildStateComplete"
 	DependencyBuildStateFailed       = "DependencyBuildStateFailed"
 	DependencyBuildStateContaminated = "DependencyBuildStateContaminated"
+
+	AdditionalDownloadTypeExecutable = "executable"
+	AdditionalDownloadTypeTar        = "tar"
+	AdditionalDownloadTypeTarGz      = "tar.gz"
+	AdditionalDownloadTypeTarBz2     = "tar.bz2"
+	AdditionalDownloadTypeTarXz      = "tar.xz"
+	AdditionalDownloadTypeZip        = "zip"
+	AdditionalDownloadTypeRpm        = "rpm"
+	AdditionalDownloadTypeMaven      = "maven"
+
+	// The tag or commit did not have a valid signature, the build is failed without trying other recipes
+	DependencyBuildFailureReasonSignatureVerification = "SignatureVerificationFailed"
+	// No builder image had the tools the remaining recipes need, the build is re-queued if the SystemConfig changes
+	DependencyBuildFailureReasonNoBuilderImage = "NoMatchingBuilderImage"
+	// The output of the two builds was different and the JBSConfig requires reproducible builds
+	DependencyBuildFailureReasonNotReproducible = "NotReproducible"
+	// The build produced artifacts with a license denied by the JBSConfig, and the policy requires the build to fail
+	DependencyBuildFailureReasonDeniedLicense = "DeniedLicense"
+	// The build recipe could not be turned into a pipeline, e.g. an invalid patch or additional download
+	DependencyBuildFailureReasonInvalidRecipe = "InvalidRecipe"
+
+	// The condition recording if building twice produced the same output
+	DependencyBuildConditionReproducible = "Reproducible"
+	// The condition recording if the artifacts only have licenses allowed by the JBSConfig
+	DependencyBuildConditionLicenseCompliant = "LicenseCompliant"
+	// The condition recording if the contaminants of a contaminated build lead back to a build that is blocked by them
+	DependencyBuildConditionContaminationCycle = "ContaminationCycle"
+	// The condition recording if the contaminants of a contaminated build can still be rebuilt, it is false if the
+	// ArtifactBuild or DependencyBuild of a contaminant has failed
+	DependencyBuildConditionContaminationResolvable = "ContaminationResolvable"
 )
 
 type DependencyBuildSpec struct {
@@ -29,6 +60,13 @@ type DependencyBuildStatus struct {
 	State        string             `json:"state,omitempty"`
 	Message      string             `json:"message,omitempty"`
 	Contaminants []Contaminant      `json:"contaminates,omitempty"`
+	// Contaminants that were found but are allowed by the JBSConfig contaminant policy
+	AllowedContaminants []Contaminant `json:"allowedContaminants,omitempty"`
+	// The GAVs of contaminants that were allowed to break a contamination cycle, they are allowed when the build is run
+	// again so the artifacts they contaminate are deployed
+	CycleAllowedContaminants []string `json:"cycleAllowedContaminants,omitempty"`
+	// The builds of the contaminants, and of their contaminants, that a contaminated build is waiting for
+	ContaminationGraph []ContaminationNode `json:"contaminationGraph,omitempty"`
 	// PotentialBuildRecipes additional recipes to try if the current recipe fails
 	PotentialBuildRecipes    []*BuildRecipe   `json:"potentialBuildRecipes,omitempty"`
 	CommitTime               int64            `json:"commitTime,omitempty"`
@@ -38,6 +76,26 @@ type DependencyBuildStatus struct {
 	PipelineRetries          int              `json:"pipelineRetries,omitempty"`
 	BuildAttempts            []*BuildAttempt  `json:"buildAttempts,omitempty"`
 	DiscoveryPipelineResults *PipelineResults `json:"discoveryPipelineResults,omitempty"`
+	// Set if the build failed for a reason that means retrying with a different recipe will not help
+	FailureReason string `json:"failureReason,omitempty"`
+	// Recipes that could not be tried because no builder image had the required tool versions
+	UnmatchedBuildRecipes []*BuildRecipe `json:"unmatchedBuildRecipes,omitempty"`
+	// Requeues records each time the build was re-queued because new builder images became available
+	Requeues []BuildRequeue `json:"requeues,omitempty"`
+	// The files that were different between the two builds of the reproducibility check, this may be truncated
+	ReproducibilityDifferences []ReproducibilityDifference `json:"reproducibilityDifferences,omitempty"`
+}
+
+type ReproducibilityDifference struct {
+	File string `json:"file"`
+	// One of ChecksumMismatch, OnlyInFirstBuild or OnlyInSecondBuild
+	Reason string `json:"reason"`
+}
+
+type BuildRequeue struct {
+	Time metav1.Time `json:"time,omitempty"`
+	// Why the build was re-queued, e.g. which builder images now have the required tools
+	Reason string `json:"reason,omitempty"`
 }
 
 // +genclient
@@ -71,6 +129,62 @@ type BuildAttempt struct {
 	BuildId string            `json:"buildId,omitempty"`
 	Recipe  *BuildRecipe      `json:"buildRecipe,omitempty"`
 	Build   *BuildPipelineRun `json:"build,omitempty"`
+	// The result of the signature verification, only present if it is enabled in the JBSConfig
+	SignatureVerification *SignatureVerificationResult `json:"signatureVerification,omitempty"`
+	// The resources used by each step of the build, used to recommend resources for later builds of the same repository
+	ResourceUsage []StepResourceUsage `json:"resourceUsage,omitempty"`
+	// The differences found when comparing the built artifacts to the upstream ones
+	VerificationReport *VerificationReport `json:"verificationReport,omitempty"`
+}
+
+// VerificationReport is the result of verifying the built artifacts against the upstream artifacts. If the report is
+// too large to be stored in the status it is stored in a ConfigMap instead, and only the ConfigMap name is set.
+type VerificationReport struct {
+	Artifacts []ArtifactVerification `json:"artifacts,omitempty"`
+	// The name of the ConfigMap that holds the report under the report.json key
+	ConfigMap string `json:"configMap,omitempty"`
+}
+
+type ArtifactVerification struct {
+	GAV string `json:"gav"`
+	// The verified file, relative to the deployment directory
+	File   string `json:"file,omitempty"`
+	Passed bool   `json:"passed"`
+	// The number of differences that were not allowed, by category
+	Differences []VerificationDifferenceCount `json:"differences,omitempty"`
+	// The number of differences each of the recipe AllowedDifferences patterns matched
+	AllowedDifferences []AllowedDifferenceCount `json:"allowedDifferences,omitempty"`
+}
+
+type VerificationDifferenceCount struct {
+	// The category of the difference, e.g. ClassAdded, MethodRemoved or FieldChanged
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+}
+
+type AllowedDifferenceCount struct {
+	Pattern string `json:"pattern"`
+	Count   int    `json:"count"`
+}
+
+// StepResourceUsage is the resources a build step actually used, read from the cgroup of the step container
+type StepResourceUsage struct {
+	Step string `json:"step"`
+	// The peak memory usage in bytes
+	PeakMemoryBytes int64 `json:"peakMemoryBytes,omitempty"`
+	// The average CPU usage over the life of the step in millicores
+	AverageCPUMillicores int64 `json:"averageCPUMillicores,omitempty"`
+	// If the step was killed because it ran out of memory, in which case the usage could not be recorded
+	OOMKilled bool `json:"oomKilled,omitempty"`
+}
+
+type SignatureVerificationResult struct {
+	Verified bool `json:"verified"`
+	// Either gpg or ssh
+	Type SignatureType `json:"type,omitempty"`
+	// What the signature was verified on, either tag or commit
+	SignedObject string `json:"signedObject,omitempty"`
+	Message      string `json:"message,omitempty"`
 }
 
 type BuildPipelineRun struct {
@@ -115,6 +229,13 @@ func (r *DependencyBuildStatus) CurrentBuildAttempt() *BuildAttempt {
 	return r.BuildAttempts[len(r.BuildAttempts)-1]
 }
 
+// ClearContaminationGraph removes the contamination graph and its conditions once the build is no longer contaminated
+func (r *DependencyBuildStatus) ClearContaminationGraph() {
+	r.ContaminationGraph = nil
+	meta.RemoveStatusCondition(&r.Conditions, DependencyBuildConditionContaminationCycle)
+	meta.RemoveStatusCondition(&r.Conditions, DependencyBuildConditionContaminationResolvable)
+}
+
 type BuildRecipe struct {
 	//Deprecated
 	Pipeline            string               `json:"pipeline,omitempty"`
@@ -132,18 +253,92 @@ type BuildRecipe struct {
 	AdditionalMemory    int                  `json:"additionalMemory,omitempty"`
 	Repositories        []string             `json:"repositories,omitempty"`
 	AllowedDifferences  []string             `json:"allowedDifferences,omitempty"`
+	GitOptions          *GitCloneOptions     `json:"gitOptions,omitempty"`
+	// Patches applied in order to the source after checkout
+	Patches []BuildPatch `json:"patches,omitempty"`
+	// Environment variables for the build, these must be allowed by the JBSConfig
+	Env []BuildEnvVar `json:"env,omitempty"`
+	// Secrets mounted into the build, these must be allowed by the JBSConfig
+	SecretMounts []SecretMount `json:"secretMounts,omitempty"`
+	// The CPU for the build steps, recommended from the usage of earlier builds of the same repository
+	BuildCPU string `json:"buildCPU,omitempty"`
+	// Overrides the settings that make the build environment stable, so the output is reproducible
+	Reproducible *ReproducibleBuildSettings `json:"reproducible,omitempty"`
+}
+
+// ReproducibleBuildSettings control the environment of the build, SOURCE_DATE_EPOCH is always set to the commit time
+type ReproducibleBuildSettings struct {
+	// The locale used for LANG and LC_ALL, defaults to en_US.UTF-8
+	Locale string `json:"locale,omitempty"`
+	// The time zone, defaults to UTC
+	TimeZone string `json:"timeZone,omitempty"`
+	// The umask, defaults to 0022
+	Umask string `json:"umask,omitempty"`
+	// Don't set the output timestamp of the build tool to the commit time, for projects that set their own
+	DisableOutputTimestamp bool `json:"disableOutputTimestamp,omitempty"`
+	// Build twice and compare the output, even if the JBSConfig does not enable the reproducibility check
+	Check bool `json:"check,omitempty"`
 }
 type Contaminant struct {
 	GAV                   string   `json:"gav,omitempty"`
 	ContaminatedArtifacts []string `json:"contaminatedArtifacts,omitempty"`
 }
+type ContaminationNode struct {
+	// The GAV of the contaminant
+	GAV string `json:"gav"`
+	// The ArtifactBuild rebuilding the contaminant
+	ArtifactBuild      string `json:"artifactBuild,omitempty"`
+	ArtifactBuildState string `json:"artifactBuildState,omitempty"`
+	// The DependencyBuild rebuilding the contaminant, once it has been discovered
+	DependencyBuild      string `json:"dependencyBuild,omitempty"`
+	DependencyBuildState string `json:"dependencyBuildState,omitempty"`
+	// The contaminants the DependencyBuild of this contaminant is waiting for
+	BlockedBy []string `json:"blockedBy,omitempty"`
+}
 type AdditionalDownload struct {
-	Uri         string `json:"uri,omitempty"`
-	Sha256      string `json:"sha256,omitempty"`
-	FileName    string `json:"fileName,omitempty"`
-	BinaryPath  string `json:"binaryPath,omitempty"`
+	Uri string `json:"uri,omitempty"`
+	// The sha256 of the downloaded file, required for every type except rpm. Rpms are installed by name from the
+	// package repositories of the builder image, so there is no file to check before the install, and the package
+	// manager verifies them against the repository signatures instead.
+	Sha256     string `json:"sha256,omitempty"`
+	FileName   string `json:"fileName,omitempty"`
+	BinaryPath string `json:"binaryPath,omitempty"`
+	// The package to install for the rpm type
 	PackageName string `json:"packageName,omitempty"`
-	FileType    string `json:"type"`
+	// One of executable, tar, tar.gz, tar.bz2, tar.xz, zip, rpm or maven
+	FileType string `json:"type"`
+	// The number of leading path components removed when extracting an archive
+	StripComponents int `json:"stripComponents,omitempty"`
+	// The artifact to download for the maven type, in the form groupId:artifactId[:extension[:classifier]]:version
+	Gav string `json:"gav,omitempty"`
+}
+
+// A unified diff applied to the source before the build, either inline or downloaded from a URL
+type BuildPatch struct {
+	Diff string `json:"diff,omitempty"`
+	URL  string `json:"url,omitempty"`
+	// Required if the patch is downloaded from a URL
+	Sha256 string `json:"sha256,omitempty"`
+}
+
+type BuildEnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+type SecretMount struct {
+	SecretName string `json:"secretName"`
+	// The directory the secret is mounted at
+	MountPath string `json:"mountPath"`
+}
+
+type GitCloneOptions struct {
+	// If this is set the repository is fetched with the given depth, and the commit is fetched directly rather than cloning the full history
+	Depth int `json:"depth,omitempty"`
+	// If this is true only the context path of the build (and the top level files) is checked out
+	SparseCheckout bool `json:"sparseCheckout,omitempty"`
+	// If this is true git LFS content is pulled after checkout
+	LFS bool `json:"lfs,omitempty"`
 }
 
 // A representation of the Tekton Results records for a pipeline
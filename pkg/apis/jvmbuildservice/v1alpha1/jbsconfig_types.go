@@ -18,6 +18,16 @@ const (
 	MavenSecretName                         = "jvm-build-maven-repo-secrets"     //#nosec
 	GitRepoSecretKey                        = "gitdeploytoken"                   //#nosec
 	GitRepoSecretName                       = "jvm-build-git-repo-secrets"       //#nosec
+	HgSecretName                            = "jvm-build-hg-secrets"             //#nosec
+	SvnSecretName                           = "jvm-build-svn-secrets"            //#nosec
+	SourceArchiveSecretName                 = "jvm-build-source-archive-secrets" //#nosec
+	ScmSecretUsernameKey                    = "username"                         //#nosec
+	ScmSecretPasswordKey                    = "password"                         //#nosec
+	ScmSecretSshKey                         = "ssh-privatekey"                   //#nosec
+	ScmSecretKnownHostsKey                  = "known_hosts"                      //#nosec
+	CosignPrivateKeyKey                     = "cosign.key"                       //#nosec
+	CosignPasswordKey                       = "cosign.password"                  //#nosec
+	CosignPublicKeyKey                      = "cosign.pub"                       //#nosec
 	AWSAccessID                             = "awsaccesskey"                     //#nosec
 	AWSSecretKey                            = "awssecretkey"                     //#nosec
 	AWSProfile                              = "awsprofile"                       //#nosec
@@ -34,8 +44,29 @@ const (
 
 	HermeticBuildTypeNone     HermeticBuildType = "None"
 	HermeticBuildTypeRequired HermeticBuildType = "Required"
+
+	ScmCredentialsTypeBasicAuth ScmCredentialsType = "basic-auth"
+	ScmCredentialsTypeSsh       ScmCredentialsType = "ssh"
+
+	SignatureTypeGpg SignatureType = "gpg"
+	SignatureTypeSsh SignatureType = "ssh"
+
+	// The ConfigMap key holding the allowed signers for ssh signature verification, for gpg every key is imported
+	SignatureKeyringAllowedSignersKey = "allowed_signers"
+
+	ToolCacheGradle ToolCacheType = "gradle"
+	ToolCacheMaven  ToolCacheType = "m2"
+	ToolCacheIvy    ToolCacheType = "ivy2"
+	// The default size of the per DependencyBuild tool cache PVC
+	ToolCacheStorageDefault = "5Gi"
 )
 
+type ToolCacheType string
+
+type ScmCredentialsType string
+
+type SignatureType string
+
 type JBSConfigSpec struct {
 	EnableRebuilds bool `json:"enableRebuilds,omitempty"`
 
@@ -55,6 +86,70 @@ type JBSConfigSpec struct {
 	CacheSettings      CacheSettings              `json:"cacheSettings,omitempty"`
 	BuildSettings      BuildSettings              `json:"buildSettings,omitempty"`
 	RelocationPatterns []RelocationPatternElement `json:"relocationPatterns,omitempty"`
+
+	// The default clone options, a build recipe can override these
+	GitOptions GitCloneOptions `json:"gitOptions,omitempty"`
+	// Git URL rewrites for internal mirrors, the key is the original URL prefix and the value is the mirror URL prefix
+	GitMirrors map[string]string `json:"gitMirrors,omitempty"`
+	// Per host SCM credentials, used in addition to the jvm-build-git-secrets token
+	ScmCredentials []ScmHostCredentials `json:"scmCredentials,omitempty"`
+	// If this is set the tag or commit being built must have a valid signature, otherwise the build fails
+	SignatureVerification *SignatureVerificationPolicy `json:"signatureVerification,omitempty"`
+	// If this is set every build is run twice and the output compared, to check that it is reproducible
+	ReproducibilityCheck *ReproducibilityCheck `json:"reproducibilityCheck,omitempty"`
+	// If this is set the deployed images are signed with cosign, and images from shared registries are only reused
+	// if they have a valid signature
+	ImageSigning *ImageSigning `json:"imageSigning,omitempty"`
+	// Licenses that are not allowed in rebuilt artifacts
+	LicensePolicy *LicensePolicy `json:"licensePolicy,omitempty"`
+	// The OSV vulnerability records RebuiltArtifacts and JvmImageScan results are checked against
+	VulnerabilityDatabase *VulnerabilityDatabase `json:"vulnerabilityDatabase,omitempty"`
+	// Community artifacts that are accepted when they are found in rebuilt artifacts, such as shaded copies of small
+	// utility libraries
+	ContaminantPolicy *ContaminantPolicy `json:"contaminantPolicy,omitempty"`
+}
+
+type ImageSigning struct {
+	// The secret holding the cosign key pair, the cosign.key, cosign.password and cosign.pub entries are used
+	KeySecret string `json:"keySecret"`
+}
+
+type LicensePolicy struct {
+	// The SPDX identifiers of the denied licenses, these are matched case insensitively. An artifact that is dual
+	// licensed is only denied if all of its licenses are.
+	DeniedLicenses []string `json:"deniedLicenses,omitempty"`
+	// If this is true a build that produces artifacts with a denied license fails without deploying them, otherwise
+	// the DependencyBuild is flagged with a condition and the artifacts are still used
+	FailBuild bool `json:"failBuild,omitempty"`
+}
+
+type VulnerabilityDatabase struct {
+	// The ConfigMap holding the OSV records, every entry is a single record or a JSON array of records. The ConfigMap
+	// must have the jvmbuildservice.io/vulnerability-database label so that changes to it are seen.
+	ConfigMap string `json:"configMap,omitempty"`
+}
+
+type ContaminantPolicy struct {
+	// Allowed contaminants are recorded on the DependencyBuild, but do not stop it completing and are not rebuilt
+	AllowedContaminants []AllowedContaminant `json:"allowedContaminants,omitempty"`
+	// If this is true a DependencyBuild that is contaminated through a cycle, such as two projects that shade each
+	// other, is built again with the contaminants that lead back to it allowed instead of waiting forever
+	BreakCycles bool `json:"breakCycles,omitempty"`
+}
+
+type AllowedContaminant struct {
+	// A glob pattern matched against the GAV of the contaminant, e.g. com.google.guava:*
+	GAV string `json:"gav"`
+	// A glob pattern matched against the GAV of the artifact the contaminant was found in, if this is empty the
+	// contaminant is allowed in every artifact
+	Artifact string `json:"artifact,omitempty"`
+}
+
+type ReproducibilityCheck struct {
+	// If this is true the build fails if the output is not reproducible, and the artifacts are not tagged or used
+	Required bool `json:"required,omitempty"`
+	// If this is true the second build is not scheduled on the same node as the first build
+	AntiAffinity bool `json:"antiAffinity,omitempty"`
 }
 
 type ImageRegistrySpec struct {
@@ -94,7 +189,57 @@ type BuildSettings struct {
 	TaskLimitMemory string `json:"taskLimitMemory,omitempty"`
 	// The CPU limit for all other steps of a pipeline
 	TaskLimitCPU string `json:"taskLimitCPU,omitempty"`
+
+	// The size of the PVC backing the source workspace of a build pipeline, if this is not set an EmptyDir is used.
+	// The PVC is created from a VolumeClaimTemplate so it is removed with the PipelineRun.
+	WorkspaceStorage string `json:"workspaceStorage,omitempty"`
+	// The storage class for the workspace and tool cache PVCs, if not set the cluster default is used
+	StorageClassName string `json:"storageClassName,omitempty"`
+	// Tool caches (gradle, m2 or ivy2) that are kept in a per DependencyBuild PVC so they survive between build attempts.
+	// The PVC is removed when the DependencyBuild completes, fails or is deleted.
+	ToolCaches []ToolCacheType `json:"toolCaches,omitempty"`
+	// The size of the tool cache PVC, defaults to 5Gi
+	ToolCacheStorage string `json:"toolCacheStorage,omitempty"`
+
+	// Environment variables added to every build, build recipes can override these
+	Env []BuildEnvVar `json:"env,omitempty"`
+	// Secrets mounted into every build
+	SecretMounts []SecretMount `json:"secretMounts,omitempty"`
+	// The environment variables build recipes may set, a trailing * matches any suffix
+	AllowedRecipeEnv []string `json:"allowedRecipeEnv,omitempty"`
+	// The secrets build recipes may mount
+	AllowedRecipeSecrets []string `json:"allowedRecipeSecrets,omitempty"`
+
+	// Resources for individual pipeline steps keyed by step name, e.g. git-clone-and-settings, preprocessor,
+	// create-pre-build-image, build, verify-deploy-and-check-for-contaminates, tag, process-build-requests, run-syft
+	// or analyze-dependencies. Anything that is not set uses the default for the step.
+	StepResources map[string]StepResources `json:"stepResources,omitempty"`
 }
+
+type StepResources struct {
+	RequestMemory string `json:"requestMemory,omitempty"`
+	RequestCPU    string `json:"requestCPU,omitempty"`
+	LimitMemory   string `json:"limitMemory,omitempty"`
+	LimitCPU      string `json:"limitCPU,omitempty"`
+}
+type ScmHostCredentials struct {
+	// The host the credentials apply to, e.g. gitlab.example.com
+	Host string `json:"host"`
+	// The secret holding the credentials. A basic-auth secret uses the username and password keys (the password can be a token),
+	// an ssh secret uses the ssh-privatekey and known_hosts keys.
+	SecretName string `json:"secretName"`
+	// Either basic-auth (the default) or ssh. To use ssh with https SCM URLs add a rewrite to GitMirrors.
+	Type ScmCredentialsType `json:"type,omitempty"`
+}
+
+type SignatureVerificationPolicy struct {
+	// Either gpg or ssh
+	Type SignatureType `json:"type"`
+	// The ConfigMap holding the trusted keys. For gpg every entry is imported as a public key, for ssh the
+	// allowed_signers entry is used as the allowed signers file.
+	KeyringConfigMap string `json:"keyringConfigMap"`
+}
+
 type ImageRegistry struct {
 	Host       string `json:"host,omitempty"` // Defaults to quay.io in ImageRegistry()
 	Port       string `json:"port,omitempty"`
@@ -103,6 +248,16 @@ type ImageRegistry struct {
 	Insecure   bool   `json:"insecure,omitempty"`
 	PrependTag string `json:"prependTag,omitempty"`
 	SecretName string `json:"secretName,omitempty"`
+	// Only used for shared registries, the checks a preexisting build in the registry must pass before it is reused
+	TrustPolicy *SharedRegistryTrustPolicy `json:"trustPolicy,omitempty"`
+}
+
+type SharedRegistryTrustPolicy struct {
+	// Secrets holding a cosign.pub entry, the image must have a valid signature from each of the keys
+	SignerKeySecrets []string `json:"signerKeySecrets,omitempty"`
+	// The provenance the image must record, any of scm-uri, scm-commit, hermetic and build-id. The scm-uri and
+	// scm-commit must match the DependencyBuild, and hermetic must be true.
+	RequiredProvenance []string `json:"requiredProvenance,omitempty"`
 }
 
 type MavenDeployment struct {
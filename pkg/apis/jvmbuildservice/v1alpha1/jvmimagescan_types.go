@@ -12,11 +12,15 @@ type JvmImageScanStatus struct {
 	State   JvmImageDependenciesState `json:"state,omitempty"`
 	Message string                    `json:"message,omitempty"`
 	Results []JavaDependency          `json:"results,omitempty"`
+	// The digest of the vulnerability database the results were last checked against
+	VulnerabilityDatabaseDigest string `json:"vulnerabilityDatabaseDigest,omitempty"`
 }
 type JavaDependency struct {
 	GAV        string            `json:"gav,omitempty"`
 	Source     string            `json:"source,omitempty"`
 	Attributes map[string]string `json:"attributes,omitempty"`
+	// The known vulnerabilities that affect the dependency
+	Vulnerabilities []Vulnerability `json:"vulnerabilities,omitempty"`
 }
 
 type JvmImageDependenciesState string
@@ -9,9 +9,55 @@ type RebuiltArtifactSpec struct {
 	GAV    string `json:"gav,omitempty"`
 	Image  string `json:"image,omitempty"`
 	Digest string `json:"digest,omitempty"`
+	// The result of the source signature verification, if it was enabled for the build
+	SignatureVerification *SignatureVerificationResult `json:"signatureVerification,omitempty"`
+	// The cosign signature of the image, only present if image signing is enabled in the JBSConfig
+	ImageSignature string `json:"imageSignature,omitempty"`
 }
 
 type RebuiltArtifactStatus struct {
+	// The SLSA provenance of the artifact, only present if it was built by this service
+	Provenance *ProvenanceReference `json:"provenance,omitempty"`
+	// The CycloneDX SBOM of the build that produced the artifact, only present if it was built by this service
+	SBOM *SBOMReference `json:"sbom,omitempty"`
+	// The SPDX identifiers of the licenses detected for the artifact, licenses that could not be identified are
+	// recorded as LicenseRef- identifiers
+	Licenses []string `json:"licenses,omitempty"`
+	// The known vulnerabilities that affect the artifact
+	Vulnerabilities []Vulnerability `json:"vulnerabilities,omitempty"`
+	// The digest of the vulnerability database the artifact was last checked against
+	VulnerabilityDatabaseDigest string `json:"vulnerabilityDatabaseDigest,omitempty"`
+}
+
+// Vulnerability is a known vulnerability from the OSV database
+type Vulnerability struct {
+	// The OSV identifier
+	ID string `json:"id"`
+	// Other identifiers for the vulnerability, such as the CVE
+	Aliases  []string `json:"aliases,omitempty"`
+	Summary  string   `json:"summary,omitempty"`
+	Severity string   `json:"severity,omitempty"`
+	// The versions the vulnerability is fixed in
+	FixedVersions []string `json:"fixedVersions,omitempty"`
+}
+
+// ProvenanceReference references the in-toto SLSA provenance statement for the rebuilt artifact
+type ProvenanceReference struct {
+	// The name of the ConfigMap the statement is stored in, under the statement.json key
+	ConfigMap     string `json:"configMap,omitempty"`
+	PredicateType string `json:"predicateType,omitempty"`
+	// The sha256 digest of the statement
+	Digest string `json:"digest,omitempty"`
+}
+
+// SBOMReference references the CycloneDX SBOM for the rebuilt artifact
+type SBOMReference struct {
+	// The name of the ConfigMap the SBOM is stored in, under the sbom.json key. Large SBOMs are not stored in a ConfigMap
+	ConfigMap string `json:"configMap,omitempty"`
+	// The OCI referrer the SBOM was attached to the deployed image as, if the image could be updated
+	Referrer string `json:"referrer,omitempty"`
+	// The sha256 digest of the SBOM
+	Digest string `json:"digest,omitempty"`
 }
 
 // +genclient
@@ -4,6 +4,8 @@ import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 const (
 	DefaultRecipeDatabase = "https://github.com/redhat-appstudio/jvm-build-data"
+	// DefaultCosignImage is used to sign the deployed images if the SystemConfig does not set one
+	DefaultCosignImage = "gcr.io/projectsigstore/cosign:v2.2.0"
 )
 
 type QuotaImpl string
@@ -14,15 +16,65 @@ type SystemConfigSpec struct {
 	//DEPRECATED
 	Quota          QuotaImpl `json:"quota,omitempty"`
 	RecipeDatabase string    `json:"recipeDatabase,omitempty"`
+	// Docker config pull secrets in the controller namespace used to resolve the builder images
+	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
+	// If this is true builds use the builder image digest recorded in the status rather than the image tag, so a
+	// mutable tag cannot change the builder part way through a set of builds
+	PinBuilderImages bool `json:"pinBuilderImages,omitempty"`
+	// The cosign image used to sign the deployed images, DefaultCosignImage if this is not set. Unless it already
+	// has a digest the image is run by the digest recorded in the status.
+	CosignImage string `json:"cosignImage,omitempty"`
 }
 
 type BuilderImageInfo struct {
-	Image    string `json:"image,omitempty"`
+	Image string `json:"image,omitempty"`
+	//DEPRECATED: use Capabilities. The tools in the image in the form tool:version[;version],tool:version
 	Tag      string `json:"tag,omitempty"`
 	Priority int    `json:"priority,omitempty"`
+	// The install locations of the tools in the image. Tools that are not listed are assumed to be in the
+	// conventional location (e.g. /opt/maven/<version>).
+	ToolPaths []ToolPath `json:"toolPaths,omitempty"`
+	// The tools installed in the image. If this is not set the capabilities are read from the tag.
+	Capabilities []BuilderCapability `json:"capabilities,omitempty"`
+}
+
+// BuilderCapability describes a tool that is installed in a builder image
+type BuilderCapability struct {
+	// The tool name, e.g. jdk, maven, gradle, ant or sbt
+	Tool     string   `json:"tool,omitempty"`
+	Versions []string `json:"versions,omitempty"`
+	// The install location of the tool, {version} is replaced with each version. If this is not set the
+	// conventional location is used.
+	InstallPath string `json:"installPath,omitempty"`
+	// The architecture the tool is available for, e.g. amd64 or arm64. If this is not set the tool is available
+	// on all architectures the image supports.
+	Architecture string `json:"architecture,omitempty"`
+}
+
+// ToolPath is the install location of a specific version of a tool within a builder image
+type ToolPath struct {
+	// The tool name as used in the builder tag, e.g. jdk, maven, gradle, ant or sbt
+	Tool    string `json:"tool,omitempty"`
+	Version string `json:"version,omitempty"`
+	Path    string `json:"path,omitempty"`
 }
 
 type SystemConfigStatus struct {
+	// The validation result for each builder, keyed by builder name
+	Builders map[string]BuilderImageStatus `json:"builders,omitempty"`
+	// The digest the cosign image resolved to, it is only looked up once a JBSConfig enables image signing and is
+	// kept if a later lookup fails
+	CosignImageDigest string `json:"cosignImageDigest,omitempty"`
+}
+
+type BuilderImageStatus struct {
+	Valid bool `json:"valid"`
+	// The problems found with the builder, if any
+	Messages []string `json:"messages,omitempty"`
+	// If the image could be resolved in the registry
+	Reachable bool `json:"reachable,omitempty"`
+	// The digest the image resolved to when the SystemConfig was last reconciled
+	Digest string `json:"digest,omitempty"`
 }
 
 // +genclient
@@ -41,6 +41,38 @@ func (in *AdditionalDownload) DeepCopy() *AdditionalDownload {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllowedContaminant) DeepCopyInto(out *AllowedContaminant) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllowedContaminant.
+func (in *AllowedContaminant) DeepCopy() *AllowedContaminant {
+	if in == nil {
+		return nil
+	}
+	out := new(AllowedContaminant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllowedDifferenceCount) DeepCopyInto(out *AllowedDifferenceCount) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllowedDifferenceCount.
+func (in *AllowedDifferenceCount) DeepCopy() *AllowedDifferenceCount {
+	if in == nil {
+		return nil
+	}
+	out := new(AllowedDifferenceCount)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArtifactBuild) DeepCopyInto(out *ArtifactBuild) {
 	*out = *in
@@ -135,6 +167,32 @@ func (in *ArtifactBuildStatus) DeepCopy() *ArtifactBuildStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArtifactVerification) DeepCopyInto(out *ArtifactVerification) {
+	*out = *in
+	if in.Differences != nil {
+		in, out := &in.Differences, &out.Differences
+		*out = make([]VerificationDifferenceCount, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedDifferences != nil {
+		in, out := &in.AllowedDifferences, &out.AllowedDifferences
+		*out = make([]AllowedDifferenceCount, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactVerification.
+func (in *ArtifactVerification) DeepCopy() *ArtifactVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(ArtifactVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildAttempt) DeepCopyInto(out *BuildAttempt) {
 	*out = *in
@@ -148,6 +206,21 @@ func (in *BuildAttempt) DeepCopyInto(out *BuildAttempt) {
 		*out = new(BuildPipelineRun)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SignatureVerification != nil {
+		in, out := &in.SignatureVerification, &out.SignatureVerification
+		*out = new(SignatureVerificationResult)
+		**out = **in
+	}
+	if in.ResourceUsage != nil {
+		in, out := &in.ResourceUsage, &out.ResourceUsage
+		*out = make([]StepResourceUsage, len(*in))
+		copy(*out, *in)
+	}
+	if in.VerificationReport != nil {
+		in, out := &in.VerificationReport, &out.VerificationReport
+		*out = new(VerificationReport)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -161,6 +234,38 @@ func (in *BuildAttempt) DeepCopy() *BuildAttempt {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildEnvVar) DeepCopyInto(out *BuildEnvVar) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildEnvVar.
+func (in *BuildEnvVar) DeepCopy() *BuildEnvVar {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildEnvVar)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildPatch) DeepCopyInto(out *BuildPatch) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildPatch.
+func (in *BuildPatch) DeepCopy() *BuildPatch {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildPatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildPipelineRun) DeepCopyInto(out *BuildPipelineRun) {
 	*out = *in
@@ -238,6 +343,31 @@ func (in *BuildRecipe) DeepCopyInto(out *BuildRecipe) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.GitOptions != nil {
+		in, out := &in.GitOptions, &out.GitOptions
+		*out = new(GitCloneOptions)
+		**out = **in
+	}
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make([]BuildPatch, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]BuildEnvVar, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretMounts != nil {
+		in, out := &in.SecretMounts, &out.SecretMounts
+		*out = make([]SecretMount, len(*in))
+		copy(*out, *in)
+	}
+	if in.Reproducible != nil {
+		in, out := &in.Reproducible, &out.Reproducible
+		*out = new(ReproducibleBuildSettings)
+		**out = **in
+	}
 	return
 }
 
@@ -251,9 +381,58 @@ func (in *BuildRecipe) DeepCopy() *BuildRecipe {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildRequeue) DeepCopyInto(out *BuildRequeue) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildRequeue.
+func (in *BuildRequeue) DeepCopy() *BuildRequeue {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildRequeue)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildSettings) DeepCopyInto(out *BuildSettings) {
 	*out = *in
+	if in.ToolCaches != nil {
+		in, out := &in.ToolCaches, &out.ToolCaches
+		*out = make([]ToolCacheType, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]BuildEnvVar, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretMounts != nil {
+		in, out := &in.SecretMounts, &out.SecretMounts
+		*out = make([]SecretMount, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedRecipeEnv != nil {
+		in, out := &in.AllowedRecipeEnv, &out.AllowedRecipeEnv
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedRecipeSecrets != nil {
+		in, out := &in.AllowedRecipeSecrets, &out.AllowedRecipeSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StepResources != nil {
+		in, out := &in.StepResources, &out.StepResources
+		*out = make(map[string]StepResources, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -267,9 +446,42 @@ func (in *BuildSettings) DeepCopy() *BuildSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuilderCapability) DeepCopyInto(out *BuilderCapability) {
+	*out = *in
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuilderCapability.
+func (in *BuilderCapability) DeepCopy() *BuilderCapability {
+	if in == nil {
+		return nil
+	}
+	out := new(BuilderCapability)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuilderImageInfo) DeepCopyInto(out *BuilderImageInfo) {
 	*out = *in
+	if in.ToolPaths != nil {
+		in, out := &in.ToolPaths, &out.ToolPaths
+		*out = make([]ToolPath, len(*in))
+		copy(*out, *in)
+	}
+	if in.Capabilities != nil {
+		in, out := &in.Capabilities, &out.Capabilities
+		*out = make([]BuilderCapability, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -283,6 +495,27 @@ func (in *BuilderImageInfo) DeepCopy() *BuilderImageInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuilderImageStatus) DeepCopyInto(out *BuilderImageStatus) {
+	*out = *in
+	if in.Messages != nil {
+		in, out := &in.Messages, &out.Messages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuilderImageStatus.
+func (in *BuilderImageStatus) DeepCopy() *BuilderImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BuilderImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CacheSettings) DeepCopyInto(out *CacheSettings) {
 	*out = *in
@@ -320,6 +553,48 @@ func (in *Contaminant) DeepCopy() *Contaminant {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContaminantPolicy) DeepCopyInto(out *ContaminantPolicy) {
+	*out = *in
+	if in.AllowedContaminants != nil {
+		in, out := &in.AllowedContaminants, &out.AllowedContaminants
+		*out = make([]AllowedContaminant, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContaminantPolicy.
+func (in *ContaminantPolicy) DeepCopy() *ContaminantPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ContaminantPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContaminationNode) DeepCopyInto(out *ContaminationNode) {
+	*out = *in
+	if in.BlockedBy != nil {
+		in, out := &in.BlockedBy, &out.BlockedBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContaminationNode.
+func (in *ContaminationNode) DeepCopy() *ContaminationNode {
+	if in == nil {
+		return nil
+	}
+	out := new(ContaminationNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DependencyBuild) DeepCopyInto(out *DependencyBuild) {
 	*out = *in
@@ -415,6 +690,25 @@ func (in *DependencyBuildStatus) DeepCopyInto(out *DependencyBuildStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.AllowedContaminants != nil {
+		in, out := &in.AllowedContaminants, &out.AllowedContaminants
+		*out = make([]Contaminant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CycleAllowedContaminants != nil {
+		in, out := &in.CycleAllowedContaminants, &out.CycleAllowedContaminants
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ContaminationGraph != nil {
+		in, out := &in.ContaminationGraph, &out.ContaminationGraph
+		*out = make([]ContaminationNode, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.PotentialBuildRecipes != nil {
 		in, out := &in.PotentialBuildRecipes, &out.PotentialBuildRecipes
 		*out = make([]*BuildRecipe, len(*in))
@@ -447,6 +741,29 @@ func (in *DependencyBuildStatus) DeepCopyInto(out *DependencyBuildStatus) {
 		*out = new(PipelineResults)
 		**out = **in
 	}
+	if in.UnmatchedBuildRecipes != nil {
+		in, out := &in.UnmatchedBuildRecipes, &out.UnmatchedBuildRecipes
+		*out = make([]*BuildRecipe, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(BuildRecipe)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.Requeues != nil {
+		in, out := &in.Requeues, &out.Requeues
+		*out = make([]BuildRequeue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReproducibilityDifferences != nil {
+		in, out := &in.ReproducibilityDifferences, &out.ReproducibilityDifferences
+		*out = make([]ReproducibilityDifference, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -460,6 +777,22 @@ func (in *DependencyBuildStatus) DeepCopy() *DependencyBuildStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitCloneOptions) DeepCopyInto(out *GitCloneOptions) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitCloneOptions.
+func (in *GitCloneOptions) DeepCopy() *GitCloneOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(GitCloneOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitSourceArchive) DeepCopyInto(out *GitSourceArchive) {
 	*out = *in
@@ -479,6 +812,11 @@ func (in *GitSourceArchive) DeepCopy() *GitSourceArchive {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageRegistry) DeepCopyInto(out *ImageRegistry) {
 	*out = *in
+	if in.TrustPolicy != nil {
+		in, out := &in.TrustPolicy, &out.TrustPolicy
+		*out = new(SharedRegistryTrustPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -495,7 +833,7 @@ func (in *ImageRegistry) DeepCopy() *ImageRegistry {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageRegistrySpec) DeepCopyInto(out *ImageRegistrySpec) {
 	*out = *in
-	out.ImageRegistry = in.ImageRegistry
+	in.ImageRegistry.DeepCopyInto(&out.ImageRegistry)
 	if in.Private != nil {
 		in, out := &in.Private, &out.Private
 		*out = new(bool)
@@ -514,6 +852,22 @@ func (in *ImageRegistrySpec) DeepCopy() *ImageRegistrySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageSigning) DeepCopyInto(out *ImageSigning) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageSigning.
+func (in *ImageSigning) DeepCopy() *ImageSigning {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageSigning)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JBSConfig) DeepCopyInto(out *JBSConfig) {
 	*out = *in
@@ -593,13 +947,15 @@ func (in *JBSConfigSpec) DeepCopyInto(out *JBSConfigSpec) {
 	if in.SharedRegistries != nil {
 		in, out := &in.SharedRegistries, &out.SharedRegistries
 		*out = make([]ImageRegistry, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	in.Registry.DeepCopyInto(&out.Registry)
 	out.MavenDeployment = in.MavenDeployment
 	out.GitSourceArchive = in.GitSourceArchive
 	out.CacheSettings = in.CacheSettings
-	out.BuildSettings = in.BuildSettings
+	in.BuildSettings.DeepCopyInto(&out.BuildSettings)
 	if in.RelocationPatterns != nil {
 		in, out := &in.RelocationPatterns, &out.RelocationPatterns
 		*out = make([]RelocationPatternElement, len(*in))
@@ -607,6 +963,49 @@ func (in *JBSConfigSpec) DeepCopyInto(out *JBSConfigSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	out.GitOptions = in.GitOptions
+	if in.GitMirrors != nil {
+		in, out := &in.GitMirrors, &out.GitMirrors
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ScmCredentials != nil {
+		in, out := &in.ScmCredentials, &out.ScmCredentials
+		*out = make([]ScmHostCredentials, len(*in))
+		copy(*out, *in)
+	}
+	if in.SignatureVerification != nil {
+		in, out := &in.SignatureVerification, &out.SignatureVerification
+		*out = new(SignatureVerificationPolicy)
+		**out = **in
+	}
+	if in.ReproducibilityCheck != nil {
+		in, out := &in.ReproducibilityCheck, &out.ReproducibilityCheck
+		*out = new(ReproducibilityCheck)
+		**out = **in
+	}
+	if in.ImageSigning != nil {
+		in, out := &in.ImageSigning, &out.ImageSigning
+		*out = new(ImageSigning)
+		**out = **in
+	}
+	if in.LicensePolicy != nil {
+		in, out := &in.LicensePolicy, &out.LicensePolicy
+		*out = new(LicensePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VulnerabilityDatabase != nil {
+		in, out := &in.VulnerabilityDatabase, &out.VulnerabilityDatabase
+		*out = new(VulnerabilityDatabase)
+		**out = **in
+	}
+	if in.ContaminantPolicy != nil {
+		in, out := &in.ContaminantPolicy, &out.ContaminantPolicy
+		*out = new(ContaminantPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -626,7 +1025,7 @@ func (in *JBSConfigStatus) DeepCopyInto(out *JBSConfigStatus) {
 	if in.ImageRegistry != nil {
 		in, out := &in.ImageRegistry, &out.ImageRegistry
 		*out = new(ImageRegistry)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
@@ -651,9 +1050,16 @@ func (in *JavaDependency) DeepCopyInto(out *JavaDependency) {
 			(*out)[key] = val
 		}
 	}
-	return
-}
-
+	if in.Vulnerabilities != nil {
+		in, out := &in.Vulnerabilities, &out.Vulnerabilities
+		*out = make([]Vulnerability, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JavaDependency.
 func (in *JavaDependency) DeepCopy() *JavaDependency {
 	if in == nil {
@@ -764,6 +1170,27 @@ func (in *JvmImageScanStatus) DeepCopy() *JvmImageScanStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LicensePolicy) DeepCopyInto(out *LicensePolicy) {
+	*out = *in
+	if in.DeniedLicenses != nil {
+		in, out := &in.DeniedLicenses, &out.DeniedLicenses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicensePolicy.
+func (in *LicensePolicy) DeepCopy() *LicensePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(LicensePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MavenDeployment) DeepCopyInto(out *MavenDeployment) {
 	*out = *in
@@ -829,13 +1256,29 @@ func (in *PipelineResults) DeepCopy() *PipelineResults {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvenanceReference) DeepCopyInto(out *ProvenanceReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvenanceReference.
+func (in *ProvenanceReference) DeepCopy() *ProvenanceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvenanceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RebuiltArtifact) DeepCopyInto(out *RebuiltArtifact) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -893,6 +1336,11 @@ func (in *RebuiltArtifactList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RebuiltArtifactSpec) DeepCopyInto(out *RebuiltArtifactSpec) {
 	*out = *in
+	if in.SignatureVerification != nil {
+		in, out := &in.SignatureVerification, &out.SignatureVerification
+		*out = new(SignatureVerificationResult)
+		**out = **in
+	}
 	return
 }
 
@@ -909,6 +1357,28 @@ func (in *RebuiltArtifactSpec) DeepCopy() *RebuiltArtifactSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RebuiltArtifactStatus) DeepCopyInto(out *RebuiltArtifactStatus) {
 	*out = *in
+	if in.Provenance != nil {
+		in, out := &in.Provenance, &out.Provenance
+		*out = new(ProvenanceReference)
+		**out = **in
+	}
+	if in.SBOM != nil {
+		in, out := &in.SBOM, &out.SBOM
+		*out = new(SBOMReference)
+		**out = **in
+	}
+	if in.Licenses != nil {
+		in, out := &in.Licenses, &out.Licenses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Vulnerabilities != nil {
+		in, out := &in.Vulnerabilities, &out.Vulnerabilities
+		*out = make([]Vulnerability, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -960,6 +1430,70 @@ func (in *RelocationPatternElement) DeepCopy() *RelocationPatternElement {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReproducibilityCheck) DeepCopyInto(out *ReproducibilityCheck) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReproducibilityCheck.
+func (in *ReproducibilityCheck) DeepCopy() *ReproducibilityCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(ReproducibilityCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReproducibilityDifference) DeepCopyInto(out *ReproducibilityDifference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReproducibilityDifference.
+func (in *ReproducibilityDifference) DeepCopy() *ReproducibilityDifference {
+	if in == nil {
+		return nil
+	}
+	out := new(ReproducibilityDifference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReproducibleBuildSettings) DeepCopyInto(out *ReproducibleBuildSettings) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReproducibleBuildSettings.
+func (in *ReproducibleBuildSettings) DeepCopy() *ReproducibleBuildSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(ReproducibleBuildSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SBOMReference) DeepCopyInto(out *SBOMReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SBOMReference.
+func (in *SBOMReference) DeepCopy() *SBOMReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SBOMReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SCMInfo) DeepCopyInto(out *SCMInfo) {
 	*out = *in
@@ -976,13 +1510,135 @@ func (in *SCMInfo) DeepCopy() *SCMInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScmHostCredentials) DeepCopyInto(out *ScmHostCredentials) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScmHostCredentials.
+func (in *ScmHostCredentials) DeepCopy() *ScmHostCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(ScmHostCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretMount) DeepCopyInto(out *SecretMount) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretMount.
+func (in *SecretMount) DeepCopy() *SecretMount {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretMount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedRegistryTrustPolicy) DeepCopyInto(out *SharedRegistryTrustPolicy) {
+	*out = *in
+	if in.SignerKeySecrets != nil {
+		in, out := &in.SignerKeySecrets, &out.SignerKeySecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequiredProvenance != nil {
+		in, out := &in.RequiredProvenance, &out.RequiredProvenance
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedRegistryTrustPolicy.
+func (in *SharedRegistryTrustPolicy) DeepCopy() *SharedRegistryTrustPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedRegistryTrustPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SignatureVerificationPolicy) DeepCopyInto(out *SignatureVerificationPolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SignatureVerificationPolicy.
+func (in *SignatureVerificationPolicy) DeepCopy() *SignatureVerificationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SignatureVerificationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SignatureVerificationResult) DeepCopyInto(out *SignatureVerificationResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SignatureVerificationResult.
+func (in *SignatureVerificationResult) DeepCopy() *SignatureVerificationResult {
+	if in == nil {
+		return nil
+	}
+	out := new(SignatureVerificationResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepResourceUsage) DeepCopyInto(out *StepResourceUsage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepResourceUsage.
+func (in *StepResourceUsage) DeepCopy() *StepResourceUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(StepResourceUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepResources) DeepCopyInto(out *StepResources) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepResources.
+func (in *StepResources) DeepCopy() *StepResources {
+	if in == nil {
+		return nil
+	}
+	out := new(StepResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SystemConfig) DeepCopyInto(out *SystemConfig) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -1044,9 +1700,14 @@ func (in *SystemConfigSpec) DeepCopyInto(out *SystemConfigSpec) {
 		in, out := &in.Builders, &out.Builders
 		*out = make(map[string]BuilderImageInfo, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1063,6 +1724,13 @@ func (in *SystemConfigSpec) DeepCopy() *SystemConfigSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SystemConfigStatus) DeepCopyInto(out *SystemConfigStatus) {
 	*out = *in
+	if in.Builders != nil {
+		in, out := &in.Builders, &out.Builders
+		*out = make(map[string]BuilderImageStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	return
 }
 
@@ -1075,3 +1743,100 @@ func (in *SystemConfigStatus) DeepCopy() *SystemConfigStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ToolPath) DeepCopyInto(out *ToolPath) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ToolPath.
+func (in *ToolPath) DeepCopy() *ToolPath {
+	if in == nil {
+		return nil
+	}
+	out := new(ToolPath)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerificationDifferenceCount) DeepCopyInto(out *VerificationDifferenceCount) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerificationDifferenceCount.
+func (in *VerificationDifferenceCount) DeepCopy() *VerificationDifferenceCount {
+	if in == nil {
+		return nil
+	}
+	out := new(VerificationDifferenceCount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerificationReport) DeepCopyInto(out *VerificationReport) {
+	*out = *in
+	if in.Artifacts != nil {
+		in, out := &in.Artifacts, &out.Artifacts
+		*out = make([]ArtifactVerification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerificationReport.
+func (in *VerificationReport) DeepCopy() *VerificationReport {
+	if in == nil {
+		return nil
+	}
+	out := new(VerificationReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Vulnerability) DeepCopyInto(out *Vulnerability) {
+	*out = *in
+	if in.Aliases != nil {
+		in, out := &in.Aliases, &out.Aliases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FixedVersions != nil {
+		in, out := &in.FixedVersions, &out.FixedVersions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Vulnerability.
+func (in *Vulnerability) DeepCopy() *Vulnerability {
+	if in == nil {
+		return nil
+	}
+	out := new(Vulnerability)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VulnerabilityDatabase) DeepCopyInto(out *VulnerabilityDatabase) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VulnerabilityDatabase.
+func (in *VulnerabilityDatabase) DeepCopy() *VulnerabilityDatabase {
+	if in == nil {
+		return nil
+	}
+	out := new(VulnerabilityDatabase)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/dependencybuild"
 	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/jbsconfig"
 	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/systemconfig"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/vulnerability"
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -119,6 +120,13 @@ func NewManager(cfg *rest.Config, options ctrl.Options) (ctrl.Manager, error) {
 	}
 	cachePods = cachePods.Add(*cacheRequirement)
 	cacheSelector := cache.ObjectSelector{Label: cachePods}
+	//and only the config maps that hold a vulnerability database
+	vulnerabilityDatabases := labels.NewSelector()
+	databaseRequirement, lerr := labels.NewRequirement(vulnerability.VulnerabilityDatabaseLabel, selection.Exists, nil)
+	if lerr != nil {
+		return nil, lerr
+	}
+	vulnerabilityDatabases = vulnerabilityDatabases.Add(*databaseRequirement)
 	var logReaderParams *cli.TektonParams
 	if util.S3Enabled {
 		//if we are synching to S3 we need init the log reader
@@ -137,6 +145,7 @@ func NewManager(cfg *rest.Config, options ctrl.Options) (ctrl.Manager, error) {
 			&v1alpha1.JvmImageScan{}:    {},
 			&v1alpha1.RebuiltArtifact{}: {},
 			&v1.Pod{}:                   cacheSelector,
+			&v1.ConfigMap{}:             {Label: vulnerabilityDatabases},
 		}})
 
 	mgr, err := ctrl.NewManager(cfg, options)
@@ -164,6 +173,10 @@ func NewManager(cfg *rest.Config, options ctrl.Options) (ctrl.Manager, error) {
 	if err := jvmimagescan.SetupNewReconcilerWithManager(mgr); err != nil {
 		return nil, err
 	}
+
+	if err := vulnerability.SetupNewReconcilerWithManager(mgr, util.VulnerabilityDatabasePath); err != nil {
+		return nil, err
+	}
 	metrics.InitPrometheus(mgr.GetClient())
 	return mgr, nil
 }
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"context"
+	"strings"
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -12,17 +13,25 @@ import (
 )
 
 const (
-	StateLabel                 string = "state"
-	ArtifactBuildTotalMetric   string = "stonesoup_jvmbuildservice_artifactbuilds_total_by_state_count"
-	DependencyBuildTotalMetric string = "stonesoup_jvmbuildservice_dependencybuilds_total_by_state_count"
+	StateLabel                           string = "state"
+	ArtifactBuildTotalMetric             string = "stonesoup_jvmbuildservice_artifactbuilds_total_by_state_count"
+	DependencyBuildTotalMetric           string = "stonesoup_jvmbuildservice_dependencybuilds_total_by_state_count"
+	SeverityLabel                        string = "severity"
+	RebuiltArtifactVulnerabilitiesMetric string = "stonesoup_jvmbuildservice_rebuiltartifact_vulnerabilities_total_by_severity_count"
+	JvmImageScanVulnerabilitiesMetric    string = "stonesoup_jvmbuildservice_jvmimagescan_vulnerabilities_total_by_severity_count"
+	// the severity used when the vulnerability record does not have a recognised severity level
+	UnknownSeverity string = "UNKNOWN"
 )
 
 var (
-	artifactBuildDesc   *prometheus.Desc
-	dependencyBuildDesc *prometheus.Desc
-	registered          = false
-	sc                  buildContCollector
-	regLock             = sync.Mutex{}
+	artifactBuildDesc                *prometheus.Desc
+	dependencyBuildDesc              *prometheus.Desc
+	rebuiltArtifactVulnerabilityDesc *prometheus.Desc
+	jvmImageScanVulnerabilityDesc    *prometheus.Desc
+	severities                       = []string{"CRITICAL", "HIGH", "MODERATE", "LOW", UnknownSeverity}
+	registered                       = false
+	sc                               buildContCollector
+	regLock                          = sync.Mutex{}
 )
 
 func InitPrometheus(client client.Client) {
@@ -44,6 +53,14 @@ func InitPrometheus(client client.Client) {
 		"Number of total ArtifactBuilds by state.",
 		labels,
 		nil)
+	rebuiltArtifactVulnerabilityDesc = prometheus.NewDesc(RebuiltArtifactVulnerabilitiesMetric,
+		"Number of known vulnerabilities affecting RebuiltArtifacts by severity.",
+		[]string{SeverityLabel},
+		nil)
+	jvmImageScanVulnerabilityDesc = prometheus.NewDesc(JvmImageScanVulnerabilitiesMetric,
+		"Number of known vulnerabilities affecting the dependencies found by JvmImageScans by severity.",
+		[]string{SeverityLabel},
+		nil)
 
 	//TODO based on our openshift builds experience, we have talked about the notion of tracking adoption
 	// of various stonesoup features (i.e. product mgmt is curious how much has feature X been used for the life of this cluster),
@@ -67,6 +84,8 @@ type buildContCollector struct {
 func (sc *buildContCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- artifactBuildDesc
 	ch <- dependencyBuildDesc
+	ch <- rebuiltArtifactVulnerabilityDesc
+	ch <- jvmImageScanVulnerabilityDesc
 }
 
 func (sc *buildContCollector) Collect(ch chan<- prometheus.Metric) {
@@ -124,4 +143,57 @@ func (sc *buildContCollector) Collect(ch chan<- prometheus.Metric) {
 	for k, v := range byState {
 		ch <- prometheus.MustNewConstMetric(dependencyBuildDesc, prometheus.GaugeValue, float64(v), k)
 	}
+
+	ras := &v1alpha1.RebuiltArtifactList{}
+	err = sc.client.List(context.Background(), ras)
+	if err != nil {
+		//TODO add log / event
+		return
+	}
+	bySeverity := vulnerabilitySeverities()
+	for _, i := range ras.Items {
+		countSeverities(bySeverity, i.Status.Vulnerabilities)
+	}
+	for k, v := range bySeverity {
+		ch <- prometheus.MustNewConstMetric(rebuiltArtifactVulnerabilityDesc, prometheus.GaugeValue, float64(v), k)
+	}
+
+	scans := &v1alpha1.JvmImageScanList{}
+	err = sc.client.List(context.Background(), scans)
+	if err != nil {
+		//TODO add log / event
+		return
+	}
+	bySeverity = vulnerabilitySeverities()
+	for _, i := range scans.Items {
+		for _, dep := range i.Status.Results {
+			countSeverities(bySeverity, dep.Vulnerabilities)
+		}
+	}
+	for k, v := range bySeverity {
+		ch <- prometheus.MustNewConstMetric(jvmImageScanVulnerabilityDesc, prometheus.GaugeValue, float64(v), k)
+	}
+}
+
+func vulnerabilitySeverities() map[string]int {
+	ret := map[string]int{}
+	for _, i := range severities {
+		ret[i] = 0
+	}
+	return ret
+}
+
+// countSeverities adds the vulnerabilities to the counts, severities that are not one of the known levels (such as
+// a CVSS vector) are counted as unknown so the number of label values stays bounded
+func countSeverities(bySeverity map[string]int, vulnerabilities []v1alpha1.Vulnerability) {
+	for _, v := range vulnerabilities {
+		severity := strings.ToUpper(v.Severity)
+		if severity == "MEDIUM" {
+			severity = "MODERATE"
+		}
+		if _, ok := bySeverity[severity]; !ok {
+			severity = UnknownSeverity
+		}
+		bySeverity[severity]++
+	}
 }
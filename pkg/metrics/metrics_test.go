@@ -21,12 +21,14 @@ import (
 // so we have cover our various "scenarios" under one test method
 
 func gatherMetrics(g *WithT) []*pmodel.Metric {
+	return gatherNamedMetrics(g, ArtifactBuildTotalMetric)
+}
+
+func gatherNamedMetrics(g *WithT, name string) []*pmodel.Metric {
 	metrics, err := crmetrics.Registry.Gather()
 	g.Expect(err).NotTo(HaveOccurred())
 	for _, metricFamily := range metrics {
-		switch metricFamily.GetName() {
-		case ArtifactBuildTotalMetric:
-			// call
+		if metricFamily.GetName() == name {
 			return metricFamily.GetMetric()
 		}
 	}
@@ -67,4 +69,32 @@ func TestMetrics(t *testing.T) {
 			g.Expect(m.GetGauge().GetValue()).Should(Equal(0.0))
 		}
 	}
+
+	ra := v1alpha1.RebuiltArtifact{
+		Spec: v1alpha1.RebuiltArtifactSpec{
+			GAV: "com.test:test:1.0",
+		},
+		ObjectMeta: controllerruntime.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Status: v1alpha1.RebuiltArtifactStatus{Vulnerabilities: []v1alpha1.Vulnerability{
+			{ID: "GHSA-1", Severity: "HIGH"},
+			{ID: "GHSA-2", Severity: "high"},
+			{ID: "GHSA-3", Severity: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+		}},
+	}
+	g.Expect(client.Create(context.TODO(), &ra)).Should(Succeed())
+	metric = gatherNamedMetrics(g, RebuiltArtifactVulnerabilitiesMetric)
+	g.Expect(len(metric)).Should(Equal(5))
+	for _, m := range metric {
+		switch *m.GetLabel()[0].Value {
+		case "HIGH":
+			g.Expect(m.GetGauge().GetValue()).Should(Equal(2.0))
+		case UnknownSeverity:
+			g.Expect(m.GetGauge().GetValue()).Should(Equal(1.0))
+		default:
+			g.Expect(m.GetGauge().GetValue()).Should(Equal(0.0))
+		}
+	}
 }
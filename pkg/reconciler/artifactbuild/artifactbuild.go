@@ -35,6 +35,8 @@ const (
 	DependencyBuildContaminatedByAnnotation = "jvmbuildservice.io/contaminated-"
 	DependencyBuildIdLabel                  = "jvmbuildservice.io/dependencybuild-id"
 	PipelineRunLabel                        = "jvmbuildservice.io/pipelinerun"
+	// ScmUrlLabel holds a hash of the SCM URL of a dependency build, so builds of the same repository can be listed
+	ScmUrlLabel = "jvmbuildservice.io/scm-url"
 
 	PipelineResultScmUrl      = "scm-url"
 	PipelineResultScmTag      = "scm-tag"
@@ -48,10 +50,15 @@ const (
 	BuildTaskName                           = "build"
 	HermeticBuildTaskName                   = "hermetic-build"
 	TagTaskName                             = "tag"
+	SignTaskName                            = "sign"
+	ReproducibilityTaskName                 = "reproducibility"
 	PipelineResultJavaCommunityDependencies = "JAVA_COMMUNITY_DEPENDENCIES"
 	PipelineResultContaminants              = "CONTAMINANTS"
 	PipelineResultDeployedResources         = "DEPLOYED_RESOURCES"
 	PipelineResultVerificationResult        = "VERIFICATION_RESULTS"
+	PipelineResultVerificationReport        = "VERIFICATION_REPORT"
+	PipelineResultSbom                      = "SBOM"
+	PipelineResultLicenses                  = "LICENSES"
 	PipelineResultPassedVerification        = "PASSED_VERIFICATION" //#nosec
 	PipelineResultHermeticBuildImage        = "HERMETIC_BUILD_IMAGE"
 	PipelineResultGavs                      = "GAVS"
@@ -312,6 +319,11 @@ func (r *ReconcileArtifactBuild) handleStateDiscovering(ctx context.Context, log
 				}
 				db.Annotations[RebuiltAnnotation] = "true"
 			}
+			//builds created before the label was added
+			if db.Labels == nil {
+				db.Labels = map[string]string{}
+			}
+			db.Labels[ScmUrlLabel] = util.HashString(db.Spec.ScmInfo.SCMURL)
 			if err := r.client.Update(ctx, db); err != nil {
 				return err
 			}
@@ -334,6 +346,7 @@ func (r *ReconcileArtifactBuild) handleStateDiscovering(ctx context.Context, log
 		db.Namespace = abr.Namespace
 		//TODO: do we in fact need to put depId through GenerateName sanitation algorithm for the name? label value restrictions are more stringent than obj name
 		db.Name = depId
+		db.Labels = map[string]string{ScmUrlLabel: util.HashString(abr.Status.SCMInfo.SCMURL)}
 		if err := controllerutil.SetOwnerReference(abr, db, r.scheme); err != nil {
 			return err
 		}
@@ -396,6 +409,7 @@ func (r *ReconcileArtifactBuild) handleStateComplete(ctx context.Context, log lo
 				//kick off the build again
 				log.Info("Contamination resolved, moving to state new", "dependencybuild", db.Name+"-"+db.Spec.ScmInfo.SCMURL+"-"+db.Spec.ScmInfo.Tag)
 				db.Status.State = v1alpha1.DependencyBuildStateNew
+				db.Status.ClearContaminationGraph()
 			}
 			if err := r.client.Status().Update(ctx, &db); err != nil {
 				return err
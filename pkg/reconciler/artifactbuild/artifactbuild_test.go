@@ -127,6 +127,9 @@ func TestStateDiscovering(t *testing.T) {
 		depId := util.HashString(abr.Status.SCMInfo.SCMURL + abr.Status.SCMInfo.Tag + abr.Status.SCMInfo.Path)
 		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: depId}}))
 		fullValidation(client, g)
+		db := v1alpha1.DependencyBuild{}
+		g.Expect(client.Get(ctx, types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: depId}, &db)).Should(Succeed())
+		g.Expect(db.Labels[ScmUrlLabel]).Should(Equal(util.HashString("goo")))
 	})
 
 	t.Run("DependencyBuild already exists for ABR", func(t *testing.T) {
@@ -241,6 +244,33 @@ func TestStateBuilding(t *testing.T) {
 		abr = getABR(client, g)
 		g.Expect(abr.Status.State).Should(Equal(v1alpha1.ArtifactBuildStateComplete))
 	})
+	t.Run("Completed build with allowed contaminants", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		setup()
+		abr := getABR(client, g)
+		abr.Spec.GAV = "com.foo:gax:2.0"
+		g.Expect(client.Update(ctx, abr)).Should(Succeed())
+		depId := util.HashString(abr.Status.SCMInfo.SCMURL + abr.Status.SCMInfo.Tag + abr.Status.SCMInfo.Path)
+		db := &v1alpha1.DependencyBuild{
+			TypeMeta: metav1.TypeMeta{},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      depId,
+				Namespace: metav1.NamespaceDefault,
+				Labels:    map[string]string{DependencyBuildIdLabel: util.HashString(""), util.StatusLabel: util.StatusSucceeded},
+			},
+			Spec: v1alpha1.DependencyBuildSpec{},
+			Status: v1alpha1.DependencyBuildStatus{
+				State:               v1alpha1.DependencyBuildStateComplete,
+				DeployedArtifacts:   []string{abr.Spec.GAV},
+				AllowedContaminants: []v1alpha1.Contaminant{{GAV: "com.test:test:1.0", ContaminatedArtifacts: []string{abr.Spec.GAV}}},
+			},
+		}
+		g.Expect(controllerutil.SetOwnerReference(abr, db, reconciler.scheme))
+		g.Expect(client.Create(ctx, db)).Should(Succeed())
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: "test"}}))
+		abr = getABR(client, g)
+		g.Expect(abr.Status.State).Should(Equal(v1alpha1.ArtifactBuildStateComplete))
+	})
 	t.Run("Failed build that is reset", func(t *testing.T) {
 		g := NewGomegaWithT(t)
 		setup()
@@ -356,8 +386,16 @@ func TestStateCompleteFixingContamination(t *testing.T) {
 				Namespace: metav1.NamespaceDefault,
 				Labels:    map[string]string{DependencyBuildIdLabel: util.HashString("")},
 			},
-			Spec:   v1alpha1.DependencyBuildSpec{},
-			Status: v1alpha1.DependencyBuildStatus{State: v1alpha1.DependencyBuildStateContaminated, Contaminants: []v1alpha1.Contaminant{{GAV: "com.test:test:1.0", ContaminatedArtifacts: []string{"a:b:1"}}}},
+			Spec: v1alpha1.DependencyBuildSpec{},
+			Status: v1alpha1.DependencyBuildStatus{
+				State:              v1alpha1.DependencyBuildStateContaminated,
+				Contaminants:       []v1alpha1.Contaminant{{GAV: "com.test:test:1.0", ContaminatedArtifacts: []string{"a:b:1"}}},
+				ContaminationGraph: []v1alpha1.ContaminationNode{{GAV: "com.test:test:1.0", ArtifactBuild: "test", ArtifactBuildState: v1alpha1.ArtifactBuildStateBuilding}},
+				Conditions: []metav1.Condition{
+					{Type: v1alpha1.DependencyBuildConditionContaminationCycle, Status: metav1.ConditionFalse, Reason: "NoCycle"},
+					{Type: v1alpha1.DependencyBuildConditionContaminationResolvable, Status: metav1.ConditionTrue, Reason: "ContaminantsBuilding"},
+				},
+			},
 		}
 		client, reconciler = setupClientAndReconciler(abr, contaiminated)
 	}
@@ -366,7 +404,10 @@ func TestStateCompleteFixingContamination(t *testing.T) {
 		setup()
 		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: "test"}}))
 		db := v1alpha1.DependencyBuild{}
-		g.Expect(client.Get(ctx, types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: contaminatedName}, &db))
+		g.Expect(client.Get(ctx, types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: contaminatedName}, &db)).Should(Succeed())
 		g.Expect(db.Status.Contaminants).Should(BeEmpty())
+		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateNew))
+		g.Expect(db.Status.ContaminationGraph).Should(BeEmpty())
+		g.Expect(db.Status.Conditions).Should(BeEmpty())
 	})
 }
@@ -1,25 +1,31 @@
 package dependencybuild
 
 import (
+	"crypto/sha256"
 	_ "embed"
 	"encoding/base64"
 	"fmt"
 	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	v1alpha12 "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
 	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/systemconfig"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/util"
 	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/strings/slices"
 )
 
 const (
 	WorkspaceBuildSettings      = "build-settings"
 	WorkspaceSource             = "source"
 	WorkspaceTls                = "tls"
+	WorkspaceToolCache          = "tool-cache"
+	WorkspaceReproducibility    = "reproducibility-source"
 	OriginalContentPath         = "/original-content"
 	MavenArtifactsPath          = "/maven-artifacts"
 	PreBuildImageDigest         = "PRE_BUILD_IMAGE_DIGEST"
@@ -66,8 +72,21 @@ func createPipelineSpec(tool string, commitTime int64, jbsConfig *v1alpha12.JBSC
 	verifyBuiltArtifactsArgs := verifyParameters(jbsConfig, recipe)
 
 	preBuildImageArgs, deployArgs, hermeticDeployArgs, tagArgs, createHermeticImageArgs := imageRegistryCommands(imageId, recipe, db, jbsConfig, hermeticBuildRequired, buildId)
-	gitArgs := gitArgs(db, recipe)
-	install := additionalPackages(recipe)
+	scm, err := scmProviderForType(db.Spec.ScmInfo.SCMType)
+	if err != nil {
+		return nil, "", err
+	}
+	gitArgs := scm.checkoutScript(jbsConfig, db, recipe, "$(workspaces."+WorkspaceSource+".path)/workspace")
+	//problems with the recipe are collected so the build can be failed with all of them
+	var invalid []string
+	patches, err := patchScript(recipe, "$(workspaces."+WorkspaceSource+".path)/workspace")
+	if err != nil {
+		invalid = append(invalid, err.Error())
+	}
+	install, err := additionalPackages(recipe)
+	if err != nil {
+		invalid = append(invalid, err.Error())
+	}
 
 	preprocessorArgs := []string{
 		"maven-prepare",
@@ -75,20 +94,35 @@ func createPipelineSpec(tool string, commitTime int64, jbsConfig *v1alpha12.JBSC
 		"$(params.CACHE_URL)",
 		"$(workspaces." + WorkspaceSource + ".path)/workspace",
 	}
+	toolPaths := builderToolPaths(systemConfig, recipe.Image)
 	toolEnv := []v1.EnvVar{}
 	if recipe.ToolVersions["maven"] != "" {
-		toolEnv = append(toolEnv, v1.EnvVar{Name: "MAVEN_HOME", Value: "/opt/maven/" + recipe.ToolVersions["maven"]})
+		toolEnv = append(toolEnv, v1.EnvVar{Name: "MAVEN_HOME", Value: toolHome(toolPaths, "maven", recipe.ToolVersions["maven"])})
 	}
 	if recipe.ToolVersions["gradle"] != "" {
-		toolEnv = append(toolEnv, v1.EnvVar{Name: "GRADLE_HOME", Value: "/opt/gradle/" + recipe.ToolVersions["gradle"]})
+		toolEnv = append(toolEnv, v1.EnvVar{Name: "GRADLE_HOME", Value: toolHome(toolPaths, "gradle", recipe.ToolVersions["gradle"])})
 	}
 	if recipe.ToolVersions["ant"] != "" {
-		toolEnv = append(toolEnv, v1.EnvVar{Name: "ANT_HOME", Value: "/opt/ant/" + recipe.ToolVersions["ant"]})
+		toolEnv = append(toolEnv, v1.EnvVar{Name: "ANT_HOME", Value: toolHome(toolPaths, "ant", recipe.ToolVersions["ant"])})
 	}
 	if recipe.ToolVersions["sbt"] != "" {
-		toolEnv = append(toolEnv, v1.EnvVar{Name: "SBT_DIST", Value: "/opt/sbt/" + recipe.ToolVersions["sbt"]})
+		toolEnv = append(toolEnv, v1.EnvVar{Name: "SBT_DIST", Value: toolHome(toolPaths, "sbt", recipe.ToolVersions["sbt"])})
 	}
 	toolEnv = append(toolEnv, v1.EnvVar{Name: "TOOL_VERSION", Value: recipe.ToolVersion})
+	//the recipe environment is added after this, so it can still override these if upstream needs it
+	reproducibleEnv, umask, err := reproducibleBuildEnvironment(recipe, commitTime)
+	if err != nil {
+		invalid = append(invalid, err.Error())
+	}
+	toolEnv = append(toolEnv, reproducibleEnv...)
+	buildEnv, secretVolumes, secretVolumeMounts, err := buildEnvironment(jbsConfig, recipe)
+	if err != nil {
+		invalid = append(invalid, err.Error())
+	}
+	toolEnv = append(toolEnv, buildEnv...)
+	if len(invalid) > 0 {
+		return nil, "", &invalidRecipeError{problems: invalid}
+	}
 
 	additionalMemory := recipe.AdditionalMemory
 	if systemConfig.Spec.MaxAdditionalMemory > 0 && additionalMemory > systemConfig.Spec.MaxAdditionalMemory {
@@ -96,10 +130,11 @@ func createPipelineSpec(tool string, commitTime int64, jbsConfig *v1alpha12.JBSC
 	}
 	var buildToolSection string
 	trueBool := true
+	jdks := jdkToolchains(toolPaths, recipe.JavaVersion)
 	if tool == "maven" {
-		buildToolSection = mavenSettings + "\n" + mavenBuild
+		buildToolSection = mavenSettings + "\n" + strings.ReplaceAll(mavenBuild, "{{TOOLCHAINS}}", mavenToolchains(jdks))
 	} else if tool == "gradle" {
-		buildToolSection = gradleBuild
+		buildToolSection = strings.ReplaceAll(gradleBuild, "{{JAVA_INSTALLATIONS}}", gradleJavaInstallations(jdks))
 		preprocessorArgs[0] = "gradle-prepare"
 	} else if tool == "sbt" {
 		buildToolSection = sbtBuild
@@ -127,6 +162,8 @@ func createPipelineSpec(tool string, commitTime int64, jbsConfig *v1alpha12.JBSC
 		}
 	}
 	build = strings.ReplaceAll(build, "{{BUILD}}", buildToolSection)
+	build = strings.ReplaceAll(build, "{{TOOL_CACHES}}", toolCacheScript(jbsConfig))
+	build = strings.ReplaceAll(build, "{{UMASK}}", umask)
 	build = strings.ReplaceAll(build, "{{INSTALL_PACKAGE_SCRIPT}}", install)
 	build = strings.ReplaceAll(build, "{{PRE_BUILD_SCRIPT}}", recipe.PreBuildScript)
 	build = strings.ReplaceAll(build, "{{POST_BUILD_SCRIPT}}", recipe.PostBuildScript)
@@ -134,19 +171,15 @@ func createPipelineSpec(tool string, commitTime int64, jbsConfig *v1alpha12.JBSC
 	if jbsConfig.Spec.CacheSettings.DisableTLS {
 		cacheUrl = "http://jvm-build-workspace-artifact-cache." + jbsConfig.Namespace + ".svc.cluster.local/v2/cache/rebuild"
 	}
-	var javaHome string
-	if recipe.JavaVersion == "7" || recipe.JavaVersion == "8" {
-		javaHome = "/lib/jvm/java-1." + recipe.JavaVersion + ".0"
-	} else {
-		javaHome = "/lib/jvm/java-" + recipe.JavaVersion
-	}
+	javaHome := toolHome(toolPaths, "jdk", recipe.JavaVersion)
 
 	pullPolicy := pullPolicy(buildRequestProcessorImage)
-	limits, err := memoryLimits(jbsConfig, additionalMemory)
+	resources, err := stepResources(jbsConfig, recipe.BuildCPU, additionalMemory)
 	if err != nil {
 		return nil, "", err
 	}
 
+	reproducibilityCheck := reproducibilityCheckEnabled(jbsConfig, recipe)
 	createBuildScript := createBuildScript(build, hermeticBuildEntryScript)
 	pipelineParams := []pipelinev1beta1.ParamSpec{
 		{Name: PipelineBuildId, Type: pipelinev1beta1.ParamTypeString},
@@ -180,24 +213,50 @@ func createPipelineSpec(tool string, commitTime int64, jbsConfig *v1alpha12.JBSC
 		secretVariables = append(secretVariables, v1.EnvVar{Name: "GIT_DEPLOY_TOKEN", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: v1alpha12.GitRepoSecretName}, Key: v1alpha12.GitRepoSecretKey, Optional: &trueBool}}})
 	}
 
+	taskWorkspaces := []pipelinev1beta1.WorkspaceDeclaration{{Name: WorkspaceBuildSettings}, {Name: WorkspaceSource}, {Name: WorkspaceTls}}
+	taskWorkspaceBindings := []pipelinev1beta1.WorkspacePipelineTaskBinding{
+		{Name: WorkspaceBuildSettings, Workspace: WorkspaceBuildSettings},
+		{Name: WorkspaceSource, Workspace: WorkspaceSource},
+		{Name: WorkspaceTls, Workspace: WorkspaceTls},
+	}
+	pipelineWorkspaces := []pipelinev1beta1.PipelineWorkspaceDeclaration{{Name: WorkspaceBuildSettings}, {Name: WorkspaceSource}, {Name: WorkspaceTls}}
+	//the tool cache is only bound to the pre-build and build tasks, the hermetic build must not see it
+	cachingWorkspaces := taskWorkspaces
+	cachingWorkspaceBindings := taskWorkspaceBindings
+	if len(jbsConfig.Spec.BuildSettings.ToolCaches) > 0 {
+		cachingWorkspaces = append(append([]pipelinev1beta1.WorkspaceDeclaration{}, taskWorkspaces...), pipelinev1beta1.WorkspaceDeclaration{Name: WorkspaceToolCache})
+		cachingWorkspaceBindings = append(append([]pipelinev1beta1.WorkspacePipelineTaskBinding{}, taskWorkspaceBindings...), pipelinev1beta1.WorkspacePipelineTaskBinding{Name: WorkspaceToolCache, Workspace: WorkspaceToolCache})
+		pipelineWorkspaces = append(pipelineWorkspaces, pipelinev1beta1.PipelineWorkspaceDeclaration{Name: WorkspaceToolCache})
+	}
+
+	//if signature verification is enabled it happens straight after the checkout, before anything from the source is run
+	verifySignature := ""
+	var checkoutVolumeMounts []v1.VolumeMount
+	var buildSetupVolumes []v1.Volume
+	buildSetupResults := []pipelinev1beta1.TaskResult{{Name: PreBuildImageDigest, Type: pipelinev1beta1.ResultsTypeString}, {Name: PipelineResultResourceUsage, Type: pipelinev1beta1.ResultsTypeString}}
+	if jbsConfig.Spec.SignatureVerification != nil {
+		verifySignature = signatureVerificationScript(jbsConfig.Spec.SignatureVerification)
+		checkoutVolumeMounts = []v1.VolumeMount{{Name: signatureKeyringVolume, MountPath: signatureKeyringPath}}
+		buildSetupVolumes = []v1.Volume{{Name: signatureKeyringVolume, VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{LocalObjectReference: v1.LocalObjectReference{Name: jbsConfig.Spec.SignatureVerification.KeyringConfigMap}}}}}
+		buildSetupResults = append(buildSetupResults, pipelinev1beta1.TaskResult{Name: PipelineResultSignatureVerification, Type: pipelinev1beta1.ResultsTypeString})
+	}
+
 	buildSetup := pipelinev1beta1.TaskSpec{
-		Workspaces: []pipelinev1beta1.WorkspaceDeclaration{{Name: WorkspaceBuildSettings}, {Name: WorkspaceSource}, {Name: WorkspaceTls}},
+		Workspaces: cachingWorkspaces,
 		Params:     pipelineParams,
-		Results:    []pipelinev1beta1.TaskResult{{Name: PreBuildImageDigest, Type: pipelinev1beta1.ResultsTypeString}},
+		Results:    buildSetupResults,
+		Volumes:    buildSetupVolumes,
 		Steps: []pipelinev1beta1.Step{
 			{
-				Name:            "git-clone-and-settings",
-				Image:           "$(params." + PipelineParamImage + ")",
-				SecurityContext: &v1.SecurityContext{RunAsUser: &zero},
-				ComputeResources: v1.ResourceRequirements{
-					Requests: v1.ResourceList{"memory": limits.defaultRequestMemory, "cpu": limits.defaultRequestCPU},
-					Limits:   v1.ResourceList{"memory": limits.defaultRequestMemory, "cpu": limits.defaultLimitCPU},
-				},
-				Script: gitArgs + "\n" + createBuildScript,
-				Env: []v1.EnvVar{
+				Name:             "git-clone-and-settings",
+				Image:            "$(params." + PipelineParamImage + ")",
+				SecurityContext:  &v1.SecurityContext{RunAsUser: &zero},
+				ComputeResources: resources["git-clone-and-settings"],
+				Script:           withResourceUsage("git-clone-and-settings", withScmCredentials(jbsConfig, gitArgs+"\n"+verifySignature+patches+"\n"+createBuildScript)),
+				VolumeMounts:     checkoutVolumeMounts,
+				Env: append(append([]v1.EnvVar{
 					{Name: PipelineParamCacheUrl, Value: "$(params." + PipelineParamCacheUrl + ")"},
-					{Name: "GIT_TOKEN", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: v1alpha12.GitSecretName}, Key: v1alpha12.GitSecretTokenKey, Optional: &trueBool}}},
-				},
+				}, scm.credentials()...), scmCredentialsEnv(jbsConfig)...),
 			},
 			{
 				Name:            "preprocessor",
@@ -207,25 +266,17 @@ func createPipelineSpec(tool string, commitTime int64, jbsConfig *v1alpha12.JBSC
 				Env: []v1.EnvVar{
 					{Name: PipelineParamCacheUrl, Value: "$(params." + PipelineParamCacheUrl + ")"},
 				},
-				ComputeResources: v1.ResourceRequirements{
-					//TODO: make configurable
-					Requests: v1.ResourceList{"memory": limits.defaultRequestMemory, "cpu": limits.defaultRequestCPU},
-					Limits:   v1.ResourceList{"memory": limits.defaultRequestMemory, "cpu": limits.defaultLimitCPU},
-				},
-				Script: artifactbuild.InstallKeystoreIntoBuildRequestProcessor(preprocessorArgs),
+				ComputeResources: resources["preprocessor"],
+				Script:           withResourceUsage("preprocessor", artifactbuild.InstallKeystoreIntoBuildRequestProcessor(preprocessorArgs)),
 			},
 			{
-				Name:            "create-pre-build-image",
-				Image:           buildRequestProcessorImage,
-				ImagePullPolicy: pullPolicy,
-				SecurityContext: &v1.SecurityContext{RunAsUser: &zero},
-				Env:             secretVariables,
-				ComputeResources: v1.ResourceRequirements{
-					//TODO: make configurable
-					Requests: v1.ResourceList{"memory": limits.defaultBuildRequestMemory, "cpu": limits.defaultRequestCPU},
-					Limits:   v1.ResourceList{"memory": limits.defaultBuildRequestMemory, "cpu": limits.defaultLimitCPU},
-				},
-				Script: artifactbuild.InstallKeystoreIntoBuildRequestProcessor(preBuildImageArgs),
+				Name:             "create-pre-build-image",
+				Image:            buildRequestProcessorImage,
+				ImagePullPolicy:  pullPolicy,
+				SecurityContext:  &v1.SecurityContext{RunAsUser: &zero},
+				Env:              secretVariables,
+				ComputeResources: resources["create-pre-build-image"],
+				Script:           withResourceUsage("create-pre-build-image", artifactbuild.InstallKeystoreIntoBuildRequestProcessor(preBuildImageArgs)),
 			},
 		},
 	}
@@ -239,7 +290,7 @@ func createPipelineSpec(tool string, commitTime int64, jbsConfig *v1alpha12.JBSC
 		buildTaskScript = artifactbuild.InstallKeystoreIntoBuildRequestProcessor(verifyBuiltArtifactsArgs, deployArgs)
 	}
 	buildTask := pipelinev1beta1.TaskSpec{
-		Workspaces: []pipelinev1beta1.WorkspaceDeclaration{{Name: WorkspaceBuildSettings}, {Name: WorkspaceSource}, {Name: WorkspaceTls}},
+		Workspaces: cachingWorkspaces,
 		Params:     append(pipelineParams, pipelinev1beta1.ParamSpec{Name: PreBuildImageDigest, Type: pipelinev1beta1.ParamTypeString}),
 		Results: append(hermeticResults, []pipelinev1beta1.TaskResult{
 			{Name: artifactbuild.PipelineResultContaminants},
@@ -248,7 +299,12 @@ func createPipelineSpec(tool string, commitTime int64, jbsConfig *v1alpha12.JBSC
 			{Name: PipelineResultImageDigest},
 			{Name: artifactbuild.PipelineResultPassedVerification},
 			{Name: artifactbuild.PipelineResultVerificationResult},
+			{Name: artifactbuild.PipelineResultVerificationReport},
+			{Name: artifactbuild.PipelineResultSbom},
+			{Name: artifactbuild.PipelineResultLicenses},
+			{Name: PipelineResultResourceUsage},
 		}...),
+		Volumes: secretVolumes,
 		Steps: []pipelinev1beta1.Step{
 			{
 				Timeout:         &v12.Duration{Duration: time.Hour * 3},
@@ -257,37 +313,39 @@ func createPipelineSpec(tool string, commitTime int64, jbsConfig *v1alpha12.JBSC
 				ImagePullPolicy: v1.PullAlways,
 				WorkingDir:      "$(workspaces." + WorkspaceSource + ".path)/workspace",
 				SecurityContext: &v1.SecurityContext{RunAsUser: &zero},
-				Env: append(toolEnv, []v1.EnvVar{
+				Env: append(append([]v1.EnvVar{}, toolEnv...), []v1.EnvVar{
 					{Name: JavaHome, Value: javaHome},
 					{Name: PipelineParamCacheUrl, Value: "$(params." + PipelineParamCacheUrl + ")"},
 					{Name: PipelineParamEnforceVersion, Value: "$(params." + PipelineParamEnforceVersion + ")"},
 				}...),
-				ComputeResources: v1.ResourceRequirements{
-					//TODO: limits management and configuration
-					Requests: v1.ResourceList{"memory": limits.buildRequestMemory, "cpu": limits.buildRequestCPU},
-					Limits:   v1.ResourceList{"memory": limits.buildRequestMemory, "cpu": limits.buildRequestCPU},
-				},
-				Args:   []string{"$(params.GOALS[*])"},
-				Script: OriginalContentPath + "/build.sh \"$@\"",
+				VolumeMounts:     secretVolumeMounts,
+				ComputeResources: resources["build"],
+				Args:             []string{"$(params.GOALS[*])"},
+				Script:           withResourceUsage("build", OriginalContentPath+"/build.sh \"$@\""),
 			},
 			{
-				Name:            "verify-deploy-and-check-for-contaminates",
-				Image:           buildRequestProcessorImage,
-				ImagePullPolicy: pullPolicy,
-				SecurityContext: &v1.SecurityContext{RunAsUser: &zero},
-				Env:             secretVariables,
-				ComputeResources: v1.ResourceRequirements{
-					//TODO: make configurable
-					Requests: v1.ResourceList{"memory": limits.defaultBuildRequestMemory, "cpu": limits.defaultRequestCPU},
-					Limits:   v1.ResourceList{"memory": limits.defaultBuildRequestMemory, "cpu": limits.defaultLimitCPU},
-				},
-				Script: buildTaskScript,
+				Name:             "verify-deploy-and-check-for-contaminates",
+				Image:            buildRequestProcessorImage,
+				ImagePullPolicy:  pullPolicy,
+				SecurityContext:  &v1.SecurityContext{RunAsUser: &zero},
+				Env:              secretVariables,
+				ComputeResources: resources["verify-deploy-and-check-for-contaminates"],
+				Script:           withResourceUsage("verify-deploy-and-check-for-contaminates", buildTaskScript),
 			},
 		},
 	}
 
+	var reproducibilityTask pipelinev1beta1.TaskSpec
+	if reproducibilityCheck {
+		//the second build runs in parallel in its own task and workspace, it does not get the tool cache so nothing is shared with the first build
+		reproducibilityTask = reproducibilityTaskSpec(&buildTask, taskWorkspaces, resources)
+		buildTask.Results = append(buildTask.Results, pipelinev1beta1.TaskResult{Name: PipelineResultArtifactChecksums})
+		buildTask.Steps = append([]pipelinev1beta1.Step{buildTask.Steps[0], artifactChecksumsStepSpec(resources[artifactChecksumsStep])}, buildTask.Steps[1:]...)
+	}
+
 	hermeticBuildTask := pipelinev1beta1.TaskSpec{
-		Workspaces: []pipelinev1beta1.WorkspaceDeclaration{{Name: WorkspaceBuildSettings}, {Name: WorkspaceSource}, {Name: WorkspaceTls}},
+		Workspaces: taskWorkspaces,
+		Volumes:    secretVolumes,
 		Params:     append(pipelineParams, pipelinev1beta1.ParamSpec{Name: HermeticPreBuildImageDigest, Type: pipelinev1beta1.ParamTypeString}),
 		Results: []pipelinev1beta1.TaskResult{
 			{Name: artifactbuild.PipelineResultContaminants},
@@ -296,6 +354,10 @@ func createPipelineSpec(tool string, commitTime int64, jbsConfig *v1alpha12.JBSC
 			{Name: PipelineResultImageDigest},
 			{Name: artifactbuild.PipelineResultPassedVerification},
 			{Name: artifactbuild.PipelineResultVerificationResult},
+			{Name: artifactbuild.PipelineResultVerificationReport},
+			{Name: artifactbuild.PipelineResultSbom},
+			{Name: artifactbuild.PipelineResultLicenses},
+			{Name: PipelineResultResourceUsage},
 		},
 		Steps: []pipelinev1beta1.Step{
 			{
@@ -304,51 +366,41 @@ func createPipelineSpec(tool string, commitTime int64, jbsConfig *v1alpha12.JBSC
 				ImagePullPolicy: v1.PullAlways,
 				WorkingDir:      "$(workspaces." + WorkspaceSource + ".path)",
 				SecurityContext: &v1.SecurityContext{RunAsUser: &zero, Capabilities: &v1.Capabilities{Add: []v1.Capability{"SETFCAP"}}},
-				Env: append(toolEnv, []v1.EnvVar{
+				Env: append(append([]v1.EnvVar{}, toolEnv...), []v1.EnvVar{
 					{Name: JavaHome, Value: javaHome},
 					{Name: PipelineParamCacheUrl, Value: "file://" + MavenArtifactsPath},
 					{Name: PipelineParamEnforceVersion, Value: "$(params." + PipelineParamEnforceVersion + ")"},
 				}...),
-				ComputeResources: v1.ResourceRequirements{
-					//TODO: limits management and configuration
-					Requests: v1.ResourceList{"memory": limits.buildRequestMemory, "cpu": limits.buildRequestCPU},
-				},
+				VolumeMounts:     secretVolumeMounts,
+				ComputeResources: resources["hermetic-build"],
 
 				Args: []string{"$(params.GOALS[*])"},
 
-				Script: OriginalContentPath + "/hermetic-build.sh \"$@\"",
+				Script: withResourceUsage("hermetic-build", OriginalContentPath+"/hermetic-build.sh \"$@\""),
 			},
 			{
-				Name:            "verify-deploy-and-check-for-contaminates",
-				Image:           buildRequestProcessorImage,
-				ImagePullPolicy: pullPolicy,
-				SecurityContext: &v1.SecurityContext{RunAsUser: &zero},
-				Env:             secretVariables,
-				ComputeResources: v1.ResourceRequirements{
-					//TODO: make configurable
-					Requests: v1.ResourceList{"memory": limits.defaultBuildRequestMemory, "cpu": limits.defaultRequestCPU},
-					Limits:   v1.ResourceList{"memory": limits.defaultBuildRequestMemory, "cpu": limits.defaultLimitCPU},
-				},
-				Script: artifactbuild.InstallKeystoreIntoBuildRequestProcessor(verifyBuiltArtifactsArgs, hermeticDeployArgs),
+				Name:             "verify-deploy-and-check-for-contaminates",
+				Image:            buildRequestProcessorImage,
+				ImagePullPolicy:  pullPolicy,
+				SecurityContext:  &v1.SecurityContext{RunAsUser: &zero},
+				Env:              secretVariables,
+				ComputeResources: resources["verify-deploy-and-check-for-contaminates"],
+				Script:           withResourceUsage("verify-deploy-and-check-for-contaminates", artifactbuild.InstallKeystoreIntoBuildRequestProcessor(verifyBuiltArtifactsArgs, hermeticDeployArgs)),
 			},
 		},
 	}
 	tagTask := pipelinev1beta1.TaskSpec{
-		Workspaces: []pipelinev1beta1.WorkspaceDeclaration{{Name: WorkspaceBuildSettings}, {Name: WorkspaceSource}, {Name: WorkspaceTls}},
+		Workspaces: taskWorkspaces,
 		Params:     []pipelinev1beta1.ParamSpec{{Name: "GAVS", Type: pipelinev1beta1.ParamTypeString}, {Name: DeployedImageDigest, Type: pipelinev1beta1.ParamTypeString}},
 		Steps: []pipelinev1beta1.Step{
 			{
-				Name:            "tag",
-				Image:           buildRequestProcessorImage,
-				ImagePullPolicy: pullPolicy,
-				SecurityContext: &v1.SecurityContext{RunAsUser: &zero},
-				Env:             secretVariables,
-				ComputeResources: v1.ResourceRequirements{
-					//TODO: make configurable
-					Requests: v1.ResourceList{"memory": limits.defaultBuildRequestMemory, "cpu": limits.defaultRequestCPU},
-					Limits:   v1.ResourceList{"memory": limits.defaultBuildRequestMemory, "cpu": limits.defaultLimitCPU},
-				},
-				Script: artifactbuild.InstallKeystoreIntoBuildRequestProcessor(tagArgs),
+				Name:             "tag",
+				Image:            buildRequestProcessorImage,
+				ImagePullPolicy:  pullPolicy,
+				SecurityContext:  &v1.SecurityContext{RunAsUser: &zero},
+				Env:              secretVariables,
+				ComputeResources: resources["tag"],
+				Script:           artifactbuild.InstallKeystoreIntoBuildRequestProcessor(tagArgs),
 			},
 		},
 	}
@@ -359,6 +411,19 @@ func createPipelineSpec(tool string, commitTime int64, jbsConfig *v1alpha12.JBSC
 		tagDepends = artifactbuild.HermeticBuildTaskName
 		tagDigest = "$(tasks." + artifactbuild.HermeticBuildTaskName + ".results." + PipelineResultImageDigest + ")"
 	}
+	tagRunAfter := []string{tagDepends}
+	tagParams := []pipelinev1beta1.Param{{Name: DeployedImageDigest, Value: pipelinev1beta1.ParamValue{Type: pipelinev1beta1.ParamTypeString, StringVal: tagDigest}}}
+	if reproducibilityCheck {
+		//the output of the two builds is compared before anything is tagged
+		required := jbsConfig.Spec.ReproducibilityCheck != nil && jbsConfig.Spec.ReproducibilityCheck.Required
+		tagTask.Params = append(tagTask.Params, pipelinev1beta1.ParamSpec{Name: firstBuildChecksums, Type: pipelinev1beta1.ParamTypeString}, pipelinev1beta1.ParamSpec{Name: secondBuildChecksums, Type: pipelinev1beta1.ParamTypeString})
+		tagTask.Results = append(tagTask.Results, pipelinev1beta1.TaskResult{Name: PipelineResultReproducibility})
+		tagTask.Steps = append([]pipelinev1beta1.Step{compareBuildsStepSpec(buildRequestProcessorImage, required, resources[compareBuildsStep])}, tagTask.Steps...)
+		tagRunAfter = append(tagRunAfter, artifactbuild.ReproducibilityTaskName)
+		tagParams = append(tagParams,
+			pipelinev1beta1.Param{Name: firstBuildChecksums, Value: pipelinev1beta1.ParamValue{Type: pipelinev1beta1.ParamTypeString, StringVal: "$(tasks." + artifactbuild.BuildTaskName + ".results." + PipelineResultArtifactChecksums + ")"}},
+			pipelinev1beta1.Param{Name: secondBuildChecksums, Value: pipelinev1beta1.ParamValue{Type: pipelinev1beta1.ParamTypeString, StringVal: "$(tasks." + artifactbuild.ReproducibilityTaskName + ".results." + PipelineResultArtifactChecksums + ")"}})
+	}
 	hermeticBuildPipelineTask := pipelinev1beta1.PipelineTask{
 		Name:     artifactbuild.HermeticBuildTaskName,
 		RunAfter: []string{artifactbuild.BuildTaskName},
@@ -366,25 +431,17 @@ func createPipelineSpec(tool string, commitTime int64, jbsConfig *v1alpha12.JBSC
 			TaskSpec: hermeticBuildTask,
 		},
 
-		Params: []pipelinev1beta1.Param{{Name: HermeticPreBuildImageDigest, Value: pipelinev1beta1.ParamValue{Type: pipelinev1beta1.ParamTypeString, StringVal: "$(tasks." + artifactbuild.BuildTaskName + ".results." + HermeticPreBuildImageDigest + ")"}}},
-		Workspaces: []pipelinev1beta1.WorkspacePipelineTaskBinding{
-			{Name: WorkspaceBuildSettings, Workspace: WorkspaceBuildSettings},
-			{Name: WorkspaceSource, Workspace: WorkspaceSource},
-			{Name: WorkspaceTls, Workspace: WorkspaceTls},
-		},
+		Params:     []pipelinev1beta1.Param{{Name: HermeticPreBuildImageDigest, Value: pipelinev1beta1.ParamValue{Type: pipelinev1beta1.ParamTypeString, StringVal: "$(tasks." + artifactbuild.BuildTaskName + ".results." + HermeticPreBuildImageDigest + ")"}}},
+		Workspaces: taskWorkspaceBindings,
 	}
 	tagPipelineTask := pipelinev1beta1.PipelineTask{
 		Name:     artifactbuild.TagTaskName,
-		RunAfter: []string{tagDepends},
+		RunAfter: tagRunAfter,
 		TaskSpec: &pipelinev1beta1.EmbeddedTask{
 			TaskSpec: tagTask,
 		},
-		Params: []pipelinev1beta1.Param{{Name: DeployedImageDigest, Value: pipelinev1beta1.ParamValue{Type: pipelinev1beta1.ParamTypeString, StringVal: tagDigest}}},
-		Workspaces: []pipelinev1beta1.WorkspacePipelineTaskBinding{
-			{Name: WorkspaceBuildSettings, Workspace: WorkspaceBuildSettings},
-			{Name: WorkspaceSource, Workspace: WorkspaceSource},
-			{Name: WorkspaceTls, Workspace: WorkspaceTls},
-		},
+		Params:     tagParams,
+		Workspaces: taskWorkspaceBindings,
 	}
 
 	ps := &pipelinev1beta1.PipelineSpec{
@@ -394,11 +451,7 @@ func createPipelineSpec(tool string, commitTime int64, jbsConfig *v1alpha12.JBSC
 				TaskSpec: &pipelinev1beta1.EmbeddedTask{
 					TaskSpec: buildSetup,
 				},
-				Params: []pipelinev1beta1.Param{}, Workspaces: []pipelinev1beta1.WorkspacePipelineTaskBinding{
-					{Name: WorkspaceBuildSettings, Workspace: WorkspaceBuildSettings},
-					{Name: WorkspaceSource, Workspace: WorkspaceSource},
-					{Name: WorkspaceTls, Workspace: WorkspaceTls},
-				},
+				Params: []pipelinev1beta1.Param{}, Workspaces: cachingWorkspaceBindings,
 			},
 			{
 				Name:     artifactbuild.BuildTaskName,
@@ -406,24 +459,60 @@ func createPipelineSpec(tool string, commitTime int64, jbsConfig *v1alpha12.JBSC
 				TaskSpec: &pipelinev1beta1.EmbeddedTask{
 					TaskSpec: buildTask,
 				},
-				Params: []pipelinev1beta1.Param{{Name: PreBuildImageDigest, Value: pipelinev1beta1.ParamValue{Type: pipelinev1beta1.ParamTypeString, StringVal: "$(tasks." + artifactbuild.PreBuildTaskName + ".results." + PreBuildImageDigest + ")"}}},
-				Workspaces: []pipelinev1beta1.WorkspacePipelineTaskBinding{
-					{Name: WorkspaceBuildSettings, Workspace: WorkspaceBuildSettings},
-					{Name: WorkspaceSource, Workspace: WorkspaceSource},
-					{Name: WorkspaceTls, Workspace: WorkspaceTls},
-				},
+				Params:     []pipelinev1beta1.Param{{Name: PreBuildImageDigest, Value: pipelinev1beta1.ParamValue{Type: pipelinev1beta1.ParamTypeString, StringVal: "$(tasks." + artifactbuild.PreBuildTaskName + ".results." + PreBuildImageDigest + ")"}}},
+				Workspaces: cachingWorkspaceBindings,
 			},
 		},
-		Workspaces: []pipelinev1beta1.PipelineWorkspaceDeclaration{{Name: WorkspaceBuildSettings}, {Name: WorkspaceSource}, {Name: WorkspaceTls}},
+		Workspaces: pipelineWorkspaces,
+	}
+	if reproducibilityCheck {
+		ps.Tasks = append(ps.Tasks, pipelinev1beta1.PipelineTask{
+			Name:     artifactbuild.ReproducibilityTaskName,
+			RunAfter: []string{artifactbuild.PreBuildTaskName},
+			TaskSpec: &pipelinev1beta1.EmbeddedTask{
+				TaskSpec: reproducibilityTask,
+			},
+			Params: []pipelinev1beta1.Param{{Name: PreBuildImageDigest, Value: pipelinev1beta1.ParamValue{Type: pipelinev1beta1.ParamTypeString, StringVal: "$(tasks." + artifactbuild.PreBuildTaskName + ".results." + PreBuildImageDigest + ")"}}},
+			Workspaces: []pipelinev1beta1.WorkspacePipelineTaskBinding{
+				{Name: WorkspaceBuildSettings, Workspace: WorkspaceBuildSettings},
+				{Name: WorkspaceSource, Workspace: WorkspaceReproducibility},
+				{Name: WorkspaceTls, Workspace: WorkspaceTls},
+			},
+		})
+		ps.Workspaces = append(ps.Workspaces, pipelinev1beta1.PipelineWorkspaceDeclaration{Name: WorkspaceReproducibility})
 	}
 	if hermeticBuildRequired {
 		ps.Tasks = append(ps.Tasks, hermeticBuildPipelineTask)
 	}
 	ps.Tasks = append(ps.Tasks, tagPipelineTask)
+	if jbsConfig.Spec.ImageSigning != nil {
+		cosignImage, err := systemconfig.PinnedCosignImage(systemConfig)
+		if err != nil {
+			return nil, "", err
+		}
+		ps.Tasks = append(ps.Tasks, pipelinev1beta1.PipelineTask{
+			Name:     artifactbuild.SignTaskName,
+			RunAfter: []string{artifactbuild.TagTaskName},
+			TaskSpec: &pipelinev1beta1.EmbeddedTask{
+				TaskSpec: signTask(cosignImage, jbsConfig, resources[artifactbuild.SignTaskName]),
+			},
+			Params: []pipelinev1beta1.Param{
+				{Name: PipelineResultImage, Value: pipelinev1beta1.ParamValue{Type: pipelinev1beta1.ParamTypeString, StringVal: "$(tasks." + tagDepends + ".results." + PipelineResultImage + ")"}},
+				{Name: DeployedImageDigest, Value: pipelinev1beta1.ParamValue{Type: pipelinev1beta1.ParamTypeString, StringVal: tagDigest}},
+			},
+		})
+	}
 
 	for _, i := range buildTask.Results {
+		//these are read from the TaskRuns, as they are needed for failed builds as well
+		if i.Name == PipelineResultResourceUsage || i.Name == PipelineResultArtifactChecksums || i.Name == artifactbuild.PipelineResultVerificationReport || i.Name == artifactbuild.PipelineResultSbom || i.Name == artifactbuild.PipelineResultLicenses {
+			continue
+		}
 		ps.Results = append(ps.Results, pipelinev1beta1.PipelineResult{Name: i.Name, Description: i.Description, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: "$(tasks." + artifactbuild.BuildTaskName + ".results." + i.Name + ")"}})
 	}
+	if jbsConfig.Spec.SignatureVerification != nil {
+		ps.Results = append(ps.Results, pipelinev1beta1.PipelineResult{Name: PipelineResultSignatureVerification, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: "$(tasks." + artifactbuild.PreBuildTaskName + ".results." + PipelineResultSignatureVerification + ")"}})
+	}
 	for _, i := range buildSetup.Params {
 		ps.Params = append(ps.Params, pipelinev1beta1.ParamSpec{Name: i.Name, Description: i.Description, Default: i.Default, Type: i.Type})
 		var value pipelinev1beta1.ResultValue
@@ -469,7 +558,9 @@ func createPipelineSpec(tool string, commitTime int64, jbsConfig *v1alpha12.JBSC
 		"\nCOPY --from=build-request-processor /lib/jvm/jre-17 /root/software/system-java" +
 		"\nCOPY --from=build-request-processor /etc/java/java-17-openjdk /etc/java/java-17-openjdk" +
 		"\nCOPY --from=cache /deployments/ /root/software/cache" +
+		secretMountDockerfileSection(jbsConfig, recipe) +
 		"\nRUN " + doSubstitution(gitArgs, paramValues, commitTime, buildRepos) +
+		patchDockerfileSection(patches, recipe, paramValues, commitTime, buildRepos) +
 		"\nRUN echo " + base64.StdEncoding.EncodeToString([]byte("#!/bin/sh\n/root/software/system-java/bin/java -Dbuild-policy.default.store-list=rebuilt,central,jboss,redhat -Dkube.disabled=true -Dquarkus.kubernetes-client.trust-certs=true -jar /root/software/cache/quarkus-run.jar >/root/cache.log &"+
 		"\nwhile ! cat /root/cache.log | grep 'Listening on:'; do\n        echo \"Waiting for Cache to start\"\n        sleep 1\ndone \n")) + " | base64 -d >/root/start-cache.sh" +
 		"\nRUN echo " + base64.StdEncoding.EncodeToString([]byte("#!/bin/sh\n/root/software/system-java/bin/java -jar /root/software/build-request-processor/quarkus-run.jar "+doSubstitution(strings.Join(preprocessorArgs, " "), paramValues, commitTime, buildRepos)+"\n")) + " | base64 -d >/root/preprocessor.sh" +
@@ -482,6 +573,16 @@ func createPipelineSpec(tool string, commitTime int64, jbsConfig *v1alpha12.JBSC
 	return ps, df, nil
 }
 
+// patchDockerfileSection applies the recipe patches in the diagnostic Dockerfile, listing their digests so the
+// patched source can be identified
+func patchDockerfileSection(patches string, recipe *v1alpha12.BuildRecipe, paramValues []pipelinev1beta1.Param, commitTime int64, buildRepos string) string {
+	if patches == "" {
+		return ""
+	}
+	lines := strings.Split(strings.TrimSpace(patches), "\n")
+	return "\n# Patches: " + strings.Join(patchDigests(recipe), ", ") + "\nRUN set -e; " + doSubstitution(strings.Join(lines, "; \\\n    "), paramValues, commitTime, buildRepos)
+}
+
 func createBuildScript(build string, hermeticBuildEntryScript string) string {
 	ret := "tee $(workspaces." + WorkspaceSource + ".path)/build.sh <<'RHTAPEOF'\n"
 	ret += build
@@ -504,93 +605,386 @@ func pullPolicy(buildRequestProcessorImage string) v1.PullPolicy {
 	return pullPolicy
 }
 
-type memLimits struct {
-	defaultRequestMemory, defaultBuildRequestMemory, defaultRequestCPU, defaultLimitCPU, buildRequestCPU, buildRequestMemory resource.Quantity
-}
-
-func memoryLimits(jbsConfig *v1alpha12.JBSConfig, additionalMemory int) (*memLimits, error) {
-	limits := memLimits{}
-	var err error
-	limits.defaultRequestMemory, err = resource.ParseQuantity(settingOrDefault(jbsConfig.Spec.BuildSettings.TaskRequestMemory, "512Mi"))
-	if err != nil {
-		return nil, err
+// reproducibleBuildEnvironment returns the environment that makes the build output independent of when and where it
+// was built, and the umask to build with. The commit time is in milliseconds.
+func reproducibleBuildEnvironment(recipe *v1alpha12.BuildRecipe, commitTime int64) ([]v1.EnvVar, string, error) {
+	settings := v1alpha12.ReproducibleBuildSettings{}
+	if recipe.Reproducible != nil {
+		settings = *recipe.Reproducible
 	}
-	limits.defaultBuildRequestMemory, err = resource.ParseQuantity(settingOrDefault(jbsConfig.Spec.BuildSettings.BuildRequestMemory, "1024Mi"))
-	if err != nil {
-		return nil, err
+	locale := settingOrDefault(settings.Locale, "en_US.UTF-8")
+	umask := settingOrDefault(settings.Umask, "0022")
+	if !umaskRegex.MatchString(umask) {
+		return nil, "0022", fmt.Errorf("invalid umask %s", umask)
 	}
-	limits.defaultRequestCPU, err = resource.ParseQuantity(settingOrDefault(jbsConfig.Spec.BuildSettings.TaskRequestCPU, "10m"))
-	if err != nil {
-		return nil, err
+	env := []v1.EnvVar{
+		{Name: "LANG", Value: locale},
+		{Name: "LC_ALL", Value: locale},
+		{Name: "TZ", Value: settingOrDefault(settings.TimeZone, "UTC")},
 	}
-	limits.defaultLimitCPU, err = resource.ParseQuantity(settingOrDefault(jbsConfig.Spec.BuildSettings.TaskLimitCPU, "300m"))
-	if err != nil {
-		return nil, err
+	if commitTime > 0 {
+		epoch := strconv.FormatInt(commitTime/1000, 10)
+		env = append(env, v1.EnvVar{Name: "SOURCE_DATE_EPOCH", Value: epoch})
+		if !settings.DisableOutputTimestamp {
+			env = append(env, v1.EnvVar{Name: "OUTPUT_TIMESTAMP", Value: epoch})
+		}
 	}
-	limits.buildRequestCPU, err = resource.ParseQuantity(settingOrDefault(jbsConfig.Spec.BuildSettings.BuildRequestCPU, "300m"))
-	if err != nil {
-		return nil, err
+	return env, umask, nil
+}
+
+// stepResources returns the resources for each step of the build pipeline, buildCPU is the recommended CPU for the
+// build steps and replaces the default if set
+func stepResources(jbsConfig *v1alpha12.JBSConfig, buildCPU string, additionalMemory int) (map[string]v1.ResourceRequirements, error) {
+	settings := jbsConfig.Spec.BuildSettings
+	taskMemory := settingOrDefault(settings.TaskRequestMemory, "512Mi")
+	taskCPU := settingOrDefault(settings.TaskRequestCPU, "10m")
+	taskLimitCPU := settingOrDefault(settings.TaskLimitCPU, "300m")
+	buildMemory := settingOrDefault(settings.BuildRequestMemory, "1024Mi")
+	if buildCPU == "" {
+		buildCPU = settingOrDefault(settings.BuildRequestCPU, "300m")
 	}
+	task := v1alpha12.StepResources{RequestMemory: taskMemory, RequestCPU: taskCPU, LimitMemory: settingOrDefault(settings.TaskLimitMemory, taskMemory), LimitCPU: taskLimitCPU}
+	deploy := v1alpha12.StepResources{RequestMemory: buildMemory, RequestCPU: taskCPU, LimitMemory: buildMemory, LimitCPU: taskLimitCPU}
+	build := v1alpha12.StepResources{RequestMemory: buildMemory, RequestCPU: buildCPU, LimitMemory: buildMemory, LimitCPU: buildCPU}
 
-	limits.buildRequestMemory = limits.defaultBuildRequestMemory
-	if additionalMemory > 0 {
-		additional := resource.MustParse(fmt.Sprintf("%dMi", additionalMemory))
-		limits.buildRequestMemory.Add(additional)
-		limits.defaultRequestMemory.Add(additional)
+	steps := []struct {
+		name             string
+		defaults         v1alpha12.StepResources
+		additionalMemory int
+	}{
+		{"git-clone-and-settings", task, additionalMemory},
+		{"preprocessor", task, additionalMemory},
+		{"create-pre-build-image", deploy, 0},
+		{"build", build, additionalMemory},
+		{"hermetic-build", build, additionalMemory},
+		{reproducibilityBuildStep, build, additionalMemory},
+		{artifactChecksumsStep, task, 0},
+		{compareBuildsStep, task, 0},
+		{"verify-deploy-and-check-for-contaminates", deploy, 0},
+		{"tag", deploy, 0},
+		{artifactbuild.SignTaskName, task, 0},
 	}
-	return &limits, nil
+	ret := map[string]v1.ResourceRequirements{}
+	for _, i := range steps {
+		resources, err := util.StepResources(jbsConfig, i.name, i.defaults, i.additionalMemory)
+		if err != nil {
+			return nil, err
+		}
+		ret[i.name] = resources
+	}
+	return ret, nil
 }
 
-func additionalPackages(recipe *v1alpha12.BuildRecipe) string {
+func additionalPackages(recipe *v1alpha12.BuildRecipe) (string, error) {
 	install := ""
 	for count, i := range recipe.AdditionalDownloads {
-		if i.FileType == "tar" {
-			if i.BinaryPath == "" {
-				install = "echo 'Binary path not specified for package " + i.Uri + "'; exit 1"
-			}
-
-		} else if i.FileType == "executable" {
-			if i.FileName == "" {
-				install = "echo 'File name not specified for package " + i.Uri + "'; exit 1"
-			}
-		} else if i.FileType == "rpm" {
-			if i.PackageName == "" {
-				install = "echo 'Package name not specified for rpm type'; exit 1"
-			}
-		} else {
-			//unknown
-			//we still run the pipeline so there is logs
-			install = "echo 'Unknown file type " + i.FileType + " for package " + i.Uri + "'; exit 1"
-			break
+		//these are validated when the recipe is created, but older recipes may still be invalid
+		if err := validateAdditionalDownload(i); err != nil {
+			return "", err
 		}
 		template := packageTemplate
 		fileName := i.FileName
 		if fileName == "" {
 			fileName = "package-" + strconv.Itoa(count)
 		}
-		template = strings.ReplaceAll(template, "{URI}", i.Uri)
+		uri := i.Uri
+		if i.FileType == v1alpha12.AdditionalDownloadTypeMaven {
+			path, _ := mavenArtifactPath(i.Gav)
+			uri = "${CACHE_URL}/" + path
+		}
+		template = strings.ReplaceAll(template, "{URI}", uri)
 		template = strings.ReplaceAll(template, "{FILENAME}", fileName)
 		template = strings.ReplaceAll(template, "{SHA256}", i.Sha256)
 		template = strings.ReplaceAll(template, "{TYPE}", i.FileType)
 		template = strings.ReplaceAll(template, "{BINARY_PATH}", i.BinaryPath)
 		template = strings.ReplaceAll(template, "{PACKAGE_NAME}", i.PackageName)
+		template = strings.ReplaceAll(template, "{STRIP_COMPONENTS}", strconv.Itoa(i.StripComponents))
 		install = install + template
 	}
-	return install
+	return install, nil
 }
 
-func gitArgs(db *v1alpha12.DependencyBuild, recipe *v1alpha12.BuildRecipe) string {
-	gitArgs := ""
-	if db.Spec.ScmInfo.Private {
-		gitArgs = "echo \"$GIT_TOKEN\"  > $HOME/.git-credentials\nchmod 400 $HOME/.git-credentials\n"
-		gitArgs = gitArgs + "echo '[credential]\n        helper=store\n' > $HOME/.gitconfig\n"
+// builderToolPaths returns the tool install locations declared by the builder image, either directly or through
+// the install paths of its capabilities
+func builderToolPaths(systemConfig *v1alpha12.SystemConfig, image string) []v1alpha12.ToolPath {
+	for _, i := range systemConfig.Spec.Builders {
+		if i.Image == image {
+			ret := append([]v1alpha12.ToolPath{}, i.ToolPaths...)
+			for _, capability := range i.Capabilities {
+				if capability.InstallPath == "" {
+					continue
+				}
+				for _, version := range capability.Versions {
+					ret = append(ret, v1alpha12.ToolPath{Tool: capability.Tool, Version: version, Path: strings.ReplaceAll(capability.InstallPath, "{version}", version)})
+				}
+			}
+			return ret
+		}
 	}
-	gitArgs = gitArgs + "git clone $(params." + PipelineParamScmUrl + ") $(workspaces." + WorkspaceSource + ".path)/workspace && cd $(workspaces." + WorkspaceSource + ".path)/workspace && git reset --hard $(params." + PipelineParamScmHash + ")"
+	return nil
+}
 
-	if !recipe.DisableSubmodules {
-		gitArgs = gitArgs + " && git submodule init && git submodule update --recursive"
+// toolHome returns the install location of a tool, falling back to the conventional location if the builder image
+// does not declare one
+func toolHome(toolPaths []v1alpha12.ToolPath, tool string, version string) string {
+	for _, i := range toolPaths {
+		if i.Tool == tool && i.Version == version {
+			return i.Path
+		}
 	}
-	return gitArgs
+	if tool == "jdk" {
+		if version == "7" || version == "8" {
+			return "/lib/jvm/java-1." + version + ".0"
+		}
+		return "/lib/jvm/java-" + version
+	}
+	return "/opt/" + tool + "/" + version
+}
+
+type jdkToolchain struct {
+	version string
+	home    string
+}
+
+// jdkToolchains returns the JDKs available for toolchains. If the builder image does not declare its JDKs we assume
+// the conventional set of OpenJDK packages is installed.
+func jdkToolchains(toolPaths []v1alpha12.ToolPath, javaVersion string) []jdkToolchain {
+	ret := []jdkToolchain{}
+	for _, i := range toolPaths {
+		if i.Tool == "jdk" {
+			ret = append(ret, jdkToolchain{version: toolchainVersion(i.Version), home: i.Path})
+		}
+	}
+	if len(ret) > 0 {
+		return ret
+	}
+	homes := []string{"1.8.0", "11", "17"}
+	if javaVersion == "7" || javaVersion == "1.7" {
+		homes = []string{"1.7.0", "1.8.0", "11"}
+	}
+	for _, i := range homes {
+		ret = append(ret, jdkToolchain{version: strings.TrimSuffix(i, ".0"), home: "/usr/lib/jvm/java-" + i + "-openjdk"})
+	}
+	return ret
+}
+
+// toolchainVersion converts a JDK version to the form used by toolchain requirements, which is 1.x for Java 8 and
+// older
+func toolchainVersion(version string) string {
+	if version == "7" || version == "8" {
+		return "1." + version
+	}
+	return version
+}
+
+func mavenToolchains(jdks []jdkToolchain) string {
+	ret := "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<toolchains>\n"
+	for _, i := range jdks {
+		ret += "  <toolchain>\n    <type>jdk</type>\n    <provides>\n      <version>" + i.version + "</version>\n    </provides>\n" +
+			"    <configuration>\n      <jdkHome>" + i.home + "</jdkHome>\n    </configuration>\n  </toolchain>\n"
+	}
+	return ret + "</toolchains>"
+}
+
+func gradleJavaInstallations(jdks []jdkToolchain) string {
+	homes := []string{}
+	for _, i := range jdks {
+		homes = append(homes, i.home)
+	}
+	return strings.Join(homes, ",")
+}
+
+var sha256Regex = regexp.MustCompile("^[a-f0-9]{64}$")
+var umaskRegex = regexp.MustCompile("^[0-7]{3,4}$")
+
+// validateAdditionalDownload checks that an additional download has everything required for its type. Everything
+// except rpms needs a sha256, see AdditionalDownload.Sha256 for why rpms are the exception.
+func validateAdditionalDownload(download v1alpha12.AdditionalDownload) error {
+	switch download.FileType {
+	case v1alpha12.AdditionalDownloadTypeRpm:
+		if download.PackageName == "" {
+			return fmt.Errorf("package name not specified for rpm type")
+		}
+		return nil
+	case v1alpha12.AdditionalDownloadTypeExecutable:
+		if download.FileName == "" {
+			return fmt.Errorf("file name not specified for package %s", download.Uri)
+		}
+	case v1alpha12.AdditionalDownloadTypeTar, v1alpha12.AdditionalDownloadTypeTarGz, v1alpha12.AdditionalDownloadTypeTarBz2, v1alpha12.AdditionalDownloadTypeTarXz, v1alpha12.AdditionalDownloadTypeZip:
+		if download.BinaryPath == "" {
+			return fmt.Errorf("binary path not specified for package %s", download.Uri)
+		}
+		if download.StripComponents < 0 {
+			return fmt.Errorf("strip components cannot be negative for package %s", download.Uri)
+		}
+	case v1alpha12.AdditionalDownloadTypeMaven:
+		if download.FileName == "" {
+			return fmt.Errorf("file name not specified for maven artifact %s", download.Gav)
+		}
+		if _, err := mavenArtifactPath(download.Gav); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown file type %s for package %s", download.FileType, download.Uri)
+	}
+	if download.FileType != v1alpha12.AdditionalDownloadTypeMaven && download.Uri == "" {
+		return fmt.Errorf("uri not specified for %s package", download.FileType)
+	}
+	if !sha256Regex.MatchString(download.Sha256) {
+		return fmt.Errorf("missing or invalid sha256 for package %s%s", download.Uri, download.Gav)
+	}
+	return nil
+}
+
+// mavenArtifactPath returns the repository path of an artifact in the form groupId:artifactId[:extension[:classifier]]:version
+func mavenArtifactPath(gav string) (string, error) {
+	parts := strings.Split(gav, ":")
+	extension := "jar"
+	classifier := ""
+	switch len(parts) {
+	case 3:
+	case 4:
+		extension = parts[2]
+	case 5:
+		extension = parts[2]
+		classifier = "-" + parts[3]
+	default:
+		return "", fmt.Errorf("invalid maven artifact %s", gav)
+	}
+	for _, i := range parts {
+		if i == "" {
+			return "", fmt.Errorf("invalid maven artifact %s", gav)
+		}
+	}
+	group, artifact, version := parts[0], parts[1], parts[len(parts)-1]
+	return strings.ReplaceAll(group, ".", "/") + "/" + artifact + "/" + version + "/" + artifact + "-" + version + classifier + "." + extension, nil
+}
+
+// buildEnvironment merges the environment variables and secret mounts from the JBSConfig build settings with those
+// from the recipe. Recipes are not controlled by the namespace owner, so their values must be in the allow lists.
+func buildEnvironment(jbsConfig *v1alpha12.JBSConfig, recipe *v1alpha12.BuildRecipe) ([]v1.EnvVar, []v1.Volume, []v1.VolumeMount, error) {
+	settings := jbsConfig.Spec.BuildSettings
+	for _, i := range recipe.Env {
+		if !envAllowed(settings.AllowedRecipeEnv, i.Name) {
+			return nil, nil, nil, fmt.Errorf("environment variable %s is not allowed by the JBSConfig", i.Name)
+		}
+	}
+	for _, i := range recipe.SecretMounts {
+		if !slices.Contains(settings.AllowedRecipeSecrets, i.SecretName) {
+			return nil, nil, nil, fmt.Errorf("secret %s is not allowed by the JBSConfig", i.SecretName)
+		}
+	}
+	env := []v1.EnvVar{}
+	index := map[string]int{}
+	for _, i := range append(append([]v1alpha12.BuildEnvVar{}, settings.Env...), recipe.Env...) {
+		//recipe values replace the defaults from the build settings
+		if existing, ok := index[i.Name]; ok {
+			env[existing].Value = i.Value
+		} else {
+			index[i.Name] = len(env)
+			env = append(env, v1.EnvVar{Name: i.Name, Value: i.Value})
+		}
+	}
+	var volumes []v1.Volume
+	var mounts []v1.VolumeMount
+	for count, i := range append(append([]v1alpha12.SecretMount{}, settings.SecretMounts...), recipe.SecretMounts...) {
+		name := "build-secret-" + strconv.Itoa(count)
+		volumes = append(volumes, v1.Volume{Name: name, VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: i.SecretName}}})
+		mounts = append(mounts, v1.VolumeMount{Name: name, MountPath: i.MountPath, ReadOnly: true})
+	}
+	return env, volumes, mounts, nil
+}
+
+func envAllowed(allowed []string, name string) bool {
+	for _, i := range allowed {
+		if i == name || (strings.HasSuffix(i, "*") && strings.HasPrefix(name, strings.TrimSuffix(i, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretMountDockerfileSection lists the secrets the build expects, they are not added to the diagnostic Dockerfile
+// so have to be provided when running it
+func secretMountDockerfileSection(jbsConfig *v1alpha12.JBSConfig, recipe *v1alpha12.BuildRecipe) string {
+	result := ""
+	for _, i := range append(append([]v1alpha12.SecretMount{}, jbsConfig.Spec.BuildSettings.SecretMounts...), recipe.SecretMounts...) {
+		result += "\n# The build expects secret " + i.SecretName + " to be mounted at " + i.MountPath
+	}
+	return result
+}
+
+// patchScript applies the recipe patches to the checkout in the given directory, with one command per line. The
+// diagnostic Dockerfile runs the same commands as a single RUN instruction, see patchDockerfileSection.
+func patchScript(recipe *v1alpha12.BuildRecipe, dir string) (string, error) {
+	if len(recipe.Patches) == 0 {
+		return "", nil
+	}
+	//every command is on its own line so the script stops at the first one that fails
+	script := "cd \"" + dir + "\"\nmkdir -p /tmp/patches\n"
+	for count, i := range recipe.Patches {
+		patchFile := "/tmp/patches/" + strconv.Itoa(count) + ".patch"
+		if i.Diff != "" {
+			script = script + "echo " + base64.StdEncoding.EncodeToString([]byte(i.Diff)) + " | base64 -d >" + patchFile + "\n"
+		} else if i.URL != "" && sha256Regex.MatchString(i.Sha256) {
+			script = script + "curl --fail --silent --show-error --location --output " + patchFile + " " + shellQuote(i.URL) + "\n" +
+				"echo '" + i.Sha256 + "  " + patchFile + "' | sha256sum -c -\n"
+		} else {
+			return "", fmt.Errorf("patch %d must have either a diff or a URL and sha256", count)
+		}
+		script = script + "git apply --verbose " + patchFile + " || { echo 'Patch " + strconv.Itoa(count) + " (" + patchDigests(recipe)[count] + ") does not apply cleanly'; exit 1; }\n"
+	}
+	return script, nil
+}
+
+// invalidRecipeError is returned by createPipelineSpec when the recipe cannot be turned into a pipeline. Running the
+// pipeline would only fail, so the build is failed instead.
+type invalidRecipeError struct {
+	problems []string
+}
+
+func (e *invalidRecipeError) Error() string {
+	return strings.Join(e.problems, "; ")
+}
+
+// shellQuote quotes a value so it is passed to a shell command as a single argument
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "'\\''") + "'"
+}
+
+// patchDigests returns the sha256 digests of the recipe patches, in the order they are applied
+func patchDigests(recipe *v1alpha12.BuildRecipe) []string {
+	digests := []string{}
+	for _, i := range recipe.Patches {
+		if i.Diff != "" {
+			digests = append(digests, "sha256:"+fmt.Sprintf("%x", sha256.Sum256([]byte(i.Diff))))
+		} else {
+			digests = append(digests, "sha256:"+i.Sha256)
+		}
+	}
+	return digests
+}
+
+// toolCacheScript links the configured tool caches into the tool cache workspace. The build script is shared with the
+// hermetic build, which does not have the workspace, so nothing is done if the workspace directory is not present.
+func toolCacheScript(jbsConfig *v1alpha12.JBSConfig) string {
+	if len(jbsConfig.Spec.BuildSettings.ToolCaches) == 0 {
+		return ""
+	}
+	cachePath := "$(workspaces." + WorkspaceToolCache + ".path)"
+	script := "if [ -d \"" + cachePath + "\" ]; then\n"
+	for _, i := range jbsConfig.Spec.BuildSettings.ToolCaches {
+		switch i {
+		case v1alpha12.ToolCacheGradle:
+			script += "    mkdir -p \"" + cachePath + "/gradle\"\n"
+			script += "    export GRADLE_USER_HOME_CACHE=\"" + cachePath + "/gradle\"\n"
+		case v1alpha12.ToolCacheMaven, v1alpha12.ToolCacheIvy:
+			script += "    mkdir -p \"" + cachePath + "/" + string(i) + "\"\n"
+			script += "    rm -rf \"$HOME/." + string(i) + "\" && ln -s \"" + cachePath + "/" + string(i) + "\" \"$HOME/." + string(i) + "\"\n"
+		}
+	}
+	script += "fi"
+	return script
 }
 
 func imageRegistryCommands(imageId string, recipe *v1alpha12.BuildRecipe, db *v1alpha12.DependencyBuild, jbsConfig *v1alpha12.JBSConfig, hermeticBuild bool, buildId string) ([]string, []string, []string, []string, []string) {
@@ -617,9 +1011,20 @@ func imageRegistryCommands(imageId string, recipe *v1alpha12.BuildRecipe, db *v1
 		"--build-id=" + buildId,
 		"--scm-uri=" + db.Spec.ScmInfo.SCMURL,
 		"--scm-commit=" + db.Spec.ScmInfo.CommitHash,
+		"--builder-image=" + recipe.Image,
+	}
+	if len(recipe.Patches) > 0 {
+		deployArgs = append(deployArgs, "--patches="+strings.Join(patchDigests(recipe), ","))
+	}
+	if allowed := allowedContaminantsArg(buildContaminantPolicy(jbsConfig.Spec.ContaminantPolicy, db)); allowed != "" {
+		//allowed contaminants are still deployed, the controller then records them as allowed
+		deployArgs = append(deployArgs, "--allowed-contaminants="+allowed)
+	}
+	if denied := deniedLicensesArg(jbsConfig.Spec.LicensePolicy); denied != "" {
+		deployArgs = append(deployArgs, "--denied-licenses="+denied)
 	}
 	hermeticDeployArgs := append([]string{}, deployArgs...)
-	hermeticDeployArgs = append(hermeticDeployArgs, "--image-id="+hermeticImageId)
+	hermeticDeployArgs = append(hermeticDeployArgs, "--image-id="+hermeticImageId, "--hermetic")
 	deployArgs = append(deployArgs, "--image-id="+imageId)
 
 	tagArgs := []string{
@@ -737,7 +1142,7 @@ func extractArrayParam(key string, paramValues []pipelinev1beta1.Param) []string
 func extractEnvVar(envVar []v1.EnvVar) string {
 	result := ""
 	for _, i := range envVar {
-		result += "export " + i.Name + "=" + i.Value + "\n"
+		result += "export " + i.Name + "='" + strings.ReplaceAll(i.Value, "'", "'\\''") + "'\n"
 	}
 	return result
 }
@@ -752,6 +1157,7 @@ func doSubstitution(script string, paramValues []pipelinev1beta1.Param, commitTi
 	script = strings.ReplaceAll(script, "$(workspaces.build-settings.path)", "/root/software/settings")
 	script = strings.ReplaceAll(script, "$(workspaces.source.path)", "/root/project")
 	script = strings.ReplaceAll(script, "$(workspaces.tls.path)", "/root/project/tls/service-ca.crt")
+	script = strings.ReplaceAll(script, "$(workspaces."+WorkspaceToolCache+".path)", "/root/tool-cache")
 	return script
 }
 
@@ -1,8 +1,19 @@
 package dependencybuild
 
 import (
-	. "github.com/onsi/gomega"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	v1 "k8s.io/api/core/v1"
 )
 
 func TestImageRegistryArrayToString(t *testing.T) {
@@ -21,3 +32,330 @@ func TestImageRegistryArrayToString(t *testing.T) {
 	imageId = prependTagToImage(imageId, prependTag)
 	g.Expect(imageId).To(Equal("quay.io/foo/artifact-deployments:123456_975ea3800099190263d38f051c1a188a975ea3800099190263d38f051c1a188a975ea3800099190263d38f051c1a188a975ea3800099190263d38f051"))
 }
+
+func TestGitArgs(t *testing.T) {
+	g := NewGomegaWithT(t)
+	db := &v1alpha1.DependencyBuild{}
+	db.Spec.ScmInfo.Path = "sub-module"
+	jbsConfig := &v1alpha1.JBSConfig{}
+
+	args := gitArgs(jbsConfig, db, &v1alpha1.BuildRecipe{}, "$(workspaces.source.path)/workspace")
+	g.Expect(args).Should(Equal("git clone $(params.URL) $(workspaces.source.path)/workspace && cd $(workspaces.source.path)/workspace && git reset --hard $(params.HASH) && git submodule init && git submodule update --recursive"))
+
+	jbsConfig.Spec.GitOptions = v1alpha1.GitCloneOptions{Depth: 1, LFS: true}
+	jbsConfig.Spec.GitMirrors = map[string]string{"https://github.com/": "https://mirror.example.com/github/"}
+	args = gitArgs(jbsConfig, db, &v1alpha1.BuildRecipe{GitOptions: &v1alpha1.GitCloneOptions{Depth: 10, SparseCheckout: true}, DisableSubmodules: true}, "$(workspaces.source.path)/workspace")
+	g.Expect(args).Should(HavePrefix("git config --global url.\"https://mirror.example.com/github/\".insteadOf \"https://github.com/\" && git init $(workspaces.source.path)/workspace"))
+	g.Expect(args).Should(ContainSubstring("(git fetch --depth=10 origin $(params.HASH) || git fetch --depth=10 origin tag $(params.TAG))"))
+	g.Expect(args).Should(HaveSuffix("git sparse-checkout set $(params.CONTEXT_DIR) && git reset --hard $(params.HASH) && git lfs install --local && git lfs pull"))
+
+	jbsConfig.Spec.GitOptions = v1alpha1.GitCloneOptions{SparseCheckout: true}
+	jbsConfig.Spec.GitMirrors = nil
+	args = gitArgs(jbsConfig, db, &v1alpha1.BuildRecipe{DisableSubmodules: true}, "$(workspaces.source.path)/workspace")
+	g.Expect(args).Should(Equal("git clone --no-checkout $(params.URL) $(workspaces.source.path)/workspace && cd $(workspaces.source.path)/workspace && git sparse-checkout set $(params.CONTEXT_DIR) && git reset --hard $(params.HASH)"))
+}
+
+func TestGitMirrorUrl(t *testing.T) {
+	g := NewGomegaWithT(t)
+	jbsConfig := &v1alpha1.JBSConfig{}
+	g.Expect(gitMirrorUrl(jbsConfig, "https://github.com/foo/bar.git")).Should(Equal("https://github.com/foo/bar.git"))
+	jbsConfig.Spec.GitMirrors = map[string]string{
+		"https://github.com/":     "https://mirror.example.com/github/",
+		"https://github.com/foo/": "git@git.example.com:foo/",
+	}
+	g.Expect(gitMirrorUrl(jbsConfig, "https://github.com/foo/bar.git")).Should(Equal("git@git.example.com:foo/bar.git"))
+	g.Expect(gitMirrorUrl(jbsConfig, "https://github.com/baz/bar.git")).Should(Equal("https://mirror.example.com/github/baz/bar.git"))
+	g.Expect(gitMirrorUrl(jbsConfig, "https://gitlab.com/baz/bar.git")).Should(Equal("https://gitlab.com/baz/bar.git"))
+}
+
+func TestScmProviders(t *testing.T) {
+	g := NewGomegaWithT(t)
+	db := &v1alpha1.DependencyBuild{}
+	db.Spec.ScmInfo.Private = true
+	jbsConfig := &v1alpha1.JBSConfig{}
+	recipe := &v1alpha1.BuildRecipe{}
+
+	_, err := scmProviderForType("cvs")
+	g.Expect(err).Should(HaveOccurred())
+
+	scm, err := scmProviderForType("")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(scm.checkoutScript(jbsConfig, db, recipe, "/src")).Should(ContainSubstring("git clone $(params.URL) /src"))
+	g.Expect(scm.credentials()[0].Name).Should(Equal("GIT_TOKEN"))
+
+	scm, err = scmProviderForType(ScmTypeHg)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	script := scm.checkoutScript(jbsConfig, db, recipe, "/src")
+	g.Expect(script).Should(ContainSubstring("$HG_PASSWORD"))
+	g.Expect(script).Should(ContainSubstring("hg clone --noupdate \"$(params.URL)\" /src && cd /src && hg update --clean --rev \"$(params.HASH)\""))
+	g.Expect(script).Should(ContainSubstring("git init"))
+	g.Expect(scm.credentials()[0].ValueFrom.SecretKeyRef.Name).Should(Equal(v1alpha1.HgSecretName))
+
+	scm, err = scmProviderForType(ScmTypeSvn)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(scm.checkoutScript(jbsConfig, db, recipe, "/src")).Should(HavePrefix("svn checkout --non-interactive --username \"$SVN_USERNAME\" --password \"$SVN_PASSWORD\" --no-auth-cache --revision \"$(params.HASH)\" \"$(params.URL)\" /src && cd /src"))
+	g.Expect(scm.credentials()[1].Name).Should(Equal("SVN_PASSWORD"))
+
+	scm, err = scmProviderForType(ScmTypeSourceArchive)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	script = scm.checkoutScript(jbsConfig, db, recipe, "/src")
+	g.Expect(script).Should(ContainSubstring("--user \"$ARCHIVE_USERNAME:$ARCHIVE_PASSWORD\""))
+	g.Expect(script).Should(ContainSubstring("echo \"$(params.HASH) /tmp/source-archive\" | sha256sum --check -"))
+	g.Expect(scm.credentials()[0].ValueFrom.SecretKeyRef.Name).Should(Equal(v1alpha1.SourceArchiveSecretName))
+}
+
+func TestScmCredentials(t *testing.T) {
+	g := NewGomegaWithT(t)
+	jbsConfig := &v1alpha1.JBSConfig{}
+	g.Expect(scmCredentialsEnv(jbsConfig)).Should(BeEmpty())
+	g.Expect(withScmCredentials(jbsConfig, "#!/bin/bash\necho hi")).Should(Equal("#!/bin/bash\necho hi"))
+
+	jbsConfig.Spec.ScmCredentials = []v1alpha1.ScmHostCredentials{
+		{Host: "gitlab.example.com", SecretName: "gitlab"},
+		{Host: "git.example.com", SecretName: "ssh-secret", Type: v1alpha1.ScmCredentialsTypeSsh},
+	}
+	env := scmCredentialsEnv(jbsConfig)
+	g.Expect(env).Should(HaveLen(4))
+	g.Expect(env[0].Name).Should(Equal("SCM_CREDENTIALS_0_USERNAME"))
+	g.Expect(env[1].ValueFrom.SecretKeyRef.Key).Should(Equal(v1alpha1.ScmSecretPasswordKey))
+	g.Expect(env[2].Name).Should(Equal("SCM_CREDENTIALS_1_SSH_KEY"))
+	g.Expect(env[2].ValueFrom.SecretKeyRef.Name).Should(Equal("ssh-secret"))
+	g.Expect(env[3].ValueFrom.SecretKeyRef.Key).Should(Equal(v1alpha1.ScmSecretKnownHostsKey))
+
+	script := withScmCredentials(jbsConfig, "#!/bin/bash\necho hi")
+	g.Expect(script).Should(HavePrefix("#!/bin/bash\n"))
+	g.Expect(script).Should(HaveSuffix("export GIT_TOKEN=\"$(cat $HOME/.git-credentials)\"\necho hi"))
+	g.Expect(script).Should(ContainSubstring("\"$(scm_urlencode \"$SCM_CREDENTIALS_0_USERNAME\")\" \"$(scm_urlencode \"$SCM_CREDENTIALS_0_PASSWORD\")\" 'gitlab.example.com' >> $HOME/.git-credentials"))
+	out, err := exec.Command("sh", "-c", script[strings.Index(script, "scm_urlencode() "):strings.Index(script, "\nmkdir")]+"\nscm_urlencode 'p@ss:w/rd% +'").Output()
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(string(out)).Should(Equal("p%40ss%3Aw%2Frd%25%20%2B"))
+	g.Expect(script).Should(ContainSubstring("'git.example.com' \"$HOME/.ssh/id_scm_1\" >> $HOME/.ssh/config"))
+	g.Expect(withScmCredentials(jbsConfig, "git clone")).Should(HaveSuffix("\ngit clone"))
+}
+
+func TestSignatureVerificationScript(t *testing.T) {
+	g := NewGomegaWithT(t)
+	script := signatureVerificationScript(&v1alpha1.SignatureVerificationPolicy{Type: v1alpha1.SignatureTypeGpg, KeyringConfigMap: "keys"})
+	g.Expect(script).Should(HavePrefix("gpg --batch --quiet --import /var/signature-keyring/*\n"))
+	g.Expect(script).Should(ContainSubstring("git verify-tag \"$(params.TAG)\""))
+	g.Expect(script).Should(ContainSubstring("elif git verify-commit \"$(params.HASH)\""))
+	g.Expect(script).Should(ContainSubstring("exit 65"))
+
+	script = signatureVerificationScript(&v1alpha1.SignatureVerificationPolicy{Type: v1alpha1.SignatureTypeSsh, KeyringConfigMap: "keys"})
+	g.Expect(script).Should(HavePrefix("git config --global gpg.ssh.allowedSignersFile /var/signature-keyring/allowed_signers\n"))
+	g.Expect(script).Should(ContainSubstring("echo -n \"ssh:commit\" > $(results.SIGNATURE_VERIFICATION.path)"))
+
+	g.Expect(parseSignatureVerificationResult("ssh:commit")).Should(Equal(&v1alpha1.SignatureVerificationResult{Verified: true, Type: v1alpha1.SignatureTypeSsh, SignedObject: "commit"}))
+}
+
+func TestPatchScript(t *testing.T) {
+	g := NewGomegaWithT(t)
+	script, err := patchScript(&v1alpha1.BuildRecipe{}, "/src")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(script).Should(BeEmpty())
+
+	sha := fmt.Sprintf("%x", sha256.Sum256([]byte("fix")))
+	recipe := &v1alpha1.BuildRecipe{Patches: []v1alpha1.BuildPatch{
+		{Diff: "--- a/pom.xml\n+++ b/pom.xml\n"},
+		{URL: "https://example.com/fix.patch?a=1&b='2'", Sha256: sha},
+	}}
+	digests := patchDigests(recipe)
+	g.Expect(digests).Should(Equal([]string{"sha256:" + fmt.Sprintf("%x", sha256.Sum256([]byte(recipe.Patches[0].Diff))), "sha256:" + sha}))
+	script, err = patchScript(recipe, "/src")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(strings.Split(script, "\n")).Should(Equal([]string{
+		`cd "/src"`,
+		"mkdir -p /tmp/patches",
+		"echo " + base64.StdEncoding.EncodeToString([]byte(recipe.Patches[0].Diff)) + " | base64 -d >/tmp/patches/0.patch",
+		"git apply --verbose /tmp/patches/0.patch || { echo 'Patch 0 (" + digests[0] + ") does not apply cleanly'; exit 1; }",
+		`curl --fail --silent --show-error --location --output /tmp/patches/1.patch 'https://example.com/fix.patch?a=1&b='\''2'\'''`,
+		"echo '" + sha + "  /tmp/patches/1.patch' | sha256sum -c -",
+		"git apply --verbose /tmp/patches/1.patch || { echo 'Patch 1 (sha256:" + sha + ") does not apply cleanly'; exit 1; }",
+		"",
+	}))
+	g.Expect(patchDockerfileSection(script, recipe, nil, 0, "")).Should(ContainSubstring("RUN set -e; cd \"/src\"; \\\n    mkdir -p /tmp/patches; \\\n"))
+
+	_, deployArgs, _, _, _ := imageRegistryCommands("id", recipe, &v1alpha1.DependencyBuild{}, &v1alpha1.JBSConfig{}, false, "build")
+	g.Expect(deployArgs).Should(ContainElement("--patches=" + strings.Join(digests, ",")))
+
+	_, err = patchScript(&v1alpha1.BuildRecipe{Patches: []v1alpha1.BuildPatch{{URL: "https://example.com/fix.patch"}}}, "/src")
+	g.Expect(err).Should(HaveOccurred())
+	_, err = patchScript(&v1alpha1.BuildRecipe{Patches: []v1alpha1.BuildPatch{{URL: "https://example.com/fix.patch", Sha256: "abc123; rm -rf /"}}}, "/src")
+	g.Expect(err).Should(HaveOccurred())
+}
+
+func TestAllowedContaminantsDeployArg(t *testing.T) {
+	g := NewGomegaWithT(t)
+	jbsConfig := &v1alpha1.JBSConfig{}
+	_, deployArgs, _, _, _ := imageRegistryCommands("id", &v1alpha1.BuildRecipe{}, &v1alpha1.DependencyBuild{}, jbsConfig, false, "build")
+	g.Expect(deployArgs).ShouldNot(ContainElement(HavePrefix("--allowed-contaminants")))
+
+	jbsConfig.Spec.ContaminantPolicy = &v1alpha1.ContaminantPolicy{AllowedContaminants: []v1alpha1.AllowedContaminant{{GAV: "com.acme:*"}, {GAV: "org.jboss:*", Artifact: "com.test:*"}, {Artifact: "ignored:*"}}}
+	_, deployArgs, hermeticDeployArgs, _, _ := imageRegistryCommands("id", &v1alpha1.BuildRecipe{}, &v1alpha1.DependencyBuild{}, jbsConfig, true, "build")
+	g.Expect(deployArgs).Should(ContainElement("--allowed-contaminants=com.acme:*,org.jboss:*@com.test:*"))
+	g.Expect(hermeticDeployArgs).Should(ContainElement("--allowed-contaminants=com.acme:*,org.jboss:*@com.test:*"))
+}
+
+func TestBuildEnvironment(t *testing.T) {
+	g := NewGomegaWithT(t)
+	jbsConfig := &v1alpha1.JBSConfig{}
+	jbsConfig.Spec.BuildSettings.Env = []v1alpha1.BuildEnvVar{{Name: "MAVEN_OPTS", Value: "-Xmx1g"}, {Name: "LANG", Value: "C"}}
+	jbsConfig.Spec.BuildSettings.SecretMounts = []v1alpha1.SecretMount{{SecretName: "settings", MountPath: "/etc/settings"}}
+	recipe := &v1alpha1.BuildRecipe{
+		Env:          []v1alpha1.BuildEnvVar{{Name: "MAVEN_OPTS", Value: "-Xmx2g -Dfoo=bar"}, {Name: "GRADLE_OPTS", Value: "-Dx"}},
+		SecretMounts: []v1alpha1.SecretMount{{SecretName: "signing-key", MountPath: "/etc/signing"}},
+	}
+
+	_, _, _, err := buildEnvironment(jbsConfig, recipe)
+	g.Expect(err).Should(MatchError("environment variable MAVEN_OPTS is not allowed by the JBSConfig"))
+	jbsConfig.Spec.BuildSettings.AllowedRecipeEnv = []string{"MAVEN_OPTS", "GRADLE_*"}
+	_, _, _, err = buildEnvironment(jbsConfig, recipe)
+	g.Expect(err).Should(MatchError("secret signing-key is not allowed by the JBSConfig"))
+	jbsConfig.Spec.BuildSettings.AllowedRecipeSecrets = []string{"signing-key"}
+
+	env, volumes, mounts, err := buildEnvironment(jbsConfig, recipe)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(env).Should(Equal([]v1.EnvVar{{Name: "MAVEN_OPTS", Value: "-Xmx2g -Dfoo=bar"}, {Name: "LANG", Value: "C"}, {Name: "GRADLE_OPTS", Value: "-Dx"}}))
+	g.Expect(volumes).Should(HaveLen(2))
+	g.Expect(volumes[1].Secret.SecretName).Should(Equal("signing-key"))
+	g.Expect(mounts[1]).Should(Equal(v1.VolumeMount{Name: "build-secret-1", MountPath: "/etc/signing", ReadOnly: true}))
+	g.Expect(extractEnvVar(env[:1])).Should(Equal("export MAVEN_OPTS='-Xmx2g -Dfoo=bar'\n"))
+	g.Expect(secretMountDockerfileSection(jbsConfig, recipe)).Should(ContainSubstring("# The build expects secret signing-key to be mounted at /etc/signing"))
+}
+
+func TestAdditionalDownloads(t *testing.T) {
+	g := NewGomegaWithT(t)
+	sha := strings.Repeat("a", 64)
+	g.Expect(validateAdditionalDownload(v1alpha1.AdditionalDownload{FileType: v1alpha1.AdditionalDownloadTypeRpm, PackageName: "glibc-devel"})).Should(Succeed())
+	g.Expect(validateAdditionalDownload(v1alpha1.AdditionalDownload{FileType: v1alpha1.AdditionalDownloadTypeTarGz, Uri: "https://example.com/tool.tar.gz", BinaryPath: "bin"})).Should(MatchError("missing or invalid sha256 for package https://example.com/tool.tar.gz"))
+	g.Expect(validateAdditionalDownload(v1alpha1.AdditionalDownload{FileType: v1alpha1.AdditionalDownloadTypeZip, Uri: "https://example.com/tool.zip", Sha256: sha, StripComponents: -1, BinaryPath: "bin"})).ShouldNot(Succeed())
+	g.Expect(validateAdditionalDownload(v1alpha1.AdditionalDownload{FileType: "deb", Uri: "https://example.com/tool.deb", Sha256: sha})).Should(MatchError("unknown file type deb for package https://example.com/tool.deb"))
+	g.Expect(validateAdditionalDownload(v1alpha1.AdditionalDownload{FileType: v1alpha1.AdditionalDownloadTypeMaven, FileName: "tool.jar", Gav: "org.example:tool", Sha256: sha})).Should(MatchError("invalid maven artifact org.example:tool"))
+	g.Expect(validateAdditionalDownload(v1alpha1.AdditionalDownload{FileType: v1alpha1.AdditionalDownloadTypeMaven, FileName: "tool.jar", Gav: "org.example:tool:1.0", Sha256: sha})).Should(Succeed())
+
+	path, err := mavenArtifactPath("org.example:tool:zip:dist:1.0")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(path).Should(Equal("org/example/tool/1.0/tool-1.0-dist.zip"))
+
+	script, err := additionalPackages(&v1alpha1.BuildRecipe{AdditionalDownloads: []v1alpha1.AdditionalDownload{
+		{FileType: v1alpha1.AdditionalDownloadTypeTarGz, Uri: "https://example.com/tool.tar.gz", Sha256: sha, BinaryPath: "bin", StripComponents: 1},
+		{FileType: v1alpha1.AdditionalDownloadTypeMaven, FileName: "tool.jar", Gav: "org.example:tool:1.0", Sha256: sha},
+	}})
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(script).Should(ContainSubstring("\"https://example.com/tool.tar.gz\""))
+	g.Expect(script).Should(ContainSubstring("echo \"" + sha + " "))
+	g.Expect(script).Should(ContainSubstring("--strip-components=1"))
+	g.Expect(script).Should(ContainSubstring("\"${CACHE_URL}/org/example/tool/1.0/tool-1.0.jar\""))
+	g.Expect(script).ShouldNot(MatchRegexp(`[^$]\{[A-Z_0-9]+\}`))
+
+	_, err = additionalPackages(&v1alpha1.BuildRecipe{AdditionalDownloads: []v1alpha1.AdditionalDownload{{FileType: v1alpha1.AdditionalDownloadTypeExecutable, Uri: "https://example.com/tool"}}})
+	g.Expect(err).Should(HaveOccurred())
+}
+
+func TestToolPaths(t *testing.T) {
+	g := NewGomegaWithT(t)
+	systemConfig := &v1alpha1.SystemConfig{Spec: v1alpha1.SystemConfigSpec{Builders: map[string]v1alpha1.BuilderImageInfo{
+		"jdk17": {Image: "quay.io/builder:17", Tag: "jdk:8;17,maven:3.9.5", ToolPaths: []v1alpha1.ToolPath{
+			{Tool: "jdk", Version: "8", Path: "/usr/lib/jvm/temurin-8"},
+			{Tool: "jdk", Version: "17", Path: "/usr/lib/jvm/temurin-17"},
+			{Tool: "maven", Version: "3.9.5", Path: "/usr/share/maven"},
+		}},
+	}}}
+	paths := builderToolPaths(systemConfig, "quay.io/builder:17")
+	g.Expect(toolHome(paths, "jdk", "17")).Should(Equal("/usr/lib/jvm/temurin-17"))
+	g.Expect(toolHome(paths, "maven", "3.9.5")).Should(Equal("/usr/share/maven"))
+	g.Expect(toolHome(paths, "gradle", "8.0.2")).Should(Equal("/opt/gradle/8.0.2"))
+	g.Expect(toolHome(nil, "jdk", "8")).Should(Equal("/lib/jvm/java-1.8.0"))
+	g.Expect(toolHome(nil, "jdk", "11")).Should(Equal("/lib/jvm/java-11"))
+
+	jdks := jdkToolchains(paths, "17")
+	g.Expect(mavenToolchains(jdks)).Should(ContainSubstring("<version>1.8</version>\n    </provides>\n    <configuration>\n      <jdkHome>/usr/lib/jvm/temurin-8</jdkHome>"))
+	g.Expect(gradleJavaInstallations(jdks)).Should(Equal("/usr/lib/jvm/temurin-8,/usr/lib/jvm/temurin-17"))
+	g.Expect(gradleJavaInstallations(jdkToolchains(nil, "8"))).Should(Equal("/usr/lib/jvm/java-1.8.0-openjdk,/usr/lib/jvm/java-11-openjdk,/usr/lib/jvm/java-17-openjdk"))
+	g.Expect(mavenToolchains(jdkToolchains(nil, "7"))).Should(ContainSubstring("<version>1.7</version>"))
+
+	systemConfig.Spec.Builders["jdk11"] = v1alpha1.BuilderImageInfo{Image: "quay.io/builder:11", Capabilities: []v1alpha1.BuilderCapability{{Tool: "jdk", Versions: []string{"11"}, InstallPath: "/opt/jdk-{version}"}}}
+	g.Expect(toolHome(builderToolPaths(systemConfig, "quay.io/builder:11"), "jdk", "11")).Should(Equal("/opt/jdk-11"))
+}
+
+func TestStepResources(t *testing.T) {
+	g := NewGomegaWithT(t)
+	jbsConfig := &v1alpha1.JBSConfig{}
+	jbsConfig.Spec.BuildSettings.TaskLimitMemory = "768Mi"
+	jbsConfig.Spec.BuildSettings.StepResources = map[string]v1alpha1.StepResources{"tag": {RequestMemory: "256Mi", LimitMemory: "256Mi"}}
+	resources, err := stepResources(jbsConfig, "", 200)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	for name, i := range resources {
+		g.Expect(i.Requests).Should(HaveLen(2), name)
+		g.Expect(i.Limits).Should(HaveLen(2), name)
+	}
+	preprocessor, hermetic, tag := resources["preprocessor"], resources["hermetic-build"], resources["tag"]
+	g.Expect(preprocessor.Requests.Memory().String()).Should(Equal("712Mi"))
+	g.Expect(preprocessor.Limits.Memory().String()).Should(Equal("968Mi"))
+	g.Expect(hermetic.Limits.Memory().String()).Should(Equal("1224Mi"))
+	g.Expect(tag.Requests.Memory().String()).Should(Equal("256Mi"))
+	g.Expect(tag.Limits.Cpu().String()).Should(Equal("300m"))
+}
+
+func TestReproducibleBuildEnvironment(t *testing.T) {
+	g := NewGomegaWithT(t)
+	env, umask, err := reproducibleBuildEnvironment(&v1alpha1.BuildRecipe{}, 1690000000123)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(umask).Should(Equal("0022"))
+	g.Expect(env).Should(ConsistOf(
+		v1.EnvVar{Name: "LANG", Value: "en_US.UTF-8"},
+		v1.EnvVar{Name: "LC_ALL", Value: "en_US.UTF-8"},
+		v1.EnvVar{Name: "TZ", Value: "UTC"},
+		v1.EnvVar{Name: "SOURCE_DATE_EPOCH", Value: "1690000000"},
+		v1.EnvVar{Name: "OUTPUT_TIMESTAMP", Value: "1690000000"},
+	))
+
+	recipe := &v1alpha1.BuildRecipe{Reproducible: &v1alpha1.ReproducibleBuildSettings{Locale: "C.UTF-8", TimeZone: "Europe/Prague", Umask: "002", DisableOutputTimestamp: true}}
+	env, umask, err = reproducibleBuildEnvironment(recipe, 1690000000123)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(umask).Should(Equal("002"))
+	g.Expect(env).Should(ContainElements(v1.EnvVar{Name: "LC_ALL", Value: "C.UTF-8"}, v1.EnvVar{Name: "TZ", Value: "Europe/Prague"}, v1.EnvVar{Name: "SOURCE_DATE_EPOCH", Value: "1690000000"}))
+	g.Expect(env).ShouldNot(ContainElement(HaveField("Name", "OUTPUT_TIMESTAMP")))
+
+	//without a commit time there is nothing stable to use
+	env, _, _ = reproducibleBuildEnvironment(&v1alpha1.BuildRecipe{}, 0)
+	g.Expect(env).ShouldNot(ContainElement(HaveField("Name", "SOURCE_DATE_EPOCH")))
+
+	_, _, err = reproducibleBuildEnvironment(&v1alpha1.BuildRecipe{Reproducible: &v1alpha1.ReproducibleBuildSettings{Umask: "0022; rm -rf /"}}, 0)
+	g.Expect(err).Should(MatchError("invalid umask 0022; rm -rf /"))
+}
+
+func TestReproducibilityCheck(t *testing.T) {
+	g := NewGomegaWithT(t)
+	jbsConfig := &v1alpha1.JBSConfig{}
+	g.Expect(reproducibilityCheckEnabled(jbsConfig, &v1alpha1.BuildRecipe{})).Should(BeFalse())
+	g.Expect(reproducibilityCheckEnabled(jbsConfig, &v1alpha1.BuildRecipe{Reproducible: &v1alpha1.ReproducibleBuildSettings{Check: true}})).Should(BeTrue())
+	jbsConfig.Spec.ReproducibilityCheck = &v1alpha1.ReproducibilityCheck{}
+	g.Expect(reproducibilityCheckEnabled(jbsConfig, &v1alpha1.BuildRecipe{})).Should(BeTrue())
+
+	step := compareBuildsStepSpec("quay.io/redhat-appstudio/build-request-processor:latest", true, v1.ResourceRequirements{})
+	g.Expect(step.Name).Should(Equal(compareBuildsStep))
+	g.Expect(step.Env).Should(ContainElement(v1.EnvVar{Name: firstBuildChecksums, Value: "$(params.FIRST_BUILD_CHECKSUMS)"}))
+	g.Expect(step.Script).Should(ContainSubstring("$(results.REPRODUCIBILITY.path)"))
+	g.Expect(step.Script).ShouldNot(MatchRegexp(`[^$]\{[A-Z_0-9]+\}`))
+	result := filepath.Join(t.TempDir(), "result")
+	cmd := exec.Command("bash", "-c", strings.ReplaceAll(step.Script, "$(results.REPRODUCIBILITY.path)", result))
+	cmd.Env = []string{
+		"PATH=" + os.Getenv("PATH"),
+		firstBuildChecksums + "=aaaa  com/test/test/1.0/test-1.0.jar\nbbbb  com/test/test/1.0/test-1.0-tests.jar\ncccc  com/test/test/1.0/test-1.0.pom",
+		secondBuildChecksums + "=dddd  com/test/test/1.0/test-1.0.jar\ncccc  com/test/test/1.0/test-1.0.pom",
+	}
+	err := cmd.Run()
+	g.Expect(err).Should(HaveOccurred())
+	g.Expect(err.(*exec.ExitError).ExitCode()).Should(Equal(NotReproducibleExitCode))
+	out, err := os.ReadFile(result)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(string(out)).Should(Equal("2\nOnlyInFirstBuild com/test/test/1.0/test-1.0-tests.jar\nChecksumMismatch com/test/test/1.0/test-1.0.jar\n"))
+
+	total, differences, err := parseReproducibilityResult("0\n")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(total).Should(Equal(0))
+	g.Expect(differences).Should(BeEmpty())
+	_, _, err = parseReproducibilityResult("")
+	g.Expect(err).Should(HaveOccurred())
+}
@@ -0,0 +1,123 @@
+package dependencybuild
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// applyContaminantPolicy moves the contaminants the JBSConfig contaminant policy allows into the allowed contaminants
+// of the DependencyBuild, so they are recorded but do not stop the build completing or trigger ArtifactBuilds
+func (r *ReconcileDependencyBuild) applyContaminantPolicy(ctx context.Context, log logr.Logger, db *v1alpha1.DependencyBuild) error {
+	db.Status.AllowedContaminants = nil
+	if len(db.Status.Contaminants) == 0 {
+		return nil
+	}
+	jbsConfig := &v1alpha1.JBSConfig{}
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: db.Namespace, Name: v1alpha1.JBSConfigName}, jbsConfig)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	policy := buildContaminantPolicy(jbsConfig.Spec.ContaminantPolicy, db)
+	if len(policy.AllowedContaminants) == 0 {
+		return nil
+	}
+	remaining, allowed := splitContaminants(policy, db.Status.Contaminants)
+	if len(allowed) == 0 {
+		return nil
+	}
+	db.Status.Contaminants = remaining
+	db.Status.AllowedContaminants = allowed
+	parts := []string{}
+	for _, i := range allowed {
+		parts = append(parts, fmt.Sprintf("%s (%s)", i.GAV, strings.Join(i.ContaminatedArtifacts, ", ")))
+	}
+	log.Info("Contaminants allowed by the contaminant policy", "build", db.Name, "allowed", parts)
+	r.eventRecorder.Eventf(db, v1.EventTypeNormal, "ContaminantsAllowed", "The DependencyBuild %s/%s contains allowed contaminants: %s", db.Namespace, db.Name, strings.Join(parts, ", "))
+	return nil
+}
+
+// buildContaminantPolicy returns the JBSConfig contaminant policy with the contaminants that were allowed to break a
+// contamination cycle of this build added to it
+func buildContaminantPolicy(policy *v1alpha1.ContaminantPolicy, db *v1alpha1.DependencyBuild) *v1alpha1.ContaminantPolicy {
+	ret := &v1alpha1.ContaminantPolicy{}
+	if policy != nil {
+		ret = policy.DeepCopy()
+	}
+	for _, gav := range db.Status.CycleAllowedContaminants {
+		ret.AllowedContaminants = append(ret.AllowedContaminants, v1alpha1.AllowedContaminant{GAV: gav})
+	}
+	return ret
+}
+
+// splitContaminants splits the contaminated artifacts of each contaminant into the ones that are still contaminated
+// and the ones where the contaminant is allowed
+func splitContaminants(policy *v1alpha1.ContaminantPolicy, contaminants []v1alpha1.Contaminant) ([]v1alpha1.Contaminant, []v1alpha1.Contaminant) {
+	var remaining []v1alpha1.Contaminant
+	var allowed []v1alpha1.Contaminant
+	for _, contaminant := range contaminants {
+		var contaminated []string
+		var accepted []string
+		for _, artifact := range contaminant.ContaminatedArtifacts {
+			if contaminantAllowed(policy, contaminant.GAV, artifact) {
+				accepted = append(accepted, artifact)
+			} else {
+				contaminated = append(contaminated, artifact)
+			}
+		}
+		if len(accepted) > 0 {
+			allowed = append(allowed, v1alpha1.Contaminant{GAV: contaminant.GAV, ContaminatedArtifacts: accepted})
+		}
+		//a contaminant with no contaminated artifacts is kept as is, as it was reported without them
+		if len(contaminated) > 0 || len(accepted) == 0 {
+			remaining = append(remaining, v1alpha1.Contaminant{GAV: contaminant.GAV, ContaminatedArtifacts: contaminated})
+		}
+	}
+	return remaining, allowed
+}
+
+func contaminantAllowed(policy *v1alpha1.ContaminantPolicy, gav string, artifact string) bool {
+	for _, i := range policy.AllowedContaminants {
+		if i.GAV == "" || !globMatches(i.GAV, gav) {
+			continue
+		}
+		if i.Artifact == "" || globMatches(i.Artifact, artifact) {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatches matches a GAV against a glob pattern, as GAVs do not contain a '/' a '*' matches any part of the GAV.
+// An invalid pattern does not match anything.
+func globMatches(pattern string, gav string) bool {
+	matched, err := path.Match(pattern, gav)
+	return err == nil && matched
+}
+
+// allowedContaminantsArg formats the contaminant policy for the deploy step, as contaminant-pattern[@artifact-pattern]
+// separated by commas
+func allowedContaminantsArg(policy *v1alpha1.ContaminantPolicy) string {
+	if policy == nil {
+		return ""
+	}
+	var ret []string
+	for _, i := range policy.AllowedContaminants {
+		if i.GAV == "" {
+			continue
+		}
+		if i.Artifact == "" {
+			ret = append(ret, i.GAV)
+		} else {
+			ret = append(ret, i.GAV+"@"+i.Artifact)
+		}
+	}
+	return strings.Join(ret, ",")
+}
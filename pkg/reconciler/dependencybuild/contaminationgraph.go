@@ -0,0 +1,284 @@
+package dependencybuild
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// contaminationGraph is the result of walking from the contaminants of a DependencyBuild through the ArtifactBuilds
+// rebuilding them, and the DependencyBuilds of those, to find everything the build is waiting for
+type contaminationGraph struct {
+	nodes map[string]*v1alpha1.ContaminationNode
+	// each cycle is the chain of contaminant GAVs from the build to the contaminant that closes the cycle
+	cycles [][]string
+	// the direct contaminants of the build that lead back to it
+	cyclic map[string]bool
+	// the contaminants that can't be rebuilt, and why
+	unresolvable map[string]string
+}
+
+type contaminationWalker struct {
+	ctx    context.Context
+	client client.Client
+	root   *v1alpha1.DependencyBuild
+	graph  *contaminationGraph
+	// the contaminated DependencyBuild each contaminant is waiting for, if there is one
+	blockedBuild map[string]string
+	// the contaminants of the contaminated DependencyBuilds
+	contaminants map[string][]v1alpha1.Contaminant
+	// the DependencyBuilds that have been walked, and if they lead back to the root
+	walked map[string]bool
+	// the DependencyBuilds that are currently being walked
+	walking map[string]bool
+}
+
+func (r *ReconcileDependencyBuild) buildContaminationGraph(ctx context.Context, db *v1alpha1.DependencyBuild) (*contaminationGraph, error) {
+	w := contaminationWalker{
+		ctx:          ctx,
+		client:       r.client,
+		root:         db,
+		graph:        &contaminationGraph{nodes: map[string]*v1alpha1.ContaminationNode{}, cyclic: map[string]bool{}, unresolvable: map[string]string{}},
+		blockedBuild: map[string]string{},
+		contaminants: map[string][]v1alpha1.Contaminant{},
+		walked:       map[string]bool{},
+		walking:      map[string]bool{},
+	}
+	_, err := w.walk(db.Name, db.Status.Contaminants, nil)
+	return w.graph, err
+}
+
+// walk visits the contaminants of a DependencyBuild, returning true if any of them lead back to the root build
+func (w *contaminationWalker) walk(name string, contaminants []v1alpha1.Contaminant, path []string) (bool, error) {
+	w.walking[name] = true
+	defer delete(w.walking, name)
+	reachesRoot := false
+	for _, contaminant := range contaminants {
+		chain := append(append([]string{}, path...), contaminant.GAV)
+		found, err := w.visit(contaminant.GAV, chain)
+		if err != nil {
+			return false, err
+		}
+		if found {
+			reachesRoot = true
+			if len(path) == 0 {
+				w.graph.cyclic[contaminant.GAV] = true
+			}
+		}
+	}
+	return reachesRoot, nil
+}
+
+// visit adds the node for a contaminant, and if its DependencyBuild is also contaminated walks that build's
+// contaminants. It returns true if the contaminant leads back to the root build.
+func (w *contaminationWalker) visit(gav string, chain []string) (bool, error) {
+	if w.graph.nodes[gav] == nil {
+		if err := w.addNode(gav); err != nil {
+			return false, err
+		}
+	}
+	name := w.blockedBuild[gav]
+	switch {
+	case name == "":
+		return false, nil
+	case name == w.root.Name:
+		w.graph.cycles = append(w.graph.cycles, chain)
+		return true, nil
+	case w.walking[name]:
+		//a cycle the root build is waiting on, but is not part of
+		w.graph.cycles = append(w.graph.cycles, chain)
+		return false, nil
+	}
+	if reachesRoot, ok := w.walked[name]; ok {
+		return reachesRoot, nil
+	}
+	reachesRoot, err := w.walk(name, w.contaminants[name], chain)
+	if err != nil {
+		return false, err
+	}
+	w.walked[name] = reachesRoot
+	return reachesRoot, nil
+}
+
+func (w *contaminationWalker) addNode(gav string) error {
+	node := &v1alpha1.ContaminationNode{GAV: gav, ArtifactBuild: artifactbuild.CreateABRName(gav)}
+	w.graph.nodes[gav] = node
+	abr := v1alpha1.ArtifactBuild{}
+	err := w.client.Get(w.ctx, types.NamespacedName{Namespace: w.root.Namespace, Name: node.ArtifactBuild}, &abr)
+	if errors.IsNotFound(err) {
+		node.ArtifactBuild = ""
+		w.graph.unresolvable[gav] = "no ArtifactBuild is rebuilding it"
+		return nil
+	} else if err != nil {
+		return err
+	}
+	node.ArtifactBuildState = abr.Status.State
+	switch abr.Status.State {
+	case v1alpha1.ArtifactBuildStateMissing, v1alpha1.ArtifactBuildStateFailed:
+		w.graph.unresolvable[gav] = fmt.Sprintf("ArtifactBuild %s is %s", abr.Name, abr.Status.State)
+		return nil
+	case v1alpha1.ArtifactBuildStateComplete:
+		return nil
+	}
+	if abr.Status.SCMInfo.SCMURL == "" {
+		//the source has not been discovered yet
+		return nil
+	}
+	db := v1alpha1.DependencyBuild{}
+	name := util.HashString(abr.Status.SCMInfo.SCMURL + abr.Status.SCMInfo.Tag + abr.Status.SCMInfo.Path)
+	err = w.client.Get(w.ctx, types.NamespacedName{Namespace: w.root.Namespace, Name: name}, &db)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	node.DependencyBuild = db.Name
+	node.DependencyBuildState = db.Status.State
+	switch db.Status.State {
+	case v1alpha1.DependencyBuildStateFailed:
+		w.graph.unresolvable[gav] = fmt.Sprintf("DependencyBuild %s has failed", db.Name)
+	case v1alpha1.DependencyBuildStateContaminated:
+		for _, i := range db.Status.Contaminants {
+			node.BlockedBy = append(node.BlockedBy, i.GAV)
+		}
+		w.blockedBuild[gav] = db.Name
+		w.contaminants[db.Name] = db.Status.Contaminants
+	}
+	return nil
+}
+
+func (g *contaminationGraph) sortedNodes() []v1alpha1.ContaminationNode {
+	var ret []v1alpha1.ContaminationNode
+	for _, node := range g.nodes {
+		ret = append(ret, *node)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].GAV < ret[j].GAV
+	})
+	return ret
+}
+
+// updateContaminationGraph records what a contaminated build is waiting for, and surfaces cycles and contaminants that
+// can't be rebuilt as conditions. If the JBSConfig contaminant policy allows it cycles are broken by accepting the
+// contaminants that lead back to the build.
+func (r *ReconcileDependencyBuild) updateContaminationGraph(ctx context.Context, log logr.Logger, db *v1alpha1.DependencyBuild) (reconcile.Result, error) {
+	original := db.Status.DeepCopy()
+	graph, err := r.buildContaminationGraph(ctx, db)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	db.Status.ContaminationGraph = graph.sortedNodes()
+
+	if len(graph.cycles) == 0 {
+		meta.SetStatusCondition(&db.Status.Conditions, v12.Condition{Type: v1alpha1.DependencyBuildConditionContaminationCycle, Status: v12.ConditionFalse, Reason: "NoCycle", Message: "The contaminants do not form a cycle"})
+	} else {
+		cycles := []string{}
+		for _, i := range graph.cycles {
+			cycles = append(cycles, strings.Join(i, " -> "))
+		}
+		message := "The contaminants form a cycle: " + strings.Join(cycles, ", ")
+		breakCycles, err := r.breakContaminationCycles(ctx, db)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if breakCycles && len(graph.cyclic) > 0 {
+			r.breakContaminationCycle(log, db, graph, message)
+			return reconcile.Result{}, r.client.Status().Update(ctx, db)
+		}
+		if !meta.IsStatusConditionTrue(db.Status.Conditions, v1alpha1.DependencyBuildConditionContaminationCycle) {
+			r.eventRecorder.Eventf(db, v1.EventTypeWarning, "ContaminationCycle", "The DependencyBuild %s/%s is waiting for a contamination cycle: %s", db.Namespace, db.Name, strings.Join(cycles, ", "))
+		}
+		meta.SetStatusCondition(&db.Status.Conditions, v12.Condition{Type: v1alpha1.DependencyBuildConditionContaminationCycle, Status: v12.ConditionTrue, Reason: "CycleDetected", Message: message})
+	}
+
+	if len(graph.unresolvable) == 0 {
+		meta.SetStatusCondition(&db.Status.Conditions, v12.Condition{Type: v1alpha1.DependencyBuildConditionContaminationResolvable, Status: v12.ConditionTrue, Reason: "ContaminantsBuilding", Message: "The contaminants are being rebuilt"})
+	} else {
+		gavs := []string{}
+		for gav := range graph.unresolvable {
+			gavs = append(gavs, gav)
+		}
+		sort.Strings(gavs)
+		parts := []string{}
+		for _, gav := range gavs {
+			parts = append(parts, fmt.Sprintf("%s (%s)", gav, graph.unresolvable[gav]))
+		}
+		if cond := meta.FindStatusCondition(db.Status.Conditions, v1alpha1.DependencyBuildConditionContaminationResolvable); cond == nil || cond.Status != v12.ConditionFalse {
+			r.eventRecorder.Eventf(db, v1.EventTypeWarning, "UnresolvableContaminants", "The DependencyBuild %s/%s is waiting for contaminants that can't be rebuilt: %s", db.Namespace, db.Name, strings.Join(parts, ", "))
+		}
+		meta.SetStatusCondition(&db.Status.Conditions, v12.Condition{Type: v1alpha1.DependencyBuildConditionContaminationResolvable, Status: v12.ConditionFalse, Reason: "UnresolvableContaminants", Message: "Contaminants can't be rebuilt: " + strings.Join(parts, ", ")})
+	}
+
+	if reflect.DeepEqual(original, &db.Status) {
+		return reconcile.Result{}, nil
+	}
+	return reconcile.Result{}, r.client.Status().Update(ctx, db)
+}
+
+func (r *ReconcileDependencyBuild) breakContaminationCycles(ctx context.Context, db *v1alpha1.DependencyBuild) (bool, error) {
+	jbsConfig := &v1alpha1.JBSConfig{}
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: db.Namespace, Name: v1alpha1.JBSConfigName}, jbsConfig)
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return jbsConfig.Spec.ContaminantPolicy != nil && jbsConfig.Spec.ContaminantPolicy.BreakCycles, nil
+}
+
+// breakContaminationCycle allows the contaminants that lead back to the build. The deploy step removed the artifacts
+// they contaminate, so the build has to run again with them allowed before it can complete. If it is still waiting
+// for other contaminants it is run again once they are rebuilt.
+func (r *ReconcileDependencyBuild) breakContaminationCycle(log logr.Logger, db *v1alpha1.DependencyBuild, graph *contaminationGraph, message string) {
+	var remaining []v1alpha1.Contaminant
+	var allowed []string
+	existing := map[string]bool{}
+	for _, gav := range db.Status.CycleAllowedContaminants {
+		existing[gav] = true
+	}
+	for _, i := range db.Status.Contaminants {
+		if graph.cyclic[i.GAV] {
+			allowed = append(allowed, i.GAV)
+			if !existing[i.GAV] {
+				db.Status.CycleAllowedContaminants = append(db.Status.CycleAllowedContaminants, i.GAV)
+			}
+		} else {
+			remaining = append(remaining, i)
+		}
+	}
+	db.Status.Contaminants = remaining
+	meta.SetStatusCondition(&db.Status.Conditions, v12.Condition{Type: v1alpha1.DependencyBuildConditionContaminationCycle, Status: v12.ConditionTrue, Reason: "CycleBroken", Message: message + ", the contaminants leading back to this build were allowed by the contaminant policy"})
+	r.eventRecorder.Eventf(db, v1.EventTypeWarning, "ContaminationCycleBroken", "The DependencyBuild %s/%s allowed the contaminants %s to break a contamination cycle", db.Namespace, db.Name, strings.Join(allowed, ", "))
+	log.Info("Breaking contamination cycle", "build", db.Name, "allowed", allowed)
+	if len(remaining) == 0 {
+		db.Status.ContaminationGraph = nil
+		meta.RemoveStatusCondition(&db.Status.Conditions, v1alpha1.DependencyBuildConditionContaminationResolvable)
+		db.Status.State = v1alpha1.DependencyBuildStateNew
+	}
+}
+
+// contaminatedBuildRequests returns the DependencyBuilds that are contaminated by the artifact the ArtifactBuild
+// is rebuilding, so they can update their contamination graph when it changes
+func contaminatedBuildRequests(abr *v1alpha1.ArtifactBuild) []reconcile.Request {
+	var ret []reconcile.Request
+	for key, value := range abr.Annotations {
+		if strings.HasPrefix(key, artifactbuild.DependencyBuildContaminatedByAnnotation) {
+			ret = append(ret, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: abr.Namespace, Name: value}})
+		}
+	}
+	return ret
+}
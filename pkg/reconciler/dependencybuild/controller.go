@@ -1,6 +1,8 @@
 package dependencybuild
 
 import (
+	"context"
+
 	"github.com/tektoncd/cli/pkg/cli"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
@@ -67,5 +69,27 @@ func SetupNewReconcilerWithManager(mgr ctrl.Manager, params *cli.TektonParams) e
 				},
 			}
 		})).
+		Watches(&source.Kind{Type: &v1alpha1.ArtifactBuild{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+			//the contamination graph of the builds contaminated by this artifact may have changed
+			return contaminatedBuildRequests(o.(*v1alpha1.ArtifactBuild))
+		})).
+		Watches(&source.Kind{Type: &v1alpha1.DependencyBuild{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+			//a change to a build rebuilding contaminants may change the graph of the builds waiting on it
+			var ret []reconcile.Request
+			for _, ownerRef := range o.GetOwnerReferences() {
+				if ownerRef.Kind != "ArtifactBuild" {
+					continue
+				}
+				abr := v1alpha1.ArtifactBuild{}
+				if err := mgr.GetClient().Get(context.Background(), types.NamespacedName{Namespace: o.GetNamespace(), Name: ownerRef.Name}, &abr); err == nil {
+					ret = append(ret, contaminatedBuildRequests(&abr)...)
+				}
+			}
+			return ret
+		})).
+		Watches(&source.Kind{Type: &v1alpha1.SystemConfig{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+			//new builder images may have the tools builds were missing
+			return unmatchedBuildRequests(context.Background(), mgr.GetClient())
+		})).
 		Complete(r)
 }
@@ -68,6 +68,10 @@ const (
 
 	PipelineRunFinalizer = "jvmbuildservice.io/finalizer"
 	JavaHome             = "JAVA_HOME"
+
+	ToolCacheSuffix = "-tool-cache"
+
+	lookupBuildInfoSourcePath = "/var/source"
 )
 
 type ReconcileDependencyBuild struct {
@@ -131,13 +135,13 @@ func (r *ReconcileDependencyBuild) Reconcile(ctx context.Context, request reconc
 		case v1alpha1.DependencyBuildStateSubmitBuild:
 			return r.handleStateSubmitBuild(ctx, &db)
 		case v1alpha1.DependencyBuildStateFailed:
-			return reconcile.Result{}, nil
+			return r.handleStateFailed(ctx, log, &db)
 		case v1alpha1.DependencyBuildStateBuilding:
 			return r.handleStateBuilding(ctx, log, &db)
 		case v1alpha1.DependencyBuildStateContaminated:
-			return r.handleStateContaminated(ctx, &db)
+			return r.handleStateContaminated(ctx, log, &db)
 		case v1alpha1.DependencyBuildStateComplete:
-			return reconcile.Result{}, nil
+			return reconcile.Result{}, r.removeToolCache(ctx, &db)
 		}
 
 	case trerr == nil:
@@ -174,11 +178,24 @@ func (r *ReconcileDependencyBuild) Reconcile(ctx context.Context, request reconc
 }
 
 func (r *ReconcileDependencyBuild) handleStateNew(ctx context.Context, log logr.Logger, db *v1alpha1.DependencyBuild) (reconcile.Result, error) {
+	if _, err := scmProviderForType(db.Spec.ScmInfo.SCMType); err != nil {
+		db.Status.State = v1alpha1.DependencyBuildStateFailed
+		db.Status.Message = err.Error()
+		r.eventRecorder.Eventf(db, v1.EventTypeWarning, "UnsupportedScmType", "The DependencyBuild %s/%s has an unsupported SCM type %s", db.Namespace, db.Name, db.Spec.ScmInfo.SCMType)
+		return reconcile.Result{}, r.client.Status().Update(ctx, db)
+	}
 	jbsConfig := &v1alpha1.JBSConfig{}
 	err := r.client.Get(ctx, types.NamespacedName{Namespace: db.Namespace, Name: v1alpha1.JBSConfigName}, jbsConfig)
 	if err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
+	if jbsConfig.Spec.SignatureVerification != nil && db.Spec.ScmInfo.SCMType != "" && db.Spec.ScmInfo.SCMType != ScmTypeGit {
+		db.Status.State = v1alpha1.DependencyBuildStateFailed
+		db.Status.FailureReason = v1alpha1.DependencyBuildFailureReasonSignatureVerification
+		db.Status.Message = fmt.Sprintf("signature verification is required but is not supported for SCM type %s", db.Spec.ScmInfo.SCMType)
+		r.eventRecorder.Eventf(db, v1.EventTypeWarning, v1alpha1.DependencyBuildFailureReasonSignatureVerification, "The DependencyBuild %s/%s cannot be verified as it uses SCM type %s", db.Namespace, db.Name, db.Spec.ScmInfo.SCMType)
+		return reconcile.Result{}, r.client.Status().Update(ctx, db)
+	}
 	// create pipeline run
 	pr := pipelinev1beta1.PipelineRun{}
 	pr.Finalizers = []string{PipelineRunFinalizer}
@@ -309,7 +326,6 @@ func (r *ReconcileDependencyBuild) handleAnalyzeBuildPipelineRunReceived(ctx con
 			db.Status.Message = "failed to unmarshal json build info: " + err.Error() + ": " + buildInfo
 			return reconcile.Result{}, r.client.Status().Update(ctx, &db)
 		}
-
 		//read our builder images from the config
 		var allBuilderImages []BuilderImage
 		allBuilderImages, err = r.processBuilderImages(ctx, log)
@@ -319,6 +335,7 @@ func (r *ReconcileDependencyBuild) handleAnalyzeBuildPipelineRunReceived(ctx con
 		// for now we are ignoring the tool versions
 		// and just using the supplied invocations
 		buildRecipes := []*v1alpha1.BuildRecipe{}
+		unmatchedRecipes := []*v1alpha1.BuildRecipe{}
 		db.Status.CommitTime = unmarshalled.CommitTime
 
 		if len(unmarshalled.Invocations) == 0 {
@@ -327,35 +344,54 @@ func (r *ReconcileDependencyBuild) handleAnalyzeBuildPipelineRunReceived(ctx con
 			return reconcile.Result{}, r.client.Status().Update(ctx, &db)
 		}
 		for _, command := range unmarshalled.Invocations {
-			//loop through the builder images to find one that meets all the requirements
-			//if there is no match then we ignore the combo
-			for _, image := range allBuilderImages {
-				imageOk := true
-				for tool, version := range command.ToolVersion {
-					versions, exists := image.Tools[tool]
-					if !exists {
-						imageOk = false
-						break
-					}
-					if !slices.Contains(versions, version) {
-						imageOk = false
-						break
-					}
-				}
-				if imageOk {
-					buildRecipes = append(buildRecipes, &v1alpha1.BuildRecipe{Image: image.Image, CommandLine: command.Commands, EnforceVersion: unmarshalled.EnforceVersion, ToolVersion: command.ToolVersion[command.Tool], ToolVersions: command.ToolVersion, JavaVersion: command.ToolVersion["jdk"], Tool: command.Tool, PreBuildScript: unmarshalled.PreBuildScript, PostBuildScript: unmarshalled.PostBuildScript, AdditionalDownloads: unmarshalled.AdditionalDownloads, DisableSubmodules: unmarshalled.DisableSubmodules, AdditionalMemory: unmarshalled.AdditionalMemory, Repositories: unmarshalled.Repositories, AllowedDifferences: unmarshalled.AllowedDifferences})
-					break
-				}
+			recipe := &v1alpha1.BuildRecipe{CommandLine: command.Commands, EnforceVersion: unmarshalled.EnforceVersion, ToolVersion: command.ToolVersion[command.Tool], ToolVersions: command.ToolVersion, JavaVersion: command.ToolVersion["jdk"], Tool: command.Tool, PreBuildScript: unmarshalled.PreBuildScript, PostBuildScript: unmarshalled.PostBuildScript, AdditionalDownloads: unmarshalled.AdditionalDownloads, DisableSubmodules: unmarshalled.DisableSubmodules, AdditionalMemory: unmarshalled.AdditionalMemory, Repositories: unmarshalled.Repositories, AllowedDifferences: unmarshalled.AllowedDifferences, GitOptions: unmarshalled.GitOptions, Patches: unmarshalled.Patches, Env: unmarshalled.Env, SecretMounts: unmarshalled.SecretMounts, Reproducible: unmarshalled.Reproducible}
+			//if there is no match then we keep the recipe in case a suitable builder image is added later
+			recipe.Image = matchBuilderImage(command.ToolVersion, allBuilderImages)
+			if recipe.Image == "" {
+				unmatchedRecipes = append(unmatchedRecipes, recipe)
+			} else {
+				buildRecipes = append(buildRecipes, recipe)
 			}
+		}
 
+		//start with the resources earlier builds of the same repository needed, rather than retrying when it runs out of memory
+		jbsConfig := &v1alpha1.JBSConfig{}
+		err = r.client.Get(ctx, types.NamespacedName{Namespace: db.Namespace, Name: v1alpha1.JBSConfigName}, jbsConfig)
+		if err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		recommendedMemory, recommendedCPU, err := r.recommendedResources(ctx, &db, jbsConfig)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if recommendedMemory > unmarshalled.AdditionalMemory || recommendedCPU != "" {
+			log.Info(fmt.Sprintf("using resources recommended from earlier builds of %s, additional memory: %d, cpu: %s", db.Spec.ScmInfo.SCMURL, recommendedMemory, recommendedCPU))
+			for _, recipe := range append(append([]*v1alpha1.BuildRecipe{}, buildRecipes...), unmatchedRecipes...) {
+				if recommendedMemory > recipe.AdditionalMemory {
+					recipe.AdditionalMemory = recommendedMemory
+				}
+				recipe.BuildCPU = recommendedCPU
+			}
 		}
 
 		db.Status.PotentialBuildRecipes = buildRecipes
+		db.Status.UnmatchedBuildRecipes = unmatchedRecipes
 
+		imageSignature := ""
+		if len(unmarshalled.Image) > 0 {
+			//a shared build is only reused if it passes the trust policy of the registry, otherwise we build it ourselves
+			imageSignature, err = r.verifySharedImage(ctx, jbsConfig, &db, unmarshalled.Image, unmarshalled.Digest)
+			if err != nil {
+				log.Error(err, fmt.Sprintf("Not reusing preexisting shared build from image %s", unmarshalled.Image))
+				db.Status.Message = fmt.Sprintf("the preexisting shared build %s was rejected: %s", unmarshalled.Image, err.Error())
+				r.eventRecorder.Eventf(&db, v1.EventTypeWarning, "SharedImageNotVerified", "The preexisting shared build %s for DependencyBuild %s/%s was rejected: %s", unmarshalled.Image, db.Namespace, db.Name, err.Error())
+				unmarshalled.Image = ""
+			}
+		}
 		if len(unmarshalled.Image) > 0 {
 			log.Info(fmt.Sprintf("Found preexisting shared build with deployed GAVs %#v from image %#v", unmarshalled.Gavs, unmarshalled.Image))
 			db.Status.State = v1alpha1.DependencyBuildStateComplete
-			con, err := r.createRebuiltArtifacts(ctx, log, pr, &db, unmarshalled.Image, unmarshalled.Digest, unmarshalled.Gavs)
+			con, err := r.createRebuiltArtifacts(ctx, log, pr, &db, unmarshalled.Image, unmarshalled.Digest, imageSignature, unmarshalled.Gavs, nil)
 			if err != nil {
 				return reconcile.Result{}, err
 			} else if !con {
@@ -385,6 +421,11 @@ type marshalledBuildInfo struct {
 	AdditionalMemory    int
 	Repositories        []string
 	AllowedDifferences  []string
+	GitOptions          *v1alpha1.GitCloneOptions
+	Patches             []v1alpha1.BuildPatch
+	Env                 []v1alpha1.BuildEnvVar
+	SecretMounts        []v1alpha1.SecretMount
+	Reproducible        *v1alpha1.ReproducibleBuildSettings
 	Image               string
 	Digest              string
 	Gavs                []string
@@ -406,10 +447,15 @@ func (r *ReconcileDependencyBuild) processBuilderImages(ctx context.Context, log
 	//TODO how important is the order here?  do we want 11,8,17 per the old form at https://github.com/redhat-appstudio/jvm-build-service/blob/b91ec6e1888e43962cba16fcaee94e0c9f64557d/deploy/operator/config/system-config.yaml#L8
 	// the unit tests's imaage verification certainly assumes a order
 	result := []BuilderImage{}
-	for _, val := range systemConfig.Spec.Builders {
+	for key, val := range systemConfig.Spec.Builders {
+		tools, err := builderTools(val)
+		if err != nil {
+			log.Error(err, "ignoring invalid builder image", "builder", key)
+			continue
+		}
 		result = append(result, BuilderImage{
 			Image:    val.Image,
-			Tools:    r.processBuilderImageTags(val.Tag),
+			Tools:    tools,
 			Priority: val.Priority,
 		})
 	}
@@ -419,16 +465,138 @@ func (r *ReconcileDependencyBuild) processBuilderImages(ctx context.Context, log
 	return result, nil
 }
 
-func (r *ReconcileDependencyBuild) processBuilderImageTags(tags string) map[string][]string {
-	tagList := strings.Split(tags, ",")
+// pinnedBuilderImage returns the builder image pinned to the digest resolved by the SystemConfig reconciler, if
+// pinning is enabled and the digest is known
+func pinnedBuilderImage(log logr.Logger, systemConfig *v1alpha1.SystemConfig, image string) string {
+	if !systemConfig.Spec.PinBuilderImages {
+		return image
+	}
+	for key, val := range systemConfig.Spec.Builders {
+		if val.Image != image || systemConfig.Status.Builders[key].Digest == "" {
+			continue
+		}
+		pinned, err := systemconfig.PinnedImage(image, systemConfig.Status.Builders[key].Digest)
+		if err != nil {
+			log.Error(err, "unable to pin builder image", "image", image)
+			return image
+		}
+		return pinned
+	}
+	return image
+}
+
+// builderTools returns the versions of each tool in a builder image
+func builderTools(builder v1alpha1.BuilderImageInfo) (map[string][]string, error) {
+	capabilities, err := systemconfig.BuilderCapabilities(builder)
+	if err != nil {
+		return nil, err
+	}
 	tools := map[string][]string{}
-	for _, tag := range tagList {
-		split := strings.Split(tag, ":")
-		key := split[0]
-		val := split[1]
-		tools[key] = append(tools[key], strings.Split(val, ";")...)
+	for _, capability := range capabilities {
+		for _, version := range capability.Versions {
+			if !slices.Contains(tools[capability.Tool], version) {
+				tools[capability.Tool] = append(tools[capability.Tool], version)
+			}
+		}
 	}
-	return tools
+	return tools, nil
+}
+
+// matchBuilderImage returns the first builder image that has all the required tool versions, or an empty string if
+// there is none
+func matchBuilderImage(toolVersions map[string]string, builderImages []BuilderImage) string {
+	for _, image := range builderImages {
+		imageOk := true
+		for tool, version := range toolVersions {
+			if !slices.Contains(image.Tools[tool], version) {
+				imageOk = false
+				break
+			}
+		}
+		if imageOk {
+			return image.Image
+		}
+	}
+	return ""
+}
+
+// missingTools describes the tool versions of recipes that have no builder image
+func missingTools(recipes []*v1alpha1.BuildRecipe) string {
+	ret := []string{}
+	for _, recipe := range recipes {
+		tools := []string{}
+		for tool, version := range recipe.ToolVersions {
+			tools = append(tools, tool+":"+version)
+		}
+		sort.Strings(tools)
+		ret = append(ret, strings.Join(tools, ","))
+	}
+	return strings.Join(ret, "; ")
+}
+
+func (r *ReconcileDependencyBuild) handleStateFailed(ctx context.Context, log logr.Logger, db *v1alpha1.DependencyBuild) (reconcile.Result, error) {
+	if db.Status.FailureReason == v1alpha1.DependencyBuildFailureReasonNoBuilderImage {
+		requeued, err := r.rematchBuilderImages(ctx, log, db)
+		if err != nil || requeued {
+			return reconcile.Result{}, err
+		}
+	}
+	return reconcile.Result{}, r.removeToolCache(ctx, db)
+}
+
+// rematchBuilderImages matches the recipes that had no builder image against the current builder images, and if any
+// now match the build is submitted again with them
+func (r *ReconcileDependencyBuild) rematchBuilderImages(ctx context.Context, log logr.Logger, db *v1alpha1.DependencyBuild) (bool, error) {
+	builderImages, err := r.processBuilderImages(ctx, log)
+	if err != nil {
+		return false, err
+	}
+	matched := []*v1alpha1.BuildRecipe{}
+	unmatched := []*v1alpha1.BuildRecipe{}
+	images := []string{}
+	for _, recipe := range db.Status.UnmatchedBuildRecipes {
+		image := matchBuilderImage(recipe.ToolVersions, builderImages)
+		if image == "" {
+			unmatched = append(unmatched, recipe)
+			continue
+		}
+		recipe.Image = image
+		matched = append(matched, recipe)
+		if !slices.Contains(images, image) {
+			images = append(images, image)
+		}
+	}
+	if len(matched) == 0 {
+		return false, nil
+	}
+	reason := "builder images " + strings.Join(images, ", ") + " now provide " + missingTools(matched)
+	log.Info("re-queuing build after SystemConfig change", "reason", reason)
+	db.Status.PotentialBuildRecipes = append(db.Status.PotentialBuildRecipes, matched...)
+	db.Status.UnmatchedBuildRecipes = unmatched
+	db.Status.Requeues = append(db.Status.Requeues, v1alpha1.BuildRequeue{Time: v12.Now(), Reason: reason})
+	db.Status.FailureReason = ""
+	db.Status.Message = ""
+	db.Status.State = v1alpha1.DependencyBuildStateSubmitBuild
+	r.eventRecorder.Eventf(db, v1.EventTypeNormal, "Requeued", "The DependencyBuild %s/%s was re-queued as %s", db.Namespace, db.Name, reason)
+	return true, r.client.Status().Update(ctx, db)
+}
+
+// unmatchedBuildRequests returns the DependencyBuilds that failed because no builder image had the tools they need,
+// so they can be re-evaluated when the builder images change
+func unmatchedBuildRequests(ctx context.Context, client client.Client) []reconcile.Request {
+	list := v1alpha1.DependencyBuildList{}
+	err := client.List(ctx, &list)
+	if err != nil {
+		ctrl.Log.WithName("dependencybuild").Error(err, "unable to list DependencyBuilds after SystemConfig change")
+		return []reconcile.Request{}
+	}
+	ret := []reconcile.Request{}
+	for _, db := range list.Items {
+		if db.Status.State == v1alpha1.DependencyBuildStateFailed && db.Status.FailureReason == v1alpha1.DependencyBuildFailureReasonNoBuilderImage {
+			ret = append(ret, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: db.Namespace, Name: db.Name}})
+		}
+	}
+	return ret
 }
 
 func (r *ReconcileDependencyBuild) handleStateSubmitBuild(ctx context.Context, db *v1alpha1.DependencyBuild) (reconcile.Result, error) {
@@ -440,6 +608,11 @@ func (r *ReconcileDependencyBuild) handleStateSubmitBuild(ctx context.Context, d
 	//no more attempts
 	if len(db.Status.PotentialBuildRecipes) == 0 {
 		db.Status.State = v1alpha1.DependencyBuildStateFailed
+		if len(db.Status.UnmatchedBuildRecipes) > 0 {
+			db.Status.FailureReason = v1alpha1.DependencyBuildFailureReasonNoBuilderImage
+			db.Status.Message = "no builder image has the tool versions required by the remaining recipes: " + missingTools(db.Status.UnmatchedBuildRecipes)
+			r.eventRecorder.Eventf(db, v1.EventTypeWarning, v1alpha1.DependencyBuildFailureReasonNoBuilderImage, "The DependencyBuild %s/%s has no builder image for %s", db.Namespace, db.Name, missingTools(db.Status.UnmatchedBuildRecipes))
+		}
 		r.eventRecorder.Eventf(db, v1.EventTypeWarning, "BuildFailed", "The DependencyBuild %s/%s moved to failed, all recipes exhausted", db.Namespace, db.Name)
 		return reconcile.Result{}, r.client.Status().Update(ctx, db)
 	}
@@ -499,6 +672,12 @@ func (r *ReconcileDependencyBuild) handleStateBuilding(ctx context.Context, log
 	if err != nil {
 		return reconcile.Result{}, err
 	}
+	systemConfig := v1alpha1.SystemConfig{}
+	err = r.client.Get(ctx, types.NamespacedName{Name: systemconfig.SystemConfigKey}, &systemConfig)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	builderImage := pinnedBuilderImage(log, &systemConfig, attempt.Recipe.Image)
 	scmUrl := modifyURLFragment(log, db.Spec.ScmInfo.SCMURL)
 	paramValues := []pipelinev1beta1.Param{
 		{Name: PipelineBuildId, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: db.Name}},
@@ -508,31 +687,34 @@ func (r *ReconcileDependencyBuild) handleStateBuilding(ctx context.Context, log
 		{Name: PipelineParamChainsGitUrl, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: scmUrl}},
 		{Name: PipelineParamChainsGitCommit, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: db.Spec.ScmInfo.CommitHash}},
 		{Name: PipelineParamPath, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: db.Spec.ScmInfo.Path}},
-		{Name: PipelineParamImage, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: attempt.Recipe.Image}},
+		{Name: PipelineParamImage, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: builderImage}},
 		{Name: PipelineParamGoals, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeArray, ArrayVal: attempt.Recipe.CommandLine}},
 		{Name: PipelineParamEnforceVersion, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: attempt.Recipe.EnforceVersion}},
 		{Name: PipelineParamToolVersion, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: attempt.Recipe.ToolVersion}},
 		{Name: PipelineParamJavaVersion, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: attempt.Recipe.JavaVersion}},
 	}
 
-	systemConfig := v1alpha1.SystemConfig{}
-	err = r.client.Get(ctx, types.NamespacedName{Name: systemconfig.SystemConfigKey}, &systemConfig)
-	if err != nil {
-		return reconcile.Result{}, err
-	}
 	diagnostic := ""
 	// TODO: set owner, pass parameter to do verify if true, via an annoaton on the dependency build, may eed to wait for dep build to exist verify is an optional, use append on each step in build recipes
 	pr.Spec.PipelineSpec, diagnostic, err = createPipelineSpec(attempt.Recipe.Tool, db.Status.CommitTime, jbsConfig, &systemConfig, attempt.Recipe, db, paramValues, buildRequestProcessorImage, attempt.BuildId)
-	if err != nil {
+	if invalid, ok := err.(*invalidRecipeError); ok {
+		//the recipe has to be fixed, retrying will not help
+		db.Status.State = v1alpha1.DependencyBuildStateFailed
+		db.Status.FailureReason = v1alpha1.DependencyBuildFailureReasonInvalidRecipe
+		db.Status.Message = "invalid build recipe: " + invalid.Error()
+		r.eventRecorder.Eventf(db, v1.EventTypeWarning, v1alpha1.DependencyBuildFailureReasonInvalidRecipe, "The DependencyBuild %s/%s has an invalid build recipe: %s", db.Namespace, db.Name, invalid.Error())
+		return reconcile.Result{}, r.client.Status().Update(ctx, db)
+	} else if err != nil {
 		return reconcile.Result{}, err
 	}
 
 	attempt.Build.DiagnosticDockerFile = diagnostic
 	pr.Spec.Params = paramValues
-	pr.Spec.Workspaces = []pipelinev1beta1.WorkspaceBinding{
-		{Name: WorkspaceBuildSettings, EmptyDir: &v1.EmptyDirVolumeSource{}},
-		{Name: WorkspaceSource, EmptyDir: &v1.EmptyDirVolumeSource{}},
+	pr.Spec.Workspaces, err = r.buildWorkspaces(ctx, db, jbsConfig, attempt.Recipe)
+	if err != nil {
+		return reconcile.Result{}, err
 	}
+	pr.Spec.TaskRunSpecs = reproducibilityTaskRunSpecs(jbsConfig, attempt.Recipe, pr.Name)
 
 	if !jbsConfig.Spec.CacheSettings.DisableTLS {
 		pr.Spec.Workspaces = append(pr.Spec.Workspaces, pipelinev1beta1.WorkspaceBinding{Name: "tls", ConfigMap: &v1.ConfigMapVolumeSource{LocalObjectReference: v1.LocalObjectReference{Name: v1alpha1.TlsConfigMapName}}})
@@ -555,6 +737,90 @@ func (r *ReconcileDependencyBuild) handleStateBuilding(ctx context.Context, log
 	return reconcile.Result{}, r.client.Status().Update(ctx, db)
 }
 
+// buildWorkspaces returns the workspace bindings for the build pipeline. If workspace storage is configured the source
+// workspace is backed by a PVC created from a VolumeClaimTemplate, and if tool caches are configured the per
+// DependencyBuild tool cache PVC is created so it can be reused by later build attempts. The second build of the
+// reproducibility check gets its own source workspace.
+func (r *ReconcileDependencyBuild) buildWorkspaces(ctx context.Context, db *v1alpha1.DependencyBuild, jbsConfig *v1alpha1.JBSConfig, recipe *v1alpha1.BuildRecipe) ([]pipelinev1beta1.WorkspaceBinding, error) {
+	settings := jbsConfig.Spec.BuildSettings
+	workspaces := []pipelinev1beta1.WorkspaceBinding{
+		{Name: WorkspaceBuildSettings, EmptyDir: &v1.EmptyDirVolumeSource{}},
+	}
+	sourceWorkspaces := []string{WorkspaceSource}
+	if reproducibilityCheckEnabled(jbsConfig, recipe) {
+		sourceWorkspaces = append(sourceWorkspaces, WorkspaceReproducibility)
+	}
+	for _, name := range sourceWorkspaces {
+		if settings.WorkspaceStorage != "" {
+			pvc, err := persistentVolumeClaim(settings.WorkspaceStorage, settings.StorageClassName)
+			if err != nil {
+				return nil, err
+			}
+			workspaces = append(workspaces, pipelinev1beta1.WorkspaceBinding{Name: name, VolumeClaimTemplate: pvc})
+		} else {
+			workspaces = append(workspaces, pipelinev1beta1.WorkspaceBinding{Name: name, EmptyDir: &v1.EmptyDirVolumeSource{}})
+		}
+	}
+	if len(settings.ToolCaches) > 0 {
+		name := db.Name + ToolCacheSuffix
+		err := r.client.Get(ctx, types.NamespacedName{Namespace: db.Namespace, Name: name}, &v1.PersistentVolumeClaim{})
+		if errors.IsNotFound(err) {
+			pvc, err := persistentVolumeClaim(settingOrDefault(settings.ToolCacheStorage, v1alpha1.ToolCacheStorageDefault), settings.StorageClassName)
+			if err != nil {
+				return nil, err
+			}
+			pvc.Name = name
+			pvc.Namespace = db.Namespace
+			pvc.Labels = map[string]string{artifactbuild.DependencyBuildIdLabel: db.Name}
+			if err := controllerutil.SetOwnerReference(db, pvc, r.scheme); err != nil {
+				return nil, err
+			}
+			if err := r.client.Create(ctx, pvc); err != nil && !errors.IsAlreadyExists(err) {
+				return nil, err
+			}
+		} else if err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, pipelinev1beta1.WorkspaceBinding{Name: WorkspaceToolCache, PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: name}})
+	}
+	return workspaces, nil
+}
+
+func persistentVolumeClaim(storage string, storageClassName string) (*v1.PersistentVolumeClaim, error) {
+	qty, err := resource.ParseQuantity(storage)
+	if err != nil {
+		return nil, err
+	}
+	pvc := v1.PersistentVolumeClaim{}
+	pvc.Spec.AccessModes = []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}
+	pvc.Spec.Resources.Requests = v1.ResourceList{v1.ResourceStorage: qty}
+	if storageClassName != "" {
+		pvc.Spec.StorageClassName = &storageClassName
+	}
+	return &pvc, nil
+}
+
+// removeToolCache deletes the tool cache PVC once the DependencyBuild is finished. If the DependencyBuild is deleted
+// instead the PVC is removed by the owner reference.
+func (r *ReconcileDependencyBuild) removeToolCache(ctx context.Context, db *v1alpha1.DependencyBuild) error {
+	//finished builds are reconciled again on every resync, so only delete the PVC if it is still there
+	pvc := v1.PersistentVolumeClaim{}
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: db.Namespace, Name: db.Name + ToolCacheSuffix}, &pvc)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if pvc.DeletionTimestamp != nil {
+		return nil
+	}
+	err = r.client.Delete(ctx, &pvc)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
 func currentDependencyBuildPipelineName(db *v1alpha1.DependencyBuild) string {
 	return fmt.Sprintf("%s-build-%d", db.Name, len(db.Status.BuildAttempts))
 }
@@ -588,10 +854,33 @@ func (r *ReconcileDependencyBuild) handleBuildPipelineRunReceived(ctx context.Co
 
 		run.Complete = true
 		run.Succeeded = pr.Status.GetCondition(apis.ConditionSucceeded).IsTrue()
+		attempt.ResourceUsage = r.buildResourceUsage(ctx, log, pr)
+		r.handleReproducibilityResult(ctx, log, pr, db)
+		if err := r.handleVerificationReport(ctx, log, pr, db, attempt); err != nil {
+			return reconcile.Result{}, err
+		}
 
 		if !run.Succeeded {
 			log.Info(fmt.Sprintf("build %s failed", pr.Name))
 
+			//a bad signature will not be fixed by a different recipe, so we fail straight away
+			if r.failedSignatureVerification(ctx, log, pr) {
+				attempt.SignatureVerification = &v1alpha1.SignatureVerificationResult{Verified: false, Message: "The tag or commit did not have a valid signature"}
+				db.Status.State = v1alpha1.DependencyBuildStateFailed
+				db.Status.FailureReason = v1alpha1.DependencyBuildFailureReasonSignatureVerification
+				db.Status.Message = fmt.Sprintf("signature verification failed for %s %s", db.Spec.ScmInfo.SCMURL, db.Spec.ScmInfo.Tag)
+				r.eventRecorder.Eventf(db, v1.EventTypeWarning, v1alpha1.DependencyBuildFailureReasonSignatureVerification, "The DependencyBuild %s/%s failed signature verification", db.Namespace, db.Name)
+				return reconcile.Result{}, r.client.Status().Update(ctx, db)
+			}
+			//the project itself is not reproducible, so a different recipe will not help either
+			if r.failedReproducibilityCheck(ctx, log, pr) {
+				db.Status.State = v1alpha1.DependencyBuildStateFailed
+				db.Status.FailureReason = v1alpha1.DependencyBuildFailureReasonNotReproducible
+				db.Status.Message = fmt.Sprintf("building %s %s twice produced different artifacts", db.Spec.ScmInfo.SCMURL, db.Spec.ScmInfo.Tag)
+				r.eventRecorder.Eventf(db, v1.EventTypeWarning, v1alpha1.DependencyBuildFailureReasonNotReproducible, "The DependencyBuild %s/%s was not reproducible", db.Namespace, db.Name)
+				return reconcile.Result{}, r.client.Status().Update(ctx, db)
+			}
+
 			//if there was a cache issue we want to retry the build
 			//we check and see if there is a cache pod newer than the build
 			//if so we just delete the pipelinerun
@@ -621,11 +910,7 @@ func (r *ReconcileDependencyBuild) handleBuildPipelineRunReceived(ctx context.Co
 						log.Info(msg)
 						doRetry = true
 						//increase the memory limit
-						if attempt.Recipe.AdditionalMemory == 0 {
-							attempt.Recipe.AdditionalMemory = MemoryIncrement
-						} else {
-							attempt.Recipe.AdditionalMemory = attempt.Recipe.AdditionalMemory * 2
-						}
+						attempt.Recipe.AdditionalMemory = increaseMemory(attempt.Recipe.AdditionalMemory)
 						for i := range db.Status.PotentialBuildRecipes {
 							db.Status.PotentialBuildRecipes[i].AdditionalMemory = attempt.Recipe.AdditionalMemory
 						}
@@ -675,6 +960,8 @@ func (r *ReconcileDependencyBuild) handleBuildPipelineRunReceived(ctx context.Co
 					db.Status.DeployedArtifacts = deployed
 				} else if i.Name == artifactbuild.PipelineResultVerificationResult {
 					verificationResults = i.Value.StringVal
+				} else if i.Name == PipelineResultSignatureVerification {
+					attempt.SignatureVerification = parseSignatureVerificationResult(i.Value.StringVal)
 				}
 			}
 			run.Results = &v1alpha1.BuildPipelineRunResults{
@@ -686,6 +973,19 @@ func (r *ReconcileDependencyBuild) handleBuildPipelineRunReceived(ctx context.Co
 				HermeticBuildImage:  hermeticBuildImage,
 			}
 
+			licenses := r.buildLicenses(ctx, log, pr)
+			deniedLicense, err := r.handleLicensePolicy(ctx, db, licenses)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			if deniedLicense {
+				//the deploy step checked the same denylist, so nothing was deployed
+				db.Status.State = v1alpha1.DependencyBuildStateFailed
+				db.Status.FailureReason = v1alpha1.DependencyBuildFailureReasonDeniedLicense
+				db.Status.Message = fmt.Sprintf("the artifacts built from %s %s have denied licenses", db.Spec.ScmInfo.SCMURL, db.Spec.ScmInfo.Tag)
+				return reconcile.Result{}, r.client.Status().Update(ctx, db)
+			}
+
 			for _, i := range pr.Status.Results {
 				if i.Name == artifactbuild.PipelineResultContaminants {
 
@@ -699,7 +999,14 @@ func (r *ReconcileDependencyBuild) handleBuildPipelineRunReceived(ctx context.Co
 					//we need to create 'DeployedArtifact' resources for the objects that were deployed
 					deployed := strings.Split(i.Value.StringVal, ",")
 
-					con, err := r.createRebuiltArtifacts(ctx, log, pr, db, image, digest, deployed)
+					imageSignature := ""
+					if signedBuild(pr) {
+						imageSignature, err = imageSignatureReference(image, digest)
+						if err != nil {
+							return reconcile.Result{}, err
+						}
+					}
+					con, err := r.createRebuiltArtifacts(ctx, log, pr, db, image, digest, imageSignature, deployed, licenses)
 
 					if err != nil {
 						return reconcile.Result{}, err
@@ -712,6 +1019,9 @@ func (r *ReconcileDependencyBuild) handleBuildPipelineRunReceived(ctx context.Co
 				}
 			}
 
+			if err := r.applyContaminantPolicy(ctx, log, db); err != nil {
+				return reconcile.Result{}, err
+			}
 			if len(db.Status.Contaminants) == 0 {
 				db.Status.State = v1alpha1.DependencyBuildStateComplete
 			} else {
@@ -881,21 +1191,38 @@ func (r *ReconcileDependencyBuild) handleBuildCompletedWithContaminants(ctx cont
 	}
 	return r.client.Status().Update(ctx, db)
 }
-func (r *ReconcileDependencyBuild) handleStateContaminated(ctx context.Context, db *v1alpha1.DependencyBuild) (reconcile.Result, error) {
+func (r *ReconcileDependencyBuild) handleStateContaminated(ctx context.Context, log logr.Logger, db *v1alpha1.DependencyBuild) (reconcile.Result, error) {
 	contaminants := db.Status.Contaminants
 	if len(contaminants) == 0 {
 		//all fixed, just set the state back to building and try again
 		//this is triggered when contaminants are removed by the ABR controller
 		//setting it back to building should re-try the recipe that actually worked
 		db.Status.State = v1alpha1.DependencyBuildStateNew
-		return reconcile.Result{}, r.client.Update(ctx, db)
+		db.Status.ClearContaminationGraph()
+		return reconcile.Result{}, r.client.Status().Update(ctx, db)
 	}
-	return reconcile.Result{}, nil
+	//the contaminants may never be rebuilt, so record what the build is waiting for
+	return r.updateContaminationGraph(ctx, log, db)
 }
 
 func (r *ReconcileDependencyBuild) createRebuiltArtifacts(ctx context.Context, log logr.Logger, pr *pipelinev1beta1.PipelineRun, db *v1alpha1.DependencyBuild,
-	image string, digest string, deployed []string) (bool, error) {
+	image string, digest string, imageSignature string, deployed []string, licenses map[string][]string) (bool, error) {
 	db.Status.DeployedArtifacts = deployed
+	var signatureVerification *v1alpha1.SignatureVerificationResult
+	attempt := db.Status.GetBuildPipelineRun(pr.Name)
+	if attempt != nil {
+		signatureVerification = attempt.SignatureVerification
+	}
+
+	//the SBOM and provenance are only generated for artifacts we built, not ones found in a shared registry
+	var sbom *v1alpha1.SBOMReference
+	if attempt != nil && attempt.Recipe != nil {
+		var err error
+		sbom, err = r.handleBuildSbom(ctx, log, pr, db, image, digest)
+		if err != nil {
+			return false, err
+		}
+	}
 
 	for _, i := range deployed {
 		ra := v1alpha1.RebuiltArtifact{}
@@ -908,6 +1235,19 @@ func (r *ReconcileDependencyBuild) createRebuiltArtifacts(ctx context.Context, l
 		ra.Spec.GAV = i
 		ra.Spec.Image = image
 		ra.Spec.Digest = digest
+		ra.Spec.SignatureVerification = signatureVerification
+		ra.Spec.ImageSignature = imageSignature
+		var provenance *v1alpha1.ProvenanceReference
+		if attempt != nil && attempt.Recipe != nil {
+			var err error
+			provenance, err = r.createProvenance(ctx, pr, db, attempt.Recipe, &ra)
+			if err != nil {
+				return false, err
+			}
+		}
+		ra.Status.Provenance = provenance
+		ra.Status.SBOM = sbom
+		ra.Status.Licenses = licenses[i]
 		err := r.client.Create(ctx, &ra)
 		if err != nil {
 			if !errors.IsAlreadyExists(err) {
@@ -925,6 +1265,11 @@ func (r *ReconcileDependencyBuild) createRebuiltArtifacts(ctx context.Context, l
 				}
 				ra.Spec.Image = image
 				ra.Spec.Digest = digest
+				ra.Spec.SignatureVerification = signatureVerification
+				ra.Spec.ImageSignature = imageSignature
+				ra.Status.Provenance = provenance
+				ra.Status.SBOM = sbom
+				ra.Status.Licenses = licenses[i]
 				log.Info(fmt.Sprintf("Updating existing RebuiltArtifact %s to reference image %s", ra.Name, ra.Spec.Image), "action", "UPDATE")
 				err = r.client.Update(ctx, &ra)
 				if err != nil {
@@ -956,7 +1301,7 @@ func (r *ReconcileDependencyBuild) createLookupBuildInfoPipeline(ctx context.Con
 		"--cache-url",
 		cacheUrl,
 		"--scm-url",
-		build.ScmInfo.SCMURL,
+		gitMirrorUrl(jbsConfig, build.ScmInfo.SCMURL),
 		"--scm-tag",
 		build.ScmInfo.Tag,
 		"--scm-commit",
@@ -987,6 +1332,18 @@ func (r *ReconcileDependencyBuild) createLookupBuildInfoPipeline(ctx context.Con
 	if build.ScmInfo.Private {
 		args = append(args, "--private-repo")
 	}
+	//the build request processor can only clone git repositories, for anything else we check the source out first
+	var steps []pipelinev1beta1.Step
+	var volumes []v1.Volume
+	if build.ScmInfo.SCMType != "" && build.ScmInfo.SCMType != ScmTypeGit {
+		checkout, err := r.createLookupBuildInfoCheckoutStep(ctx, log, db, jbsConfig)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, *checkout)
+		volumes = append(volumes, v1.Volume{Name: WorkspaceSource, VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}})
+		args = append(args, "--source-path", lookupBuildInfoSourcePath+"/workspace")
+	}
 	pullPolicy := v1.PullIfNotPresent
 	if strings.HasPrefix(image, "quay.io/minikube") {
 		pullPolicy = v1.PullNever
@@ -1000,7 +1357,10 @@ func (r *ReconcileDependencyBuild) createLookupBuildInfoPipeline(ctx context.Con
 			secretOptional = true
 		}
 	}
-	memory := fmt.Sprintf("%dMi", 512+additionalMemory)
+	resources, err := util.StepResources(jbsConfig, "process-build-requests", v1alpha1.StepResources{RequestMemory: "512Mi", RequestCPU: "10m", LimitMemory: "512Mi", LimitCPU: "300m"}, additionalMemory)
+	if err != nil {
+		return nil, err
+	}
 	envVars := []v1.EnvVar{
 		{Name: "JAVA_OPTS", Value: "-XX:+CrashOnOutOfMemoryError"},
 		{Name: "GIT_TOKEN", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: v1alpha1.GitSecretName}, Key: v1alpha1.GitSecretTokenKey, Optional: &trueBool}}},
@@ -1008,6 +1368,21 @@ func (r *ReconcileDependencyBuild) createLookupBuildInfoPipeline(ctx context.Con
 	if jbsConfig.ImageRegistry().SecretName != "" {
 		envVars = append(envVars, v1.EnvVar{Name: "REGISTRY_TOKEN", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: jbsConfig.ImageRegistry().SecretName}, Key: v1alpha1.ImageSecretTokenKey, Optional: &secretOptional}}})
 	}
+	envVars = append(envVars, scmCredentialsEnv(jbsConfig)...)
+	var volumeMounts []v1.VolumeMount
+	if len(volumes) > 0 {
+		volumeMounts = []v1.VolumeMount{{Name: WorkspaceSource, MountPath: lookupBuildInfoSourcePath}}
+	}
+	steps = append(steps, pipelinev1beta1.Step{
+		Name:             "process-build-requests",
+		Image:            image,
+		ImagePullPolicy:  pullPolicy,
+		SecurityContext:  &v1.SecurityContext{RunAsUser: &zero},
+		Script:           withScmCredentials(jbsConfig, artifactbuild.InstallKeystoreIntoBuildRequestProcessor(args)),
+		ComputeResources: resources,
+		VolumeMounts:     volumeMounts,
+		Env:              envVars,
+	})
 	return &pipelinev1beta1.PipelineSpec{
 		Workspaces: []pipelinev1beta1.PipelineWorkspaceDeclaration{{Name: "tls"}},
 		Results:    []pipelinev1beta1.PipelineResult{{Name: BuildInfoPipelineResultBuildInfo, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: "$(tasks.task.results." + BuildInfoPipelineResultBuildInfo + ")"}}},
@@ -1019,21 +1394,8 @@ func (r *ReconcileDependencyBuild) createLookupBuildInfoPipeline(ctx context.Con
 					TaskSpec: pipelinev1beta1.TaskSpec{
 						Workspaces: []pipelinev1beta1.WorkspaceDeclaration{{Name: "tls"}},
 						Results:    []pipelinev1beta1.TaskResult{{Name: BuildInfoPipelineResultBuildInfo}},
-						Steps: []pipelinev1beta1.Step{
-							{
-								Name:            "process-build-requests",
-								Image:           image,
-								ImagePullPolicy: pullPolicy,
-								SecurityContext: &v1.SecurityContext{RunAsUser: &zero},
-								Script:          artifactbuild.InstallKeystoreIntoBuildRequestProcessor(args),
-								ComputeResources: v1.ResourceRequirements{
-									//TODO: make configurable
-									Requests: v1.ResourceList{"memory": resource.MustParse(memory), "cpu": resource.MustParse("10m")},
-									Limits:   v1.ResourceList{"memory": resource.MustParse(memory)},
-								},
-								Env: envVars,
-							},
-						},
+						Steps:      steps,
+						Volumes:    volumes,
 					},
 				},
 			},
@@ -1041,11 +1403,58 @@ func (r *ReconcileDependencyBuild) createLookupBuildInfoPipeline(ctx context.Con
 	}, nil
 }
 
+// createLookupBuildInfoCheckoutStep creates a step that checks out non git sources for the build request processor.
+// It runs in the highest priority builder image, as the builder images contain the SCM tools needed for the build.
+func (r *ReconcileDependencyBuild) createLookupBuildInfoCheckoutStep(ctx context.Context, log logr.Logger, db *v1alpha1.DependencyBuild, jbsConfig *v1alpha1.JBSConfig) (*pipelinev1beta1.Step, error) {
+	scm, err := scmProviderForType(db.Spec.ScmInfo.SCMType)
+	if err != nil {
+		return nil, err
+	}
+	builderImages, err := r.processBuilderImages(ctx, log)
+	if err != nil {
+		return nil, err
+	}
+	if len(builderImages) == 0 {
+		return nil, fmt.Errorf("no builder images configured to check out %s source", db.Spec.ScmInfo.SCMType)
+	}
+	zero := int64(0)
+	//the SCM info comes from the build request, so it is passed in the environment rather than substituted into the script
+	paramValues := []v1.EnvVar{
+		{Name: "PARAM_" + PipelineParamScmUrl, Value: db.Spec.ScmInfo.SCMURL},
+		{Name: "PARAM_" + PipelineParamScmTag, Value: db.Spec.ScmInfo.Tag},
+		{Name: "PARAM_" + PipelineParamScmHash, Value: db.Spec.ScmInfo.CommitHash},
+		{Name: "PARAM_" + PipelineParamPath, Value: db.Spec.ScmInfo.Path},
+	}
+	script := scm.checkoutScript(jbsConfig, db, &v1alpha1.BuildRecipe{}, lookupBuildInfoSourcePath+"/workspace")
+	for _, i := range paramValues {
+		script = strings.ReplaceAll(script, "$(params."+strings.TrimPrefix(i.Name, "PARAM_")+")", "${"+i.Name+"}")
+	}
+	//the checkout uses the same resources as the checkout in the build pipeline
+	resources, err := stepResources(jbsConfig, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	env := append(paramValues, scm.credentials()...)
+	return &pipelinev1beta1.Step{
+		Name:             "checkout",
+		Image:            builderImages[0].Image,
+		SecurityContext:  &v1.SecurityContext{RunAsUser: &zero},
+		Script:           withScmCredentials(jbsConfig, script),
+		Env:              append(env, scmCredentialsEnv(jbsConfig)...),
+		ComputeResources: resources["git-clone-and-settings"],
+		VolumeMounts:     []v1.VolumeMount{{Name: WorkspaceSource, MountPath: lookupBuildInfoSourcePath}},
+	}, nil
+}
+
 // returns a string containing all builder image tools
 func (r *ReconcileDependencyBuild) createToolVersionString(config *v1alpha1.SystemConfig) string {
 	tools := map[string][]string{}
 	for _, i := range config.Spec.Builders {
-		tags := r.processBuilderImageTags(i.Tag)
+		tags, err := builderTools(i)
+		if err != nil {
+			//invalid builders are reported in the SystemConfig status
+			continue
+		}
 		for k, v := range tags {
 			_, exists := tools[k]
 			if exists {
@@ -1098,6 +1507,24 @@ func (r *ReconcileDependencyBuild) failedDueToMemory(ctx context.Context, log lo
 	return false
 }
 
+// failedSignatureVerification checks if the checkout step exited with the signature verification failure exit code
+func (r *ReconcileDependencyBuild) failedSignatureVerification(ctx context.Context, log logr.Logger, pr *pipelinev1beta1.PipelineRun) bool {
+	for _, trs := range pr.Status.ChildReferences {
+		tr := pipelinev1beta1.TaskRun{}
+		err := r.client.Get(ctx, types.NamespacedName{Namespace: pr.Namespace, Name: trs.Name}, &tr)
+		if err != nil {
+			log.Error(err, "Unable to retrieve TaskRun to check for signature verification failure")
+		} else {
+			for _, cont := range tr.Status.Steps {
+				if cont.Name == "git-clone-and-settings" && cont.Terminated != nil && cont.Terminated.ExitCode == SignatureVerificationExitCode {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 func (r *ReconcileDependencyBuild) buildRequestProcessorImage(ctx context.Context, log logr.Logger) (string, error) {
 	image, err := util.GetImageName(ctx, r.client, log, "build-request-processor", "JVM_BUILD_SERVICE_REQPROCESSOR_IMAGE")
 	return image, err
@@ -2,11 +2,31 @@ package dependencybuild
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
 	. "github.com/onsi/gomega"
 
 	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
@@ -17,6 +37,9 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -123,6 +146,52 @@ func TestStateNew(t *testing.T) {
 	})
 }
 
+func TestStateNewNonGitScm(t *testing.T) {
+	t.Run("Test svn sources are checked out before build discovery", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		db := v1alpha1.DependencyBuild{}
+		db.Namespace = metav1.NamespaceDefault
+		db.Name = "test"
+		db.Status.State = v1alpha1.DependencyBuildStateNew
+		db.Spec.ScmInfo.SCMURL = "https://svn.example.com/repos/project/tags/1.0"
+		db.Spec.ScmInfo.SCMType = ScmTypeSvn
+		db.Spec.ScmInfo.CommitHash = "1234"
+
+		ctx := context.TODO()
+		client, reconciler := setupClientAndReconciler(&db)
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: db.Namespace, Name: db.Name}}))
+
+		prList := &pipelinev1beta1.PipelineRunList{}
+		g.Expect(client.List(ctx, prList)).Should(Succeed())
+		g.Expect(len(prList.Items)).Should(Equal(1))
+		task := prList.Items[0].Spec.PipelineSpec.Tasks[0].TaskSpec
+		g.Expect(len(task.Steps)).Should(Equal(2))
+		g.Expect(task.Steps[0].Name).Should(Equal("checkout"))
+		g.Expect(task.Steps[0].Image).Should(HavePrefix("quay.io/redhat-appstudio/hacbs-jdk"))
+		g.Expect(task.Steps[0].Script).Should(HavePrefix("svn checkout --non-interactive --revision \"${PARAM_HASH}\" \"${PARAM_URL}\" /var/source/workspace"))
+		g.Expect(task.Steps[0].Env).Should(ContainElements(v1.EnvVar{Name: "PARAM_URL", Value: db.Spec.ScmInfo.SCMURL}, v1.EnvVar{Name: "PARAM_HASH", Value: "1234"}))
+		g.Expect(task.Steps[0].ComputeResources.Requests.Memory().String()).Should(Equal("512Mi"))
+		g.Expect(task.Steps[1].Script).Should(ContainSubstring("\"--source-path\" \"/var/source/workspace\""))
+		g.Expect(task.Volumes[0].Name).Should(Equal(WorkspaceSource))
+	})
+	t.Run("Test unsupported SCM type fails the build", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		db := v1alpha1.DependencyBuild{}
+		db.Namespace = metav1.NamespaceDefault
+		db.Name = "test"
+		db.Status.State = v1alpha1.DependencyBuildStateNew
+		db.Spec.ScmInfo.SCMURL = "some-url"
+		db.Spec.ScmInfo.SCMType = "cvs"
+
+		ctx := context.TODO()
+		client, reconciler := setupClientAndReconciler(&db)
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: db.Namespace, Name: db.Name}}))
+		build := getBuild(client, g)
+		g.Expect(build.Status.State).Should(Equal(v1alpha1.DependencyBuildStateFailed))
+		g.Expect(build.Status.Message).Should(ContainSubstring("cvs"))
+	})
+}
+
 func runBuildDiscoveryPipeline(db v1alpha1.DependencyBuild, g *WithT, reconciler *ReconcileDependencyBuild, client runtimeclient.Client, ctx context.Context, success bool) {
 	runBuildDiscoveryPipelineForResult(db, g, reconciler, client, ctx, success, `{"invocations":[{"commands":["maven","testgoal"],"toolVersion":{"maven":"3.8", "jdk": "11"},"tool": "maven"}],"enforceVersion":null,"repositories":["jboss","gradle"]}`)
 }
@@ -373,6 +442,11 @@ func TestStateBuilding(t *testing.T) {
 		g.Expect(client.Get(ctx, types.NamespacedName{Name: artifactbuild.CreateABRName(TestArtifact), Namespace: metav1.NamespaceDefault}, &ra)).Should(Succeed())
 		g.Expect(ra.Spec.GAV).Should(Equal(TestArtifact))
 		g.Expect(ra.Spec.Image).ShouldNot(BeNil())
+		g.Expect(ra.Status.Provenance).ShouldNot(BeNil())
+		g.Expect(ra.Status.Provenance.PredicateType).Should(Equal(SLSAProvenancePredicateType))
+		cm := v1.ConfigMap{}
+		g.Expect(client.Get(ctx, types.NamespacedName{Name: ra.Status.Provenance.ConfigMap, Namespace: metav1.NamespaceDefault}, &cm)).Should(Succeed())
+		g.Expect(cm.Data[ProvenanceStatementKey]).Should(ContainSubstring(TestArtifact))
 		pr = getBuildPipeline(client, g)
 		g.Expect(len(pr.Finalizers)).Should(Equal(1))
 
@@ -382,6 +456,146 @@ func TestStateBuilding(t *testing.T) {
 		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: db.Namespace, Name: pr.Name}}))
 		g.Expect(client.Get(ctx, types.NamespacedName{Name: pr.Name, Namespace: pr.Namespace}, pr)).ShouldNot(Succeed())
 	})
+	t.Run("Test reconcile building DependencyBuild with succeeded pipeline and SBOM", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		setup(g)
+		image, digest := testRegistryImage(t, g, nil)
+		sbom := `{"bomFormat":"CycloneDX","specVersion":"1.4","components":[{"name":"test"}]}`
+		tr := pipelinev1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "task", Namespace: metav1.NamespaceDefault},
+			Status: pipelinev1beta1.TaskRunStatus{
+				TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+					Results: []pipelinev1beta1.TaskRunResult{{Name: artifactbuild.PipelineResultSbom, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: sbom}}}},
+			},
+		}
+		g.Expect(client.Create(ctx, &tr)).Should(BeNil())
+		pr := getBuildPipeline(client, g)
+		pr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+		pr.Status.SetCondition(&apis.Condition{
+			Type:               apis.ConditionSucceeded,
+			Status:             "True",
+			LastTransitionTime: apis.VolatileTime{Inner: metav1.Time{Time: time.Now()}},
+		})
+		pr.Status.ChildReferences = []pipelinev1beta1.ChildStatusReference{{Name: "task"}}
+		pr.Status.Results = []pipelinev1beta1.PipelineRunResult{
+			{Name: PipelineResultImage, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: image}},
+			{Name: PipelineResultImageDigest, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: digest}},
+			{Name: artifactbuild.PipelineResultDeployedResources, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: TestArtifact}}}
+		g.Expect(client.Update(ctx, pr)).Should(BeNil())
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: taskRunName}))
+		ra := v1alpha1.RebuiltArtifact{}
+		g.Expect(client.Get(ctx, types.NamespacedName{Name: artifactbuild.CreateABRName(TestArtifact), Namespace: metav1.NamespaceDefault}, &ra)).Should(Succeed())
+		g.Expect(ra.Status.SBOM).ShouldNot(BeNil())
+		cm := v1.ConfigMap{}
+		g.Expect(client.Get(ctx, types.NamespacedName{Name: ra.Status.SBOM.ConfigMap, Namespace: metav1.NamespaceDefault}, &cm)).Should(Succeed())
+		g.Expect(cm.Data[BuildSbomKey]).Should(Equal(sbom))
+
+		//the SBOM should be discoverable from the deployed image
+		g.Expect(ra.Status.SBOM.Referrer).ShouldNot(BeEmpty())
+		ref, err := name.ParseReference(image)
+		g.Expect(err).NotTo(HaveOccurred())
+		referrers, err := remote.Referrers(ref.Context().Digest(digest))
+		g.Expect(err).NotTo(HaveOccurred())
+		manifest, err := referrers.IndexManifest()
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(manifest.Manifests).Should(HaveLen(1))
+		g.Expect(string(manifest.Manifests[0].ArtifactType)).Should(Equal(CycloneDXMediaType))
+		g.Expect(ra.Status.SBOM.Referrer).Should(HaveSuffix(manifest.Manifests[0].Digest.String()))
+	})
+	t.Run("Test reconcile building DependencyBuild with succeeded pipeline and large SBOM", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		setup(g)
+		image, digest := testRegistryImage(t, g, nil)
+		sbom := `{"bomFormat":"CycloneDX","specVersion":"1.4","components":[{"name":"` + strings.Repeat("a", maxSbomConfigMapSize) + `"}]}`
+		tr := pipelinev1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "task", Namespace: metav1.NamespaceDefault},
+			Status: pipelinev1beta1.TaskRunStatus{
+				TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+					Results: []pipelinev1beta1.TaskRunResult{{Name: artifactbuild.PipelineResultSbom, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: sbom}}}},
+			},
+		}
+		g.Expect(client.Create(ctx, &tr)).Should(BeNil())
+		pr := getBuildPipeline(client, g)
+		pr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+		pr.Status.SetCondition(&apis.Condition{
+			Type:               apis.ConditionSucceeded,
+			Status:             "True",
+			LastTransitionTime: apis.VolatileTime{Inner: metav1.Time{Time: time.Now()}},
+		})
+		pr.Status.ChildReferences = []pipelinev1beta1.ChildStatusReference{{Name: "task"}}
+		pr.Status.Results = []pipelinev1beta1.PipelineRunResult{
+			{Name: PipelineResultImage, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: image}},
+			{Name: PipelineResultImageDigest, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: digest}},
+			{Name: artifactbuild.PipelineResultDeployedResources, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: TestArtifact}}}
+		g.Expect(client.Update(ctx, pr)).Should(BeNil())
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: taskRunName}))
+		ra := v1alpha1.RebuiltArtifact{}
+		g.Expect(client.Get(ctx, types.NamespacedName{Name: artifactbuild.CreateABRName(TestArtifact), Namespace: metav1.NamespaceDefault}, &ra)).Should(Succeed())
+		g.Expect(ra.Status.SBOM).ShouldNot(BeNil())
+		//too large for a ConfigMap, so it is only attached to the image
+		g.Expect(ra.Status.SBOM.ConfigMap).Should(BeEmpty())
+		g.Expect(client.Get(ctx, types.NamespacedName{Name: pr.Name + sbomConfigMapNameSuffix, Namespace: metav1.NamespaceDefault}, &v1.ConfigMap{})).ShouldNot(Succeed())
+		g.Expect(ra.Status.SBOM.Referrer).ShouldNot(BeEmpty())
+	})
+	licensedBuild := func(g *WithT, policy *v1alpha1.LicensePolicy) {
+		jbsConfig := v1alpha1.JBSConfig{}
+		g.Expect(client.Get(ctx, types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: v1alpha1.JBSConfigName}, &jbsConfig)).Should(Succeed())
+		jbsConfig.Spec.LicensePolicy = policy
+		g.Expect(client.Update(ctx, &jbsConfig)).Should(Succeed())
+		tr := pipelinev1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "task", Namespace: metav1.NamespaceDefault},
+			Status: pipelinev1beta1.TaskRunStatus{
+				TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+					Results: []pipelinev1beta1.TaskRunResult{{Name: artifactbuild.PipelineResultLicenses, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: `{"` + TestArtifact + `":["Apache-2.0","GPL-3.0-only"]}`}}}},
+			},
+		}
+		g.Expect(client.Create(ctx, &tr)).Should(BeNil())
+		pr := getBuildPipeline(client, g)
+		pr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+		pr.Status.SetCondition(&apis.Condition{
+			Type:               apis.ConditionSucceeded,
+			Status:             "True",
+			LastTransitionTime: apis.VolatileTime{Inner: metav1.Time{Time: time.Now()}},
+		})
+		pr.Status.ChildReferences = []pipelinev1beta1.ChildStatusReference{{Name: "task"}}
+		pr.Status.Results = []pipelinev1beta1.PipelineRunResult{{Name: artifactbuild.PipelineResultDeployedResources, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: TestArtifact}}}
+		g.Expect(client.Update(ctx, pr)).Should(BeNil())
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: taskRunName}))
+	}
+	t.Run("Test reconcile building DependencyBuild with succeeded pipeline and licenses", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		setup(g)
+		licensedBuild(g, nil)
+		db := getBuild(client, g)
+		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateComplete))
+		g.Expect(meta.FindStatusCondition(db.Status.Conditions, v1alpha1.DependencyBuildConditionLicenseCompliant)).Should(BeNil())
+		ra := v1alpha1.RebuiltArtifact{}
+		g.Expect(client.Get(ctx, types.NamespacedName{Name: artifactbuild.CreateABRName(TestArtifact), Namespace: metav1.NamespaceDefault}, &ra)).Should(Succeed())
+		g.Expect(ra.Status.Licenses).Should(Equal([]string{"Apache-2.0", "GPL-3.0-only"}))
+	})
+	t.Run("Test reconcile building DependencyBuild with denied license flagged", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		setup(g)
+		licensedBuild(g, &v1alpha1.LicensePolicy{DeniedLicenses: []string{"gpl-3.0-only"}})
+		db := getBuild(client, g)
+		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateComplete))
+		condition := meta.FindStatusCondition(db.Status.Conditions, v1alpha1.DependencyBuildConditionLicenseCompliant)
+		g.Expect(condition).ShouldNot(BeNil())
+		g.Expect(condition.Status).Should(Equal(metav1.ConditionFalse))
+		g.Expect(condition.Message).Should(ContainSubstring(TestArtifact + " (GPL-3.0-only)"))
+		ra := v1alpha1.RebuiltArtifact{}
+		g.Expect(client.Get(ctx, types.NamespacedName{Name: artifactbuild.CreateABRName(TestArtifact), Namespace: metav1.NamespaceDefault}, &ra)).Should(Succeed())
+	})
+	t.Run("Test reconcile building DependencyBuild with denied license failing the build", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		setup(g)
+		licensedBuild(g, &v1alpha1.LicensePolicy{DeniedLicenses: []string{"GPL-3.0-only"}, FailBuild: true})
+		db := getBuild(client, g)
+		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateFailed))
+		g.Expect(db.Status.FailureReason).Should(Equal(v1alpha1.DependencyBuildFailureReasonDeniedLicense))
+		ra := v1alpha1.RebuiltArtifact{}
+		g.Expect(client.Get(ctx, types.NamespacedName{Name: artifactbuild.CreateABRName(TestArtifact), Namespace: metav1.NamespaceDefault}, &ra)).ShouldNot(Succeed())
+	})
 	t.Run("Test reconcile building DependencyBuild with failed pipeline", func(t *testing.T) {
 		g := NewGomegaWithT(t)
 		setup(g)
@@ -400,6 +614,22 @@ func TestStateBuilding(t *testing.T) {
 		db = getBuild(client, g)
 		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateFailed))
 	})
+	t.Run("Test reconcile building DependencyBuild with invalid recipe", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		setup(g)
+		db := getBuild(client, g)
+		db.Status.BuildAttempts[0].Build.PipelineName = "test-build-1"
+		db.Status.BuildAttempts[0].Recipe.Patches = []v1alpha1.BuildPatch{{URL: "https://example.com/fix.patch"}}
+		db.Status.BuildAttempts[0].Recipe.AdditionalDownloads = []v1alpha1.AdditionalDownload{{FileType: v1alpha1.AdditionalDownloadTypeExecutable, Uri: "https://example.com/tool"}}
+		g.Expect(client.Status().Update(ctx, db)).Should(Succeed())
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: buildName}))
+		db = getBuild(client, g)
+		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateFailed))
+		g.Expect(db.Status.FailureReason).Should(Equal(v1alpha1.DependencyBuildFailureReasonInvalidRecipe))
+		g.Expect(db.Status.Message).Should(And(ContainSubstring("patch 0"), ContainSubstring("https://example.com/tool")))
+		//no pipeline is run for the invalid recipe
+		g.Expect(client.Get(ctx, types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: "test-build-1"}, &pipelinev1beta1.PipelineRun{})).ShouldNot(Succeed())
+	})
 	t.Run("Test reconcile building DependencyBuild with OOMKilled Pipeline", func(t *testing.T) {
 		g := NewGomegaWithT(t)
 		setup(g)
@@ -471,7 +701,99 @@ func TestStateBuilding(t *testing.T) {
 
 		g.Expect(found).Should(BeTrue())
 	})
-	t.Run("Test reconcile building DependencyBuild with contaminants", func(t *testing.T) {
+	t.Run("Test reconcile building DependencyBuild with verified signature", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		setup(g)
+		pr := getBuildPipeline(client, g)
+		pr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+		pr.Status.SetCondition(&apis.Condition{
+			Type:               apis.ConditionSucceeded,
+			Status:             "True",
+			LastTransitionTime: apis.VolatileTime{Inner: metav1.Time{Time: time.Now()}},
+		})
+		pr.Status.Results = []pipelinev1beta1.PipelineRunResult{
+			{Name: PipelineResultSignatureVerification, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: "gpg:tag"}},
+			{Name: artifactbuild.PipelineResultDeployedResources, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: TestArtifact}},
+		}
+		g.Expect(client.Update(ctx, pr)).Should(BeNil())
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: taskRunName}))
+		db := getBuild(client, g)
+		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateComplete))
+		expected := &v1alpha1.SignatureVerificationResult{Verified: true, Type: v1alpha1.SignatureTypeGpg, SignedObject: "tag"}
+		g.Expect(db.Status.CurrentBuildAttempt().SignatureVerification).Should(Equal(expected))
+		ra := v1alpha1.RebuiltArtifact{}
+		g.Expect(client.Get(ctx, types.NamespacedName{Name: artifactbuild.CreateABRName(TestArtifact), Namespace: metav1.NamespaceDefault}, &ra)).Should(Succeed())
+		g.Expect(ra.Spec.SignatureVerification).Should(Equal(expected))
+	})
+	t.Run("Test reconcile building DependencyBuild with failed signature verification", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		setup(g)
+		db := getBuild(client, g)
+		db.Status.PotentialBuildRecipes = []*v1alpha1.BuildRecipe{{Image: "quay.io/redhat-appstudio/hacbs-jdk17-builder:latest"}}
+		g.Expect(client.Status().Update(ctx, db)).Should(BeNil())
+		pr := getBuildPipeline(client, g)
+		pr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+		pr.Status.SetCondition(&apis.Condition{
+			Type:               apis.ConditionSucceeded,
+			Status:             "False",
+			LastTransitionTime: apis.VolatileTime{Inner: metav1.Time{Time: time.Now()}},
+		})
+		tr := pipelinev1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "task", Namespace: pr.Namespace},
+			Status: pipelinev1beta1.TaskRunStatus{
+				TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+					Steps: []pipelinev1beta1.StepState{{Name: "git-clone-and-settings", ContainerState: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: SignatureVerificationExitCode}}}}},
+			},
+		}
+		g.Expect(client.Create(ctx, &tr)).Should(BeNil())
+		pr.Status.ChildReferences = []pipelinev1beta1.ChildStatusReference{{Name: "task"}}
+		g.Expect(client.Update(ctx, pr)).Should(BeNil())
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: taskRunName}))
+		db = getBuild(client, g)
+		//the remaining recipe is not tried
+		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateFailed))
+		g.Expect(db.Status.FailureReason).Should(Equal(v1alpha1.DependencyBuildFailureReasonSignatureVerification))
+		g.Expect(db.Status.CurrentBuildAttempt().SignatureVerification.Verified).Should(BeFalse())
+	})
+	t.Run("Test reconcile building DependencyBuild that is not reproducible", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		setup(g)
+		db := getBuild(client, g)
+		db.Status.PotentialBuildRecipes = []*v1alpha1.BuildRecipe{{Image: "quay.io/redhat-appstudio/hacbs-jdk17-builder:latest"}}
+		g.Expect(client.Status().Update(ctx, db)).Should(BeNil())
+		pr := getBuildPipeline(client, g)
+		pr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+		pr.Status.SetCondition(&apis.Condition{
+			Type:               apis.ConditionSucceeded,
+			Status:             "False",
+			LastTransitionTime: apis.VolatileTime{Inner: metav1.Time{Time: time.Now()}},
+		})
+		tr := pipelinev1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "task", Namespace: pr.Namespace},
+			Status: pipelinev1beta1.TaskRunStatus{
+				TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+					Steps:   []pipelinev1beta1.StepState{{Name: compareBuildsStep, ContainerState: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: NotReproducibleExitCode}}}},
+					Results: []pipelinev1beta1.TaskRunResult{{Name: PipelineResultReproducibility, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: "2\nChecksumMismatch com/test/test/1.0/test-1.0.jar\nOnlyInFirstBuild com/test/test/1.0/test-1.0-tests.jar\n"}}},
+				}},
+		}
+		g.Expect(client.Create(ctx, &tr)).Should(BeNil())
+		pr.Status.ChildReferences = []pipelinev1beta1.ChildStatusReference{{Name: "task"}}
+		g.Expect(client.Update(ctx, pr)).Should(BeNil())
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: taskRunName}))
+		db = getBuild(client, g)
+		//the remaining recipe is not tried
+		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateFailed))
+		g.Expect(db.Status.FailureReason).Should(Equal(v1alpha1.DependencyBuildFailureReasonNotReproducible))
+		g.Expect(db.Status.ReproducibilityDifferences).Should(Equal([]v1alpha1.ReproducibilityDifference{
+			{Reason: "ChecksumMismatch", File: "com/test/test/1.0/test-1.0.jar"},
+			{Reason: "OnlyInFirstBuild", File: "com/test/test/1.0/test-1.0-tests.jar"},
+		}))
+		condition := meta.FindStatusCondition(db.Status.Conditions, v1alpha1.DependencyBuildConditionReproducible)
+		g.Expect(condition).ShouldNot(BeNil())
+		g.Expect(condition.Status).Should(Equal(metav1.ConditionFalse))
+		g.Expect(condition.Message).Should(Equal("2 files differ between the two builds"))
+	})
+	t.Run("Test reconcile building DependencyBuild with verification report", func(t *testing.T) {
 		g := NewGomegaWithT(t)
 		setup(g)
 		pr := getBuildPipeline(client, g)
@@ -481,6 +803,72 @@ func TestStateBuilding(t *testing.T) {
 			Status:             "True",
 			LastTransitionTime: apis.VolatileTime{Inner: metav1.Time{Time: time.Now()}},
 		})
+		report := `{"artifacts":[{"gav":"com.test:test:1.0","file":"com/test/test/1.0/test-1.0.jar","passed":false,"differences":[{"category":"ClassAdded","count":2}],"allowedDifferences":[{"pattern":"^\\^:.*:version:","count":1}]}]}`
+		tr := pipelinev1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "task", Namespace: pr.Namespace},
+			Status: pipelinev1beta1.TaskRunStatus{
+				TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+					Results: []pipelinev1beta1.TaskRunResult{{Name: artifactbuild.PipelineResultVerificationReport, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: report}}},
+				}},
+		}
+		g.Expect(client.Create(ctx, &tr)).Should(BeNil())
+		pr.Status.ChildReferences = []pipelinev1beta1.ChildStatusReference{{Name: "task"}}
+		g.Expect(client.Update(ctx, pr)).Should(BeNil())
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: taskRunName}))
+		db := getBuild(client, g)
+		g.Expect(db.Status.CurrentBuildAttempt().VerificationReport).Should(Equal(&v1alpha1.VerificationReport{Artifacts: []v1alpha1.ArtifactVerification{{
+			GAV:                "com.test:test:1.0",
+			File:               "com/test/test/1.0/test-1.0.jar",
+			Differences:        []v1alpha1.VerificationDifferenceCount{{Category: "ClassAdded", Count: 2}},
+			AllowedDifferences: []v1alpha1.AllowedDifferenceCount{{Pattern: "^\\^:.*:version:", Count: 1}},
+		}}}))
+	})
+	t.Run("Test reconcile building DependencyBuild with large verification report", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		setup(g)
+		pr := getBuildPipeline(client, g)
+		pr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+		pr.Status.SetCondition(&apis.Condition{
+			Type:               apis.ConditionSucceeded,
+			Status:             "True",
+			LastTransitionTime: apis.VolatileTime{Inner: metav1.Time{Time: time.Now()}},
+		})
+		artifacts := []string{}
+		for i := 0; i < 500; i++ {
+			artifacts = append(artifacts, fmt.Sprintf(`{"gav":"com.test:test%d:1.0","file":"com/test/test%d/1.0/test%d-1.0.jar","passed":true}`, i, i, i))
+		}
+		report := `{"artifacts":[` + strings.Join(artifacts, ",") + `]}`
+		tr := pipelinev1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "task", Namespace: pr.Namespace},
+			Status: pipelinev1beta1.TaskRunStatus{
+				TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+					Results: []pipelinev1beta1.TaskRunResult{{Name: artifactbuild.PipelineResultVerificationReport, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: report}}},
+				}},
+		}
+		g.Expect(client.Create(ctx, &tr)).Should(BeNil())
+		pr.Status.ChildReferences = []pipelinev1beta1.ChildStatusReference{{Name: "task"}}
+		g.Expect(client.Update(ctx, pr)).Should(BeNil())
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: taskRunName}))
+		db := getBuild(client, g)
+		verification := db.Status.CurrentBuildAttempt().VerificationReport
+		g.Expect(verification.Artifacts).Should(BeEmpty())
+		g.Expect(verification.ConfigMap).Should(Equal(pr.Name + "-verification"))
+		cm := v1.ConfigMap{}
+		g.Expect(client.Get(ctx, types.NamespacedName{Namespace: pr.Namespace, Name: verification.ConfigMap}, &cm)).Should(BeNil())
+		g.Expect(cm.Data[VerificationReportKey]).Should(Equal(report))
+	})
+	contaminatedBuild := func(g *WithT, policy *v1alpha1.ContaminantPolicy, deployed string) {
+		jbsConfig := v1alpha1.JBSConfig{}
+		g.Expect(client.Get(ctx, types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: v1alpha1.JBSConfigName}, &jbsConfig)).Should(Succeed())
+		jbsConfig.Spec.ContaminantPolicy = policy
+		g.Expect(client.Update(ctx, &jbsConfig)).Should(Succeed())
+		pr := getBuildPipeline(client, g)
+		pr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+		pr.Status.SetCondition(&apis.Condition{
+			Type:               apis.ConditionSucceeded,
+			Status:             "True",
+			LastTransitionTime: apis.VolatileTime{Inner: metav1.Time{Time: time.Now()}},
+		})
 		ab := v1alpha1.ArtifactBuild{}
 		//we need an abr for this to be considered contaminated
 		ab.Name = artifactbuild.CreateABRName(TestArtifact)
@@ -488,6 +876,10 @@ func TestStateBuilding(t *testing.T) {
 		ab.Spec.GAV = TestArtifact
 		g.Expect(client.Create(ctx, &ab)).Should(BeNil())
 		pr.Status.Results = []pipelinev1beta1.PipelineRunResult{{Name: "contaminants", Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: "[{\"gav\": \"com.acme:foo:1.0\", \"contaminatedArtifacts\": [\"" + TestArtifact + "\"]}]"}}}
+		if deployed != "" {
+			//the deploy step only deploys contaminated artifacts if the contaminant is allowed
+			pr.Status.Results = append(pr.Status.Results, pipelinev1beta1.PipelineRunResult{Name: artifactbuild.PipelineResultDeployedResources, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: deployed}})
+		}
 		g.Expect(client.Update(ctx, pr)).Should(BeNil())
 		db := getBuild(client, g)
 		g.Expect(controllerutil.SetOwnerReference(&ab, db, reconciler.scheme)).Should(BeNil())
@@ -495,10 +887,59 @@ func TestStateBuilding(t *testing.T) {
 		g.Expect(client.Update(ctx, db))
 		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: taskRunName}))
 		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: buildName}))
-		db = getBuild(client, g)
+	}
+	t.Run("Test reconcile building DependencyBuild with contaminants", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		setup(g)
+		contaminatedBuild(g, nil, "")
+		db := getBuild(client, g)
 		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateContaminated))
+		abr := v1alpha1.ArtifactBuild{}
+		g.Expect(client.Get(ctx, types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: artifactbuild.CreateABRName("com.acme:foo:1.0")}, &abr)).Should(Succeed())
 	})
+	t.Run("Test reconcile building DependencyBuild with allowed contaminants", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		setup(g)
+		contaminatedBuild(g, &v1alpha1.ContaminantPolicy{AllowedContaminants: []v1alpha1.AllowedContaminant{{GAV: "com.acme:*"}}}, TestArtifact)
+		db := getBuild(client, g)
+		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateComplete))
+		g.Expect(db.Status.DeployedArtifacts).Should(Equal([]string{TestArtifact}))
+		g.Expect(db.Status.Contaminants).Should(BeEmpty())
+		g.Expect(db.Status.AllowedContaminants).Should(Equal([]v1alpha1.Contaminant{{GAV: "com.acme:foo:1.0", ContaminatedArtifacts: []string{TestArtifact}}}))
+		abr := v1alpha1.ArtifactBuild{}
+		g.Expect(errors.IsNotFound(client.Get(ctx, types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: artifactbuild.CreateABRName("com.acme:foo:1.0")}, &abr))).Should(BeTrue())
+	})
+	t.Run("Test reconcile building DependencyBuild with contaminants allowed in other artifacts", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		setup(g)
+		contaminatedBuild(g, &v1alpha1.ContaminantPolicy{AllowedContaminants: []v1alpha1.AllowedContaminant{{GAV: "com.acme:foo:1.0", Artifact: "com.other:*"}}}, "")
+		db := getBuild(client, g)
+		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateContaminated))
+		g.Expect(db.Status.AllowedContaminants).Should(BeEmpty())
+	})
+
+}
 
+func TestSplitContaminants(t *testing.T) {
+	g := NewGomegaWithT(t)
+	policy := &v1alpha1.ContaminantPolicy{AllowedContaminants: []v1alpha1.AllowedContaminant{
+		{GAV: "com.google.guava:*"},
+		{GAV: "org.slf4j:slf4j-api:*", Artifact: "com.test:shaded:*"},
+		{GAV: "[invalid"},
+	}}
+	remaining, allowed := splitContaminants(policy, []v1alpha1.Contaminant{
+		{GAV: "com.google.guava:guava:31.1-jre", ContaminatedArtifacts: []string{"com.test:a:1.0", "com.test:shaded:1.0"}},
+		{GAV: "org.slf4j:slf4j-api:2.0.7", ContaminatedArtifacts: []string{"com.test:a:1.0", "com.test:shaded:1.0"}},
+		{GAV: "[invalid", ContaminatedArtifacts: []string{"com.test:a:1.0"}},
+	})
+	g.Expect(allowed).Should(Equal([]v1alpha1.Contaminant{
+		{GAV: "com.google.guava:guava:31.1-jre", ContaminatedArtifacts: []string{"com.test:a:1.0", "com.test:shaded:1.0"}},
+		{GAV: "org.slf4j:slf4j-api:2.0.7", ContaminatedArtifacts: []string{"com.test:shaded:1.0"}},
+	}))
+	g.Expect(remaining).Should(Equal([]v1alpha1.Contaminant{
+		{GAV: "org.slf4j:slf4j-api:2.0.7", ContaminatedArtifacts: []string{"com.test:a:1.0"}},
+		{GAV: "[invalid", ContaminatedArtifacts: []string{"com.test:a:1.0"}},
+	}))
 }
 
 func TestStateDependencyBuildStateAnalyzeBuild(t *testing.T) {
@@ -615,4 +1056,550 @@ func TestStateDependencyBuildStateAnalyzeBuild(t *testing.T) {
 		g.Expect(ra.Spec.Digest).Should(Equal("sha256:0a959a76264f7a34f1d6793ce3fb0d37b8a12768483f075cd644265b258477e3"))
 		g.Expect(ra.Spec.Image).Should(Equal("quay.io/dummy-namespace/jvm-build-service-artifacts:4f8a8179ceadcde76e4cbc037dd7c9fd"))
 	})
+
+	t.Run("Test build info discovery with no matching builder image is re-queued when a builder is added", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		setup(g)
+		buildInfoJson, err := json.Marshal(marshalledBuildInfo{Invocations: []invocation{{Tool: "maven", Commands: []string{"install"}, ToolVersion: map[string]string{"maven": "3.9.5", "jdk": "21"}}}})
+		g.Expect(err).Should(BeNil())
+		pr := getBuildInfoPipeline(client, g)
+		pr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+		pr.Status.Results = []pipelinev1beta1.PipelineRunResult{{Name: BuildInfoPipelineResultBuildInfo, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: string(buildInfoJson)}}}
+		pr.Status.SetCondition(&apis.Condition{
+			Type:               apis.ConditionSucceeded,
+			Status:             "True",
+			LastTransitionTime: apis.VolatileTime{Inner: metav1.Time{Time: time.Now()}},
+		})
+		g.Expect(client.Status().Update(ctx, pr)).Should(BeNil())
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: taskRunName}))
+
+		db := getBuild(client, g)
+		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateSubmitBuild))
+		g.Expect(db.Status.PotentialBuildRecipes).Should(BeEmpty())
+		g.Expect(db.Status.UnmatchedBuildRecipes).Should(HaveLen(1))
+		buildName := types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: "test"}
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: buildName}))
+		db = getBuild(client, g)
+		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateFailed))
+		g.Expect(db.Status.FailureReason).Should(Equal(v1alpha1.DependencyBuildFailureReasonNoBuilderImage))
+		g.Expect(db.Status.Message).Should(ContainSubstring("jdk:21,maven:3.9.5"))
+		g.Expect(unmatchedBuildRequests(ctx, client)).Should(Equal([]reconcile.Request{{NamespacedName: buildName}}))
+
+		//still no match, so nothing changes
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: buildName}))
+		g.Expect(getBuild(client, g).Status.State).Should(Equal(v1alpha1.DependencyBuildStateFailed))
+
+		sysConfig := v1alpha1.SystemConfig{}
+		g.Expect(client.Get(ctx, types.NamespacedName{Name: systemconfig.SystemConfigKey}, &sysConfig)).Should(Succeed())
+		sysConfig.Spec.Builders["jdk21"] = v1alpha1.BuilderImageInfo{Image: "quay.io/redhat-appstudio/hacbs-jdk21-builder:latest", Tag: "jdk:21,maven:3.9.5"}
+		g.Expect(client.Update(ctx, &sysConfig)).Should(Succeed())
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: buildName}))
+		db = getBuild(client, g)
+		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateSubmitBuild))
+		g.Expect(db.Status.FailureReason).Should(BeEmpty())
+		g.Expect(db.Status.UnmatchedBuildRecipes).Should(BeEmpty())
+		g.Expect(db.Status.PotentialBuildRecipes).Should(HaveLen(1))
+		g.Expect(db.Status.PotentialBuildRecipes[0].Image).Should(Equal("quay.io/redhat-appstudio/hacbs-jdk21-builder:latest"))
+		g.Expect(db.Status.Requeues).Should(HaveLen(1))
+		g.Expect(db.Status.Requeues[0].Reason).Should(Equal("builder images quay.io/redhat-appstudio/hacbs-jdk21-builder:latest now provide jdk:21,maven:3.9.5"))
+		g.Expect(unmatchedBuildRequests(ctx, client)).Should(BeEmpty())
+	})
+}
+
+func TestPersistentWorkspaces(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+	client, reconciler := setupClientAndReconciler()
+	buildName := types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: "test"}
+
+	jbsConfig := v1alpha1.JBSConfig{}
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: v1alpha1.JBSConfigName}, &jbsConfig)).Should(Succeed())
+	jbsConfig.Spec.BuildSettings.WorkspaceStorage = "2Gi"
+	jbsConfig.Spec.BuildSettings.StorageClassName = "fast"
+	jbsConfig.Spec.BuildSettings.ToolCaches = []v1alpha1.ToolCacheType{v1alpha1.ToolCacheGradle, v1alpha1.ToolCacheMaven}
+	g.Expect(client.Update(ctx, &jbsConfig)).Should(Succeed())
+
+	db := v1alpha1.DependencyBuild{}
+	db.Namespace = metav1.NamespaceDefault
+	db.Name = "test"
+	db.Status.State = v1alpha1.DependencyBuildStateBuilding
+	db.Status.BuildAttempts = []*v1alpha1.BuildAttempt{
+		{
+			Recipe: &v1alpha1.BuildRecipe{Image: "quay.io/redhat-appstudio/hacbs-jdk11-builder:latest", Tool: "gradle"},
+			Build:  &v1alpha1.BuildPipelineRun{PipelineName: "test-build-0"},
+		},
+	}
+	db.Spec.ScmInfo.SCMURL = "some-url"
+	db.Spec.ScmInfo.Tag = "some-tag"
+	g.Expect(client.Create(ctx, &db)).Should(Succeed())
+
+	g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: buildName}))
+	pr := getBuildPipeline(client, g)
+	workspaces := map[string]pipelinev1beta1.WorkspaceBinding{}
+	for _, i := range pr.Spec.Workspaces {
+		workspaces[i.Name] = i
+	}
+	g.Expect(workspaces[WorkspaceBuildSettings].EmptyDir).ShouldNot(BeNil())
+	g.Expect(workspaces[WorkspaceSource].VolumeClaimTemplate).ShouldNot(BeNil())
+	g.Expect(*workspaces[WorkspaceSource].VolumeClaimTemplate.Spec.StorageClassName).Should(Equal("fast"))
+	g.Expect(workspaces[WorkspaceSource].VolumeClaimTemplate.Spec.Resources.Requests.Storage().String()).Should(Equal("2Gi"))
+	g.Expect(workspaces[WorkspaceToolCache].PersistentVolumeClaim.ClaimName).Should(Equal("test" + ToolCacheSuffix))
+	g.Expect(pr.Spec.PipelineSpec.Workspaces).Should(ContainElement(pipelinev1beta1.PipelineWorkspaceDeclaration{Name: WorkspaceToolCache}))
+	for _, task := range pr.Spec.PipelineSpec.Tasks {
+		if task.Name == artifactbuild.PreBuildTaskName {
+			g.Expect(task.TaskSpec.Steps[0].Script).Should(ContainSubstring("export GRADLE_USER_HOME_CACHE=\"$(workspaces.tool-cache.path)/gradle\""))
+			g.Expect(task.TaskSpec.Steps[0].Script).Should(ContainSubstring("ln -s \"$(workspaces.tool-cache.path)/m2\" \"$HOME/.m2\""))
+		}
+	}
+
+	pvc := v1.PersistentVolumeClaim{}
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: "test" + ToolCacheSuffix}, &pvc)).Should(Succeed())
+	g.Expect(pvc.Spec.Resources.Requests.Storage().String()).Should(Equal(v1alpha1.ToolCacheStorageDefault))
+	g.Expect(pvc.OwnerReferences[0].Name).Should(Equal("test"))
+
+	dbFromClient := getBuild(client, g)
+	dbFromClient.Status.State = v1alpha1.DependencyBuildStateComplete
+	g.Expect(client.Status().Update(ctx, dbFromClient)).Should(Succeed())
+	g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: buildName}))
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: "test" + ToolCacheSuffix}, &pvc)).ShouldNot(Succeed())
+
+	//later reconciles of the finished build do not try to delete it again
+	counting := &deleteCountingClient{Client: client}
+	reconciler.client = counting
+	g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: buildName}))
+	g.Expect(counting.deletes).Should(Equal(0))
+}
+
+type deleteCountingClient struct {
+	runtimeclient.Client
+	deletes int
+}
+
+func (c *deleteCountingClient) Delete(ctx context.Context, obj runtimeclient.Object, opts ...runtimeclient.DeleteOption) error {
+	c.deletes++
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func TestReproducibilityPipeline(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+	client, reconciler := setupClientAndReconciler()
+	buildName := types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: "test"}
+
+	jbsConfig := v1alpha1.JBSConfig{}
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: v1alpha1.JBSConfigName}, &jbsConfig)).Should(Succeed())
+	jbsConfig.Spec.ReproducibilityCheck = &v1alpha1.ReproducibilityCheck{Required: true, AntiAffinity: true}
+	g.Expect(client.Update(ctx, &jbsConfig)).Should(Succeed())
+
+	db := v1alpha1.DependencyBuild{}
+	db.Namespace = metav1.NamespaceDefault
+	db.Name = "test"
+	db.Status.State = v1alpha1.DependencyBuildStateBuilding
+	db.Status.BuildAttempts = []*v1alpha1.BuildAttempt{
+		{
+			Recipe: &v1alpha1.BuildRecipe{Image: "quay.io/redhat-appstudio/hacbs-jdk11-builder:latest", Tool: "maven"},
+			Build:  &v1alpha1.BuildPipelineRun{PipelineName: "test-build-0"},
+		},
+	}
+	db.Spec.ScmInfo.SCMURL = "some-url"
+	db.Spec.ScmInfo.Tag = "some-tag"
+	g.Expect(client.Create(ctx, &db)).Should(Succeed())
+
+	g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: buildName}))
+	pr := getBuildPipeline(client, g)
+	workspaces := map[string]pipelinev1beta1.WorkspaceBinding{}
+	for _, i := range pr.Spec.Workspaces {
+		workspaces[i.Name] = i
+	}
+	g.Expect(workspaces[WorkspaceReproducibility].EmptyDir).ShouldNot(BeNil())
+	g.Expect(pr.Spec.PipelineSpec.Workspaces).Should(ContainElement(pipelinev1beta1.PipelineWorkspaceDeclaration{Name: WorkspaceReproducibility}))
+	tasks := map[string]pipelinev1beta1.PipelineTask{}
+	for _, task := range pr.Spec.PipelineSpec.Tasks {
+		tasks[task.Name] = task
+	}
+	//the second build runs in parallel with the first one in its own workspace
+	reproducibility := tasks[artifactbuild.ReproducibilityTaskName]
+	g.Expect(reproducibility.RunAfter).Should(Equal([]string{artifactbuild.PreBuildTaskName}))
+	g.Expect(reproducibility.Workspaces).Should(ContainElement(pipelinev1beta1.WorkspacePipelineTaskBinding{Name: WorkspaceSource, Workspace: WorkspaceReproducibility}))
+	g.Expect(reproducibility.TaskSpec.Steps[0].Name).Should(Equal(reproducibilityBuildStep))
+	g.Expect(reproducibility.TaskSpec.Steps[1].Name).Should(Equal(artifactChecksumsStep))
+	g.Expect(tasks[artifactbuild.BuildTaskName].TaskSpec.Steps[1].Name).Should(Equal(artifactChecksumsStep))
+	//the output is compared before anything is tagged
+	tag := tasks[artifactbuild.TagTaskName]
+	g.Expect(tag.RunAfter).Should(ContainElement(artifactbuild.ReproducibilityTaskName))
+	g.Expect(tag.TaskSpec.Steps[0].Name).Should(Equal(compareBuildsStep))
+	g.Expect(tag.TaskSpec.Steps[0].Script).Should(ContainSubstring("exit 66"))
+	g.Expect(tag.Params).Should(ContainElement(HaveField("Value.StringVal", "$(tasks."+artifactbuild.ReproducibilityTaskName+".results."+PipelineResultArtifactChecksums+")")))
+
+	g.Expect(pr.Spec.TaskRunSpecs).Should(HaveLen(1))
+	g.Expect(pr.Spec.TaskRunSpecs[0].PipelineTaskName).Should(Equal(artifactbuild.ReproducibilityTaskName))
+	term := pr.Spec.TaskRunSpecs[0].PodTemplate.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0]
+	g.Expect(term.TopologyKey).Should(Equal(v1.LabelHostname))
+	g.Expect(term.LabelSelector.MatchLabels).Should(HaveKeyWithValue("tekton.dev/pipelineRun", pr.Name))
+	g.Expect(term.LabelSelector.MatchLabels).Should(HaveKeyWithValue("tekton.dev/pipelineTask", artifactbuild.BuildTaskName))
+}
+
+func TestPinnedBuilderImage(t *testing.T) {
+	g := NewGomegaWithT(t)
+	image := "quay.io/redhat-appstudio/hacbs-jdk11-builder:latest"
+	digest := "sha256:" + strings.Repeat("a", 64)
+	systemConfig := &v1alpha1.SystemConfig{
+		Spec:   v1alpha1.SystemConfigSpec{Builders: map[string]v1alpha1.BuilderImageInfo{"jdk11": {Image: image, Tag: "jdk:11"}}},
+		Status: v1alpha1.SystemConfigStatus{Builders: map[string]v1alpha1.BuilderImageStatus{"jdk11": {Valid: true, Reachable: true, Digest: digest}}},
+	}
+	g.Expect(pinnedBuilderImage(logr.Discard(), systemConfig, image)).Should(Equal(image))
+	systemConfig.Spec.PinBuilderImages = true
+	g.Expect(pinnedBuilderImage(logr.Discard(), systemConfig, image)).Should(Equal("quay.io/redhat-appstudio/hacbs-jdk11-builder@" + digest))
+	g.Expect(pinnedBuilderImage(logr.Discard(), systemConfig, "quay.io/redhat-appstudio/hacbs-jdk17-builder:latest")).Should(Equal("quay.io/redhat-appstudio/hacbs-jdk17-builder:latest"))
+}
+
+func TestRecommendedResources(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+	g.Expect(parseResourceUsage("git-clone-and-settings 104857600 20\nbuild 2147483648 450\ninvalid\n")).Should(Equal([]v1alpha1.StepResourceUsage{
+		{Step: "git-clone-and-settings", PeakMemoryBytes: 104857600, AverageCPUMillicores: 20},
+		{Step: "build", PeakMemoryBytes: 2147483648, AverageCPUMillicores: 450},
+	}))
+	g.Expect(withResourceUsage("build", "#!/bin/sh\necho hello")).Should(And(HavePrefix("#!/bin/sh\n"), ContainSubstring("echo \"build $MEMORY"), ContainSubstring("$(results.RESOURCE_USAGE.path)"), HaveSuffix("echo hello")))
+
+	previous := v1alpha1.DependencyBuild{ObjectMeta: metav1.ObjectMeta{Name: "previous", Namespace: metav1.NamespaceDefault, Labels: map[string]string{artifactbuild.ScmUrlLabel: util.HashString("https://github.com/foo/bar")}}, Spec: v1alpha1.DependencyBuildSpec{ScmInfo: v1alpha1.SCMInfo{SCMURL: "https://github.com/foo/bar", Tag: "1.0"}}}
+	previous.Status.BuildAttempts = []*v1alpha1.BuildAttempt{{Recipe: &v1alpha1.BuildRecipe{}, ResourceUsage: []v1alpha1.StepResourceUsage{{Step: "git-clone-and-settings", PeakMemoryBytes: 8589934592}, {Step: "build", PeakMemoryBytes: 2147483648, AverageCPUMillicores: 450}}}}
+	other := v1alpha1.DependencyBuild{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: metav1.NamespaceDefault, Labels: map[string]string{artifactbuild.ScmUrlLabel: util.HashString("https://github.com/foo/other")}}, Spec: v1alpha1.DependencyBuildSpec{ScmInfo: v1alpha1.SCMInfo{SCMURL: "https://github.com/foo/other", Tag: "1.0"}}}
+	other.Status.BuildAttempts = []*v1alpha1.BuildAttempt{{Recipe: &v1alpha1.BuildRecipe{}, ResourceUsage: []v1alpha1.StepResourceUsage{{Step: "build", PeakMemoryBytes: 8589934592, AverageCPUMillicores: 4000}}}}
+	db := v1alpha1.DependencyBuild{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault, Labels: map[string]string{artifactbuild.ScmUrlLabel: util.HashString("https://github.com/foo/bar")}}, Spec: v1alpha1.DependencyBuildSpec{ScmInfo: v1alpha1.SCMInfo{SCMURL: "https://github.com/foo/bar", Tag: "2.0"}}}
+	_, reconciler := setupClientAndReconciler(&previous, &other, &db)
+	jbsConfig := &v1alpha1.JBSConfig{}
+
+	//2Gi peak plus headroom against the default 1Gi build limit, rounded up to the memory increment
+	memory, cpu, err := reconciler.recommendedResources(ctx, &db, jbsConfig)
+	g.Expect(err).Should(BeNil())
+	g.Expect(memory).Should(Equal(1536))
+	g.Expect(cpu).Should(Equal("600m"))
+
+	//the default is already enough
+	jbsConfig.Spec.BuildSettings.BuildRequestMemory = "4096Mi"
+	jbsConfig.Spec.BuildSettings.BuildRequestCPU = "1"
+	memory, cpu, err = reconciler.recommendedResources(ctx, &db, jbsConfig)
+	g.Expect(err).Should(BeNil())
+	g.Expect(memory).Should(Equal(0))
+	g.Expect(cpu).Should(Equal(""))
+
+	//a build that ran out of memory needs at least the next increment
+	previous.Status.BuildAttempts = append(previous.Status.BuildAttempts, &v1alpha1.BuildAttempt{Recipe: &v1alpha1.BuildRecipe{AdditionalMemory: 1024}, ResourceUsage: []v1alpha1.StepResourceUsage{{Step: "build", OOMKilled: true}}})
+	_, reconciler = setupClientAndReconciler(&previous, &other, &db)
+	memory, _, err = reconciler.recommendedResources(ctx, &db, jbsConfig)
+	g.Expect(err).Should(BeNil())
+	g.Expect(memory).Should(Equal(2048))
+
+	resources, err := stepResources(jbsConfig, "600m", 0)
+	g.Expect(err).Should(BeNil())
+	g.Expect(resources["build"].Limits[v1.ResourceCPU]).Should(Equal(resource.MustParse("600m")))
+	g.Expect(resources["preprocessor"].Limits[v1.ResourceCPU]).Should(Equal(resource.MustParse("300m")))
+}
+
+func TestProvenanceStatement(t *testing.T) {
+	g := NewGomegaWithT(t)
+	db := v1alpha1.DependencyBuild{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault}, Spec: v1alpha1.DependencyBuildSpec{ScmInfo: v1alpha1.SCMInfo{SCMURL: "https://github.com/foo/bar", Tag: "1.0", CommitHash: "72bbbf2f3ac37bc1ab3a0bc1e1d1b7e5ba5b1a38"}}}
+	pr := pipelinev1beta1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "test-build-0", Namespace: metav1.NamespaceDefault}}
+	pr.Spec.PipelineSpec = &pipelinev1beta1.PipelineSpec{Tasks: []pipelinev1beta1.PipelineTask{{Name: artifactbuild.BuildTaskName}, {Name: artifactbuild.HermeticBuildTaskName}}}
+	pr.Status.Results = []pipelinev1beta1.PipelineRunResult{{Name: artifactbuild.PipelineResultPassedVerification, Value: pipelinev1beta1.ResultValue{Type: pipelinev1beta1.ParamTypeString, StringVal: "true"}}}
+	recipe := v1alpha1.BuildRecipe{Tool: "maven", Image: "quay.io/redhat-appstudio/hacbs-jdk17-builder@sha256:12d5b8ba7bd3a3a4b1c4bd4e5ed0b4fc3bb2a1a6ee0d2c8d0e3e1fb4c3c0c1d2"}
+
+	data, err := provenanceStatement(&pr, &db, &recipe, "com.foo:bar:1.0", "quay.io/test/artifacts:abc", "sha256:abcdef")
+	g.Expect(err).Should(BeNil())
+	statement := inTotoStatement{}
+	g.Expect(json.Unmarshal(data, &statement)).Should(Succeed())
+	g.Expect(statement.Type).Should(Equal(InTotoStatementType))
+	g.Expect(statement.PredicateType).Should(Equal(SLSAProvenancePredicateType))
+	g.Expect(statement.Subject).Should(Equal([]provenanceDescriptor{{Name: "quay.io/test/artifacts", Digest: map[string]string{"sha256": "abcdef"}}}))
+	definition := statement.Predicate.BuildDefinition
+	g.Expect(definition.ExternalParameters["gav"]).Should(Equal("com.foo:bar:1.0"))
+	g.Expect(definition.InternalParameters).Should(Equal(map[string]interface{}{"hermetic": true, "verified": true}))
+	g.Expect(definition.ResolvedDependencies).Should(Equal([]provenanceDescriptor{
+		{URI: "git+https://github.com/foo/bar@1.0", Digest: map[string]string{"gitCommit": "72bbbf2f3ac37bc1ab3a0bc1e1d1b7e5ba5b1a38"}},
+		{URI: recipe.Image, Digest: map[string]string{"sha256": "12d5b8ba7bd3a3a4b1c4bd4e5ed0b4fc3bb2a1a6ee0d2c8d0e3e1fb4c3c0c1d2"}},
+	}))
+	g.Expect(statement.Predicate.RunDetails.Metadata.InvocationID).Should(Equal("default/test-build-0"))
+}
+
+// testRegistryImage pushes a random image with the given labels to a test registry
+func testRegistryImage(t *testing.T, g *WithT, labels map[string]string) (string, string) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	image := strings.TrimPrefix(server.URL, "http://") + "/test/artifacts:abc"
+	img, err := random.Image(1024, 1)
+	g.Expect(err).NotTo(HaveOccurred())
+	config, err := img.ConfigFile()
+	g.Expect(err).NotTo(HaveOccurred())
+	config.Config.Labels = labels
+	img, err = mutate.ConfigFile(img, config)
+	g.Expect(err).NotTo(HaveOccurred())
+	ref, err := name.ParseReference(image)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(remote.Write(ref, img)).Should(Succeed())
+	digest, err := img.Digest()
+	g.Expect(err).NotTo(HaveOccurred())
+	return image, digest.String()
+}
+
+// testSigningKey creates a cosign key pair, returning the secret with the public key
+func testSigningKey(g *WithT, secretName string) (*ecdsa.PrivateKey, *v1.Secret) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	g.Expect(err).NotTo(HaveOccurred())
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	g.Expect(err).NotTo(HaveOccurred())
+	publicKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	return key, &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: metav1.NamespaceDefault}, Data: map[string][]byte{v1alpha1.CosignPublicKeyKey: publicKey}}
+}
+
+// testSignImage pushes a cosign signature for the image made with the given keys
+func testSignImage(g *WithT, image string, digest string, keys ...*ecdsa.PrivateKey) string {
+	ref, err := name.ParseReference(image)
+	g.Expect(err).NotTo(HaveOccurred())
+	payload := []byte(`{"critical":{"identity":{"docker-reference":"` + ref.Context().Name() + `"},"image":{"docker-manifest-digest":"` + digest + `"},"type":"cosign container image signature"},"optional":null}`)
+	hash := sha256.Sum256(payload)
+	signatureImage := empty.Image
+	for _, key := range keys {
+		sig, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+		g.Expect(err).NotTo(HaveOccurred())
+		signatureImage, err = mutate.Append(signatureImage, mutate.Addendum{
+			Layer:       static.NewLayer(payload, "application/vnd.dev.cosign.simplesigning.v1+json"),
+			Annotations: map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(sig)},
+		})
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	signature, err := imageSignatureReference(image, digest)
+	g.Expect(err).NotTo(HaveOccurred())
+	signatureRef, err := name.ParseReference(signature)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(remote.Write(signatureRef, signatureImage)).Should(Succeed())
+	return signature
+}
+
+func TestImageSigning(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+	image, digest := testRegistryImage(t, g, nil)
+	key, keySecret := testSigningKey(g, "cosign")
+	jbsConfig := v1alpha1.JBSConfig{ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.JBSConfigName, Namespace: metav1.NamespaceDefault}, Spec: v1alpha1.JBSConfigSpec{ImageSigning: &v1alpha1.ImageSigning{KeySecret: "cosign"}}}
+	db := v1alpha1.DependencyBuild{}
+	_, reconciler := setupClientAndReconciler(keySecret)
+
+	//there is no signature yet
+	_, err := reconciler.verifySharedImage(ctx, &jbsConfig, &db, image, digest)
+	g.Expect(err).To(HaveOccurred())
+
+	signature := testSignImage(g, image, digest, key)
+	g.Expect(signature).Should(HaveSuffix("/test/artifacts:" + strings.Replace(digest, ":", "-", 1) + ".sig"))
+	verified, err := reconciler.verifySharedImage(ctx, &jbsConfig, &db, image, digest)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(verified).Should(Equal(signature))
+
+	//a signature made with a different key is not trusted
+	_, otherKeySecret := testSigningKey(g, "other")
+	_, err = verifyImageSignature(ctx, image, digest, otherKeySecret.Data[v1alpha1.CosignPublicKeyKey], authn.DefaultKeychain)
+	g.Expect(err).To(HaveOccurred())
+
+	task := signTask("gcr.io/projectsigstore/cosign@"+digest, &jbsConfig, v1.ResourceRequirements{})
+	g.Expect(task.Steps[0].Image).Should(Equal("gcr.io/projectsigstore/cosign@" + digest))
+	g.Expect(task.Steps[0].Args).Should(Equal([]string{"sign", "--yes", "--tlog-upload=false", "--key", "/cosign/cosign.key", "$(params.IMAGE_URL)@$(params.DEPLOYED_IMAGE_DIGEST)"}))
+	g.Expect(task.Volumes[0].Secret.SecretName).Should(Equal("cosign"))
+}
+
+func TestSharedRegistryTrustPolicy(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+	image, digest := testRegistryImage(t, g, map[string]string{
+		"io.jvmbuildservice.scm-uri":    "https://github.com/foo/bar",
+		"io.jvmbuildservice.scm-commit": "72bbbf2f3ac37bc1ab3a0bc1e1d1b7e5ba5b1a38",
+		"io.jvmbuildservice.hermetic":   "false",
+	})
+	host, _, _ := strings.Cut(image, "/")
+	hostName, port, _ := strings.Cut(host, ":")
+	first, firstSecret := testSigningKey(g, "first")
+	second, secondSecret := testSigningKey(g, "second")
+	policy := &v1alpha1.SharedRegistryTrustPolicy{SignerKeySecrets: []string{"first", "second"}, RequiredProvenance: []string{ProvenanceScmUri, ProvenanceScmCommit}}
+	jbsConfig := v1alpha1.JBSConfig{ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.JBSConfigName, Namespace: metav1.NamespaceDefault}, Spec: v1alpha1.JBSConfigSpec{
+		SharedRegistries: []v1alpha1.ImageRegistry{{Host: "quay.io", Owner: "other", Repository: "artifacts"}, {Host: hostName, Port: port, Owner: "test", Repository: "artifacts", TrustPolicy: policy}},
+	}}
+	db := v1alpha1.DependencyBuild{Spec: v1alpha1.DependencyBuildSpec{ScmInfo: v1alpha1.SCMInfo{SCMURL: "https://github.com/foo/bar", CommitHash: "72bbbf2f3ac37bc1ab3a0bc1e1d1b7e5ba5b1a38"}}}
+	_, reconciler := setupClientAndReconciler(firstSecret, secondSecret)
+	g.Expect(sharedRegistryTrustPolicy(&jbsConfig, image)).Should(Equal(policy))
+	g.Expect(sharedRegistryTrustPolicy(&jbsConfig, "quay.io/other/artifacts:abc")).Should(BeNil())
+
+	//only signed by one of the required keys
+	testSignImage(g, image, digest, first)
+	_, err := reconciler.verifySharedImage(ctx, &jbsConfig, &db, image, digest)
+	g.Expect(err).Should(MatchError(ContainSubstring("the key from second")))
+
+	signature := testSignImage(g, image, digest, first, second)
+	verified, err := reconciler.verifySharedImage(ctx, &jbsConfig, &db, image, digest)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(verified).Should(Equal(signature))
+
+	//the provenance has to match the build
+	db.Spec.ScmInfo.CommitHash = "0000000000000000000000000000000000000000"
+	_, err = reconciler.verifySharedImage(ctx, &jbsConfig, &db, image, digest)
+	g.Expect(err).Should(MatchError(ContainSubstring("rather than 0000000000000000000000000000000000000000")))
+	policy.RequiredProvenance = []string{ProvenanceHermetic}
+	_, err = reconciler.verifySharedImage(ctx, &jbsConfig, &db, image, digest)
+	g.Expect(err).Should(MatchError("the image was not built hermetically"))
+	policy.RequiredProvenance = []string{ProvenanceBuildId}
+	_, err = reconciler.verifySharedImage(ctx, &jbsConfig, &db, image, digest)
+	g.Expect(err).Should(MatchError("the image does not record the build-id provenance"))
+}
+
+func TestLicenseDenied(t *testing.T) {
+	g := NewGomegaWithT(t)
+	denylist := []string{"gpl-2.0-only", "AGPL-3.0-only"}
+	g.Expect(licenseDenied("GPL-2.0-only", denylist)).Should(BeTrue())
+	g.Expect(licenseDenied("GPL-2.0-only OR Apache-2.0", denylist)).Should(BeFalse())
+	g.Expect(licenseDenied("GPL-2.0-only OR AGPL-3.0-only", denylist)).Should(BeTrue())
+	g.Expect(licenseDenied("(MIT AND GPL-2.0-only) OR AGPL-3.0-only", denylist)).Should(BeTrue())
+	g.Expect(licenseDenied("(MIT AND GPL-2.0-only) OR Apache-2.0", denylist)).Should(BeFalse())
+	g.Expect(licenseDenied("GPL-2.0-only WITH Classpath-exception-2.0", denylist)).Should(BeFalse())
+
+	policy := &v1alpha1.LicensePolicy{DeniedLicenses: denylist}
+	g.Expect(deniedLicenses(policy, map[string][]string{"com.test:dual:1.0": {"GPL-2.0-only OR Apache-2.0"}, "com.test:denied:1.0": {"MIT", "GPL-2.0-only"}})).
+		Should(Equal(map[string][]string{"com.test:denied:1.0": {"GPL-2.0-only"}}))
+
+	//the deploy step only checks the licenses if the build fails, otherwise the artifacts are still used
+	jbsConfig := &v1alpha1.JBSConfig{Spec: v1alpha1.JBSConfigSpec{LicensePolicy: policy}}
+	_, deployArgs, _, _, _ := imageRegistryCommands("id", &v1alpha1.BuildRecipe{}, &v1alpha1.DependencyBuild{}, jbsConfig, false, "build")
+	g.Expect(deployArgs).ShouldNot(ContainElement(HavePrefix("--denied-licenses")))
+	policy.FailBuild = true
+	_, deployArgs, hermeticDeployArgs, _, _ := imageRegistryCommands("id", &v1alpha1.BuildRecipe{}, &v1alpha1.DependencyBuild{}, jbsConfig, true, "build")
+	g.Expect(deployArgs).Should(ContainElement("--denied-licenses=gpl-2.0-only,AGPL-3.0-only"))
+	g.Expect(hermeticDeployArgs).Should(ContainElement("--denied-licenses=gpl-2.0-only,AGPL-3.0-only"))
+}
+
+func TestHermeticProvenance(t *testing.T) {
+	g := NewGomegaWithT(t)
+	db := v1alpha1.DependencyBuild{Spec: v1alpha1.DependencyBuildSpec{ScmInfo: v1alpha1.SCMInfo{SCMURL: "https://github.com/foo/bar", CommitHash: "72bbbf2f3ac37bc1ab3a0bc1e1d1b7e5ba5b1a38"}}}
+	_, deployArgs, hermeticDeployArgs, _, _ := imageRegistryCommands("id", &v1alpha1.BuildRecipe{}, &db, &v1alpha1.JBSConfig{}, true, "build")
+	//the labels the deploy command adds to the image from its arguments
+	deployedLabels := func(args []string) map[string]string {
+		labels := map[string]string{provenanceLabelPrefix + ProvenanceHermetic: "false"}
+		for _, arg := range args {
+			name, value, _ := strings.Cut(arg, "=")
+			switch name {
+			case "--hermetic":
+				labels[provenanceLabelPrefix+ProvenanceHermetic] = "true"
+			case "--scm-uri":
+				labels[provenanceLabelPrefix+ProvenanceScmUri] = value
+			case "--scm-commit":
+				labels[provenanceLabelPrefix+ProvenanceScmCommit] = value
+			}
+		}
+		return labels
+	}
+	required := []string{ProvenanceScmUri, ProvenanceScmCommit, ProvenanceHermetic}
+	g.Expect(checkProvenance(deployedLabels(hermeticDeployArgs), required, &db)).Should(Succeed())
+	g.Expect(checkProvenance(deployedLabels(deployArgs), required, &db)).Should(MatchError("the image was not built hermetically"))
+}
+
+func TestStateContaminated(t *testing.T) {
+	ctx := context.TODO()
+	const first = "com.first:first:1.0"
+	const second = "com.second:second:1.0"
+	contaminatedBuild := func(url string, contaminant string) *v1alpha1.DependencyBuild {
+		db := v1alpha1.DependencyBuild{}
+		db.Namespace = metav1.NamespaceDefault
+		db.Name = util.HashString(url + "some-tag" + "some-path")
+		db.Spec.ScmInfo = v1alpha1.SCMInfo{SCMURL: url, Tag: "some-tag", Path: "some-path"}
+		db.Status.State = v1alpha1.DependencyBuildStateContaminated
+		db.Status.Contaminants = []v1alpha1.Contaminant{{GAV: contaminant, ContaminatedArtifacts: []string{TestArtifact}}}
+		return &db
+	}
+	contaminantBuild := func(gav string, state string, url string) *v1alpha1.ArtifactBuild {
+		abr := v1alpha1.ArtifactBuild{}
+		abr.Namespace = metav1.NamespaceDefault
+		abr.Name = artifactbuild.CreateABRName(gav)
+		abr.Spec.GAV = gav
+		abr.Status.State = state
+		abr.Status.SCMInfo = v1alpha1.SCMInfo{SCMURL: url, Tag: "some-tag", Path: "some-path"}
+		return &abr
+	}
+	getContaminatedBuild := func(client runtimeclient.Client, g *WithT, name string) *v1alpha1.DependencyBuild {
+		build := v1alpha1.DependencyBuild{}
+		g.Expect(client.Get(ctx, types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: name}, &build)).Should(Succeed())
+		return &build
+	}
+	//the first build shades the second, and the second shades the first
+	cycle := func() (runtimeclient.Client, *ReconcileDependencyBuild, *v1alpha1.DependencyBuild) {
+		db := contaminatedBuild("first-url", second)
+		client, reconciler := setupClientAndReconciler(db, contaminatedBuild("second-url", first),
+			contaminantBuild(first, v1alpha1.ArtifactBuildStateBuilding, "first-url"),
+			contaminantBuild(second, v1alpha1.ArtifactBuildStateBuilding, "second-url"))
+		return client, reconciler, db
+	}
+
+	t.Run("Test contamination cycle", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		client, reconciler, db := cycle()
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: db.Namespace, Name: db.Name}}))
+		db = getContaminatedBuild(client, g, db.Name)
+		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateContaminated))
+		g.Expect(db.Status.ContaminationGraph).Should(HaveLen(2))
+		g.Expect(db.Status.ContaminationGraph[1].GAV).Should(Equal(second))
+		g.Expect(db.Status.ContaminationGraph[1].DependencyBuild).Should(Equal(util.HashString("second-url" + "some-tag" + "some-path")))
+		g.Expect(db.Status.ContaminationGraph[1].BlockedBy).Should(Equal([]string{first}))
+		condition := meta.FindStatusCondition(db.Status.Conditions, v1alpha1.DependencyBuildConditionContaminationCycle)
+		g.Expect(condition).ShouldNot(BeNil())
+		g.Expect(condition.Status).Should(Equal(metav1.ConditionTrue))
+		g.Expect(condition.Message).Should(ContainSubstring(second + " -> " + first))
+		g.Expect(meta.IsStatusConditionTrue(db.Status.Conditions, v1alpha1.DependencyBuildConditionContaminationResolvable)).Should(BeTrue())
+	})
+	t.Run("Test contamination cycle broken by policy", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		client, reconciler, db := cycle()
+		jbsConfig := v1alpha1.JBSConfig{}
+		g.Expect(client.Get(ctx, types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: v1alpha1.JBSConfigName}, &jbsConfig)).Should(Succeed())
+		jbsConfig.Spec.ContaminantPolicy = &v1alpha1.ContaminantPolicy{BreakCycles: true}
+		g.Expect(client.Update(ctx, &jbsConfig)).Should(Succeed())
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: db.Namespace, Name: db.Name}}))
+		db = getContaminatedBuild(client, g, db.Name)
+		//the contaminated artifacts were not deployed, so the build runs again with the cycle allowed
+		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateNew))
+		g.Expect(db.Status.Contaminants).Should(BeEmpty())
+		g.Expect(db.Status.CycleAllowedContaminants).Should(Equal([]string{second}))
+		condition := meta.FindStatusCondition(db.Status.Conditions, v1alpha1.DependencyBuildConditionContaminationCycle)
+		g.Expect(condition).ShouldNot(BeNil())
+		g.Expect(condition.Reason).Should(Equal("CycleBroken"))
+
+		_, deployArgs, _, _, _ := imageRegistryCommands("id", &v1alpha1.BuildRecipe{}, db, &jbsConfig, false, "build")
+		g.Expect(deployArgs).Should(ContainElement("--allowed-contaminants=" + second))
+		db.Status.Contaminants = []v1alpha1.Contaminant{{GAV: second, ContaminatedArtifacts: []string{TestArtifact}}}
+		g.Expect(reconciler.applyContaminantPolicy(ctx, logr.Discard(), db)).Should(Succeed())
+		g.Expect(db.Status.Contaminants).Should(BeEmpty())
+		g.Expect(db.Status.AllowedContaminants).Should(Equal([]v1alpha1.Contaminant{{GAV: second, ContaminatedArtifacts: []string{TestArtifact}}}))
+	})
+	t.Run("Test unresolvable contaminant", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		db := contaminatedBuild("first-url", second)
+		db.Status.Contaminants = append(db.Status.Contaminants, v1alpha1.Contaminant{GAV: "com.missing:missing:1.0", ContaminatedArtifacts: []string{TestArtifact}})
+		client, reconciler := setupClientAndReconciler(db, contaminantBuild(second, v1alpha1.ArtifactBuildStateMissing, ""))
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: db.Namespace, Name: db.Name}}))
+		db = getContaminatedBuild(client, g, db.Name)
+		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateContaminated))
+		condition := meta.FindStatusCondition(db.Status.Conditions, v1alpha1.DependencyBuildConditionContaminationResolvable)
+		g.Expect(condition).ShouldNot(BeNil())
+		g.Expect(condition.Status).Should(Equal(metav1.ConditionFalse))
+		g.Expect(condition.Message).Should(ContainSubstring("com.missing:missing:1.0 (no ArtifactBuild is rebuilding it)"))
+		g.Expect(condition.Message).Should(ContainSubstring(second + " (ArtifactBuild " + artifactbuild.CreateABRName(second) + " is ArtifactBuildMissing)"))
+		g.Expect(meta.IsStatusConditionTrue(db.Status.Conditions, v1alpha1.DependencyBuildConditionContaminationCycle)).Should(BeFalse())
+	})
+	t.Run("Test resolved contamination clears the graph", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		client, reconciler, db := cycle()
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: db.Namespace, Name: db.Name}}))
+		db = getContaminatedBuild(client, g, db.Name)
+		db.Status.Contaminants = nil
+		g.Expect(client.Status().Update(ctx, db)).Should(Succeed())
+		g.Expect(reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: db.Namespace, Name: db.Name}}))
+		db = getContaminatedBuild(client, g, db.Name)
+		g.Expect(db.Status.State).Should(Equal(v1alpha1.DependencyBuildStateNew))
+		g.Expect(db.Status.ContaminationGraph).Should(BeEmpty())
+		g.Expect(meta.FindStatusCondition(db.Status.Conditions, v1alpha1.DependencyBuildConditionContaminationCycle)).Should(BeNil())
+	})
 }
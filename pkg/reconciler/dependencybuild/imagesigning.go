@@ -0,0 +1,156 @@
+package dependencybuild
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// the annotation on the signature layer that holds the base64 encoded signature of the layer
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+	cosignKeyVolume           = "cosign-key"
+	cosignKeyPath             = "/cosign"
+	cosignDockerConfigVolume  = "cosign-docker-config"
+	cosignDockerConfigPath    = "/docker-config"
+)
+
+// signTask creates the task that signs the deployed image with the cosign key from the JBSConfig. The cosign image
+// has no shell, so the registry credentials are mounted as the docker config rather than written by a script.
+func signTask(cosignImage string, jbsConfig *v1alpha1.JBSConfig, resources v1.ResourceRequirements) pipelinev1beta1.TaskSpec {
+	trueBool := true
+	args := []string{"sign", "--yes", "--tlog-upload=false", "--key", cosignKeyPath + "/" + v1alpha1.CosignPrivateKeyKey}
+	if jbsConfig.ImageRegistry().Insecure {
+		args = append(args, "--allow-insecure-registry")
+	}
+	//cosign ignores the tag when there is a digest, so the image URL can be used as is
+	args = append(args, "$(params."+PipelineResultImage+")@$(params."+DeployedImageDigest+")")
+	volumes := []v1.Volume{{Name: cosignKeyVolume, VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: jbsConfig.Spec.ImageSigning.KeySecret}}}}
+	mounts := []v1.VolumeMount{{Name: cosignKeyVolume, MountPath: cosignKeyPath, ReadOnly: true}}
+	env := []v1.EnvVar{{Name: "COSIGN_PASSWORD", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: jbsConfig.Spec.ImageSigning.KeySecret}, Key: v1alpha1.CosignPasswordKey, Optional: &trueBool}}}}
+	if jbsConfig.ImageRegistry().SecretName != "" {
+		volumes = append(volumes, v1.Volume{Name: cosignDockerConfigVolume, VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: jbsConfig.ImageRegistry().SecretName, Optional: &trueBool, Items: []v1.KeyToPath{{Key: v1alpha1.ImageSecretTokenKey, Path: "config.json"}}}}})
+		mounts = append(mounts, v1.VolumeMount{Name: cosignDockerConfigVolume, MountPath: cosignDockerConfigPath, ReadOnly: true})
+		env = append(env, v1.EnvVar{Name: "DOCKER_CONFIG", Value: cosignDockerConfigPath})
+	}
+	return pipelinev1beta1.TaskSpec{
+		Params:  []pipelinev1beta1.ParamSpec{{Name: PipelineResultImage, Type: pipelinev1beta1.ParamTypeString}, {Name: DeployedImageDigest, Type: pipelinev1beta1.ParamTypeString}},
+		Volumes: volumes,
+		Steps: []pipelinev1beta1.Step{
+			{
+				Name:             artifactbuild.SignTaskName,
+				Image:            cosignImage,
+				Command:          []string{"cosign"},
+				Args:             args,
+				Env:              env,
+				VolumeMounts:     mounts,
+				ComputeResources: resources,
+			},
+		},
+	}
+}
+
+// signedBuild checks if the build pipeline signed the deployed image
+func signedBuild(pr *pipelinev1beta1.PipelineRun) bool {
+	if pr.Spec.PipelineSpec == nil {
+		return false
+	}
+	for _, task := range pr.Spec.PipelineSpec.Tasks {
+		if task.Name == artifactbuild.SignTaskName {
+			return true
+		}
+	}
+	return false
+}
+
+// imageSignatureReference returns the tag cosign stores the signature of the image under
+func imageSignatureReference(image string, digest string) (string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+	return ref.Context().Tag(strings.Replace(digest, ":", "-", 1) + ".sig").String(), nil
+}
+
+// verifyImageSignature checks that the image has a cosign signature made with the given public key. Only the
+// signature itself is verified, there is no transparency log lookup as the images are signed without one.
+func verifyImageSignature(ctx context.Context, image string, digest string, publicKey []byte, keychain authn.Keychain) (string, error) {
+	block, _ := pem.Decode(publicKey)
+	if block == nil {
+		return "", fmt.Errorf("unable to decode the cosign public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+	ecdsaKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return "", fmt.Errorf("only ECDSA cosign keys are supported")
+	}
+	signature, err := imageSignatureReference(image, digest)
+	if err != nil {
+		return "", err
+	}
+	ref, err := name.ParseReference(signature)
+	if err != nil {
+		return "", err
+	}
+	signatureImage, err := remote.Image(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(keychain))
+	if err != nil {
+		return "", err
+	}
+	manifest, err := signatureImage.Manifest()
+	if err != nil {
+		return "", err
+	}
+	for _, layer := range manifest.Layers {
+		sig, err := base64.StdEncoding.DecodeString(layer.Annotations[cosignSignatureAnnotation])
+		if err != nil || len(sig) == 0 {
+			continue
+		}
+		l, err := signatureImage.LayerByDigest(layer.Digest)
+		if err != nil {
+			return "", err
+		}
+		reader, err := l.Compressed()
+		if err != nil {
+			return "", err
+		}
+		payload, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return "", err
+		}
+		hash := sha256.Sum256(payload)
+		if !ecdsa.VerifyASN1(ecdsaKey, hash[:], sig) {
+			continue
+		}
+		//the signature has to be for this image, not just any image signed with the same key
+		signed := struct {
+			Critical struct {
+				Image struct {
+					DockerManifestDigest string `json:"docker-manifest-digest"`
+				} `json:"image"`
+			} `json:"critical"`
+		}{}
+		if json.Unmarshal(payload, &signed) == nil && signed.Critical.Image.DockerManifestDigest == digest {
+			return signature, nil
+		}
+	}
+	return "", fmt.Errorf("image %s@%s does not have a valid signature", image, digest)
+}
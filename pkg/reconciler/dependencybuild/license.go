@@ -0,0 +1,169 @@
+package dependencybuild
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// buildLicenses returns the SPDX identifiers the deploy step detected for each deployed GAV
+func (r *ReconcileDependencyBuild) buildLicenses(ctx context.Context, log logr.Logger, pr *pipelinev1beta1.PipelineRun) map[string][]string {
+	for _, trs := range pr.Status.ChildReferences {
+		tr := pipelinev1beta1.TaskRun{}
+		err := r.client.Get(ctx, types.NamespacedName{Namespace: pr.Namespace, Name: trs.Name}, &tr)
+		if err != nil {
+			log.Error(err, "Unable to retrieve TaskRun to read the licenses")
+			continue
+		}
+		for _, result := range tr.Status.Results {
+			if result.Name != artifactbuild.PipelineResultLicenses || len(result.Value.StringVal) == 0 {
+				continue
+			}
+			licenses := map[string][]string{}
+			if err := json.Unmarshal([]byte(result.Value.StringVal), &licenses); err != nil {
+				log.Error(err, "Unable to parse the licenses")
+				return nil
+			}
+			return licenses
+		}
+	}
+	return nil
+}
+
+// deniedLicenses returns the licenses of each GAV that are on the denylist
+func deniedLicenses(policy *v1alpha1.LicensePolicy, licenses map[string][]string) map[string][]string {
+	denied := map[string][]string{}
+	for gav, ids := range licenses {
+		for _, id := range ids {
+			if licenseDenied(id, policy.DeniedLicenses) {
+				denied[gav] = append(denied[gav], id)
+			}
+		}
+	}
+	return denied
+}
+
+// licenseDenied checks a license against the denylist in the same way as the deploy step. An OR expression is a
+// choice so it is only denied if every license in it is, an AND expression is denied if any license in it is.
+func licenseDenied(license string, denylist []string) bool {
+	expression := stripLicenseParentheses(strings.TrimSpace(license))
+	if alternatives := splitLicenseExpression(expression, "OR"); len(alternatives) > 1 {
+		for _, i := range alternatives {
+			if !licenseDenied(i, denylist) {
+				return false
+			}
+		}
+		return true
+	}
+	if parts := splitLicenseExpression(expression, "AND"); len(parts) > 1 {
+		for _, i := range parts {
+			if licenseDenied(i, denylist) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, deny := range denylist {
+		if strings.EqualFold(expression, deny) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitLicenseExpression splits a SPDX expression on an operator, ignoring operators inside parentheses
+func splitLicenseExpression(expression string, operator string) []string {
+	var ret []string
+	token := " " + operator + " "
+	upper := strings.ToUpper(expression)
+	depth := 0
+	start := 0
+	for i := 0; i < len(expression); i++ {
+		switch {
+		case expression[i] == '(':
+			depth++
+		case expression[i] == ')':
+			depth--
+		case depth == 0 && strings.HasPrefix(upper[i:], token):
+			ret = append(ret, strings.TrimSpace(expression[start:i]))
+			start = i + len(token)
+			i = start - 1
+		}
+	}
+	return append(ret, strings.TrimSpace(expression[start:]))
+}
+
+// stripLicenseParentheses removes parentheses that enclose the whole expression
+func stripLicenseParentheses(expression string) string {
+	for strings.HasPrefix(expression, "(") && strings.HasSuffix(expression, ")") {
+		depth := 0
+		for i := 0; i < len(expression)-1; i++ {
+			switch expression[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			if depth == 0 {
+				return expression
+			}
+		}
+		expression = strings.TrimSpace(expression[1 : len(expression)-1])
+	}
+	return expression
+}
+
+// deniedLicensesArg returns the licenses the deploy step has to check before deploying, this is only done if the
+// policy fails the build so nothing with a denied license is ever deployed
+func deniedLicensesArg(policy *v1alpha1.LicensePolicy) string {
+	if policy == nil || !policy.FailBuild {
+		return ""
+	}
+	return strings.Join(policy.DeniedLicenses, ",")
+}
+
+// handleLicensePolicy checks the detected licenses against the JBSConfig license policy, recording the result as a
+// condition on the DependencyBuild. It returns true if the policy requires the build to fail.
+func (r *ReconcileDependencyBuild) handleLicensePolicy(ctx context.Context, db *v1alpha1.DependencyBuild, licenses map[string][]string) (bool, error) {
+	jbsConfig := &v1alpha1.JBSConfig{}
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: db.Namespace, Name: v1alpha1.JBSConfigName}, jbsConfig)
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	policy := jbsConfig.Spec.LicensePolicy
+	if policy == nil || len(policy.DeniedLicenses) == 0 {
+		return false, nil
+	}
+	denied := deniedLicenses(policy, licenses)
+	if len(denied) == 0 {
+		meta.SetStatusCondition(&db.Status.Conditions, v12.Condition{Type: v1alpha1.DependencyBuildConditionLicenseCompliant, Status: v12.ConditionTrue, Reason: "NoDeniedLicenses", Message: "No artifacts have a denied license"})
+		return false, nil
+	}
+	gavs := []string{}
+	for gav := range denied {
+		gavs = append(gavs, gav)
+	}
+	sort.Strings(gavs)
+	parts := []string{}
+	for _, gav := range gavs {
+		parts = append(parts, fmt.Sprintf("%s (%s)", gav, strings.Join(denied[gav], ", ")))
+	}
+	message := "Artifacts have denied licenses: " + strings.Join(parts, ", ")
+	meta.SetStatusCondition(&db.Status.Conditions, v12.Condition{Type: v1alpha1.DependencyBuildConditionLicenseCompliant, Status: v12.ConditionFalse, Reason: v1alpha1.DependencyBuildFailureReasonDeniedLicense, Message: message})
+	r.eventRecorder.Eventf(db, v1.EventTypeWarning, v1alpha1.DependencyBuildFailureReasonDeniedLicense, "The DependencyBuild %s/%s produced artifacts with denied licenses: %s", db.Namespace, db.Name, strings.Join(parts, ", "))
+	return policy.FailBuild, nil
+}
@@ -0,0 +1,184 @@
+package dependencybuild
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	ProvenanceStatementKey        = "statement.json"
+	InTotoStatementType           = "https://in-toto.io/Statement/v1"
+	SLSAProvenancePredicateType   = "https://slsa.dev/provenance/v1"
+	provenanceBuildType           = "https://github.com/redhat-appstudio/jvm-build-service/DependencyBuild@v1"
+	provenanceBuilderId           = "https://github.com/redhat-appstudio/jvm-build-service"
+	provenanceConfigMapNameSuffix = "-provenance"
+)
+
+type inTotoStatement struct {
+	Type          string                  `json:"_type"`
+	Subject       []provenanceDescriptor  `json:"subject"`
+	PredicateType string                  `json:"predicateType"`
+	Predicate     slsaProvenancePredicate `json:"predicate"`
+}
+
+type provenanceDescriptor struct {
+	Name   string            `json:"name,omitempty"`
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+type slsaProvenancePredicate struct {
+	BuildDefinition slsaBuildDefinition `json:"buildDefinition"`
+	RunDetails      slsaRunDetails      `json:"runDetails"`
+}
+
+type slsaBuildDefinition struct {
+	BuildType            string                 `json:"buildType"`
+	ExternalParameters   map[string]interface{} `json:"externalParameters"`
+	InternalParameters   map[string]interface{} `json:"internalParameters,omitempty"`
+	ResolvedDependencies []provenanceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+type slsaRunDetails struct {
+	Builder  slsaBuilder     `json:"builder"`
+	Metadata slsaRunMetadata `json:"metadata"`
+}
+
+type slsaBuilder struct {
+	ID string `json:"id"`
+}
+
+type slsaRunMetadata struct {
+	InvocationID string `json:"invocationId"`
+	StartedOn    string `json:"startedOn,omitempty"`
+	FinishedOn   string `json:"finishedOn,omitempty"`
+}
+
+// provenanceStatement creates the in-toto SLSA provenance statement for an artifact deployed in the given image
+func provenanceStatement(pr *pipelinev1beta1.PipelineRun, db *v1alpha1.DependencyBuild, recipe *v1alpha1.BuildRecipe, gav string, image string, digest string) ([]byte, error) {
+	subject := provenanceDescriptor{Name: image, Digest: map[string]string{}}
+	if ref, err := name.ParseReference(image); err == nil {
+		subject.Name = ref.Context().Name()
+	}
+	if algorithm, value, found := strings.Cut(digest, ":"); found {
+		subject.Digest[algorithm] = value
+	}
+
+	scm := db.Spec.ScmInfo
+	source := provenanceDescriptor{URI: "git+" + scm.SCMURL + "@" + scm.Tag}
+	if scm.CommitHash != "" {
+		source.Digest = map[string]string{"gitCommit": scm.CommitHash}
+	}
+	builder := provenanceDescriptor{URI: recipe.Image}
+	if _, builderDigest, found := strings.Cut(recipe.Image, "@"); found {
+		if algorithm, value, found := strings.Cut(builderDigest, ":"); found {
+			builder.Digest = map[string]string{algorithm: value}
+		}
+	}
+
+	verified := false
+	for _, i := range pr.Status.Results {
+		if i.Name == artifactbuild.PipelineResultPassedVerification {
+			verified, _ = strconv.ParseBool(i.Value.StringVal)
+		}
+	}
+
+	metadata := slsaRunMetadata{InvocationID: pr.Namespace + "/" + pr.Name}
+	if pr.Status.StartTime != nil {
+		metadata.StartedOn = pr.Status.StartTime.UTC().Format(time.RFC3339)
+	}
+	if pr.Status.CompletionTime != nil {
+		metadata.FinishedOn = pr.Status.CompletionTime.UTC().Format(time.RFC3339)
+	}
+
+	statement := inTotoStatement{
+		Type:          InTotoStatementType,
+		Subject:       []provenanceDescriptor{subject},
+		PredicateType: SLSAProvenancePredicateType,
+		Predicate: slsaProvenancePredicate{
+			BuildDefinition: slsaBuildDefinition{
+				BuildType: provenanceBuildType,
+				ExternalParameters: map[string]interface{}{
+					"gav": gav,
+					"source": map[string]string{
+						"url":    scm.SCMURL,
+						"tag":    scm.Tag,
+						"commit": scm.CommitHash,
+						"path":   scm.Path,
+					},
+					"recipe": recipe,
+				},
+				InternalParameters: map[string]interface{}{
+					"hermetic": hermeticBuild(pr),
+					"verified": verified,
+				},
+				ResolvedDependencies: []provenanceDescriptor{source, builder},
+			},
+			RunDetails: slsaRunDetails{
+				Builder:  slsaBuilder{ID: provenanceBuilderId},
+				Metadata: metadata,
+			},
+		},
+	}
+	return json.Marshal(statement)
+}
+
+// hermeticBuild checks if the build pipeline ran the hermetic build task
+func hermeticBuild(pr *pipelinev1beta1.PipelineRun) bool {
+	if pr.Spec.PipelineSpec == nil {
+		return false
+	}
+	for _, task := range pr.Spec.PipelineSpec.Tasks {
+		if task.Name == artifactbuild.HermeticBuildTaskName {
+			return true
+		}
+	}
+	return false
+}
+
+// createProvenance stores the provenance statement for the rebuilt artifact in a ConfigMap owned by the DependencyBuild
+func (r *ReconcileDependencyBuild) createProvenance(ctx context.Context, pr *pipelinev1beta1.PipelineRun, db *v1alpha1.DependencyBuild, recipe *v1alpha1.BuildRecipe, ra *v1alpha1.RebuiltArtifact) (*v1alpha1.ProvenanceReference, error) {
+	statement, err := provenanceStatement(pr, db, recipe, ra.Spec.GAV, ra.Spec.Image, ra.Spec.Digest)
+	if err != nil {
+		return nil, err
+	}
+	cm := v1.ConfigMap{}
+	cm.Namespace = db.Namespace
+	cm.Name = ra.Name + provenanceConfigMapNameSuffix
+	cm.Labels = map[string]string{artifactbuild.DependencyBuildIdLabel: db.Name}
+	cm.Data = map[string]string{ProvenanceStatementKey: string(statement)}
+	if err := controllerutil.SetOwnerReference(db, &cm, r.scheme); err != nil {
+		return nil, err
+	}
+	err = r.client.Create(ctx, &cm)
+	if errors.IsAlreadyExists(err) {
+		//a later build of the same artifact replaces the statement
+		existing := v1.ConfigMap{}
+		err = r.client.Get(ctx, types.NamespacedName{Namespace: cm.Namespace, Name: cm.Name}, &existing)
+		if err != nil {
+			return nil, err
+		}
+		existing.Data = cm.Data
+		existing.OwnerReferences = cm.OwnerReferences
+		err = r.client.Update(ctx, &existing)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(statement)
+	return &v1alpha1.ProvenanceReference{ConfigMap: cm.Name, PredicateType: SLSAProvenancePredicateType, Digest: "sha256:" + hex.EncodeToString(sum[:])}, nil
+}
@@ -0,0 +1,179 @@
+package dependencybuild
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// PipelineResultReproducibility holds the number of files that differ between the two builds, followed by a line
+	// per file with the reason and path
+	PipelineResultReproducibility = "REPRODUCIBILITY"
+	// PipelineResultArtifactChecksums holds a line per artifact with its sha256 checksum and path, it is recorded by
+	// both builds so they can be compared
+	PipelineResultArtifactChecksums = "ARTIFACT_CHECKSUMS"
+	// the exit code of the compare step if the output differs and reproducible builds are required
+	NotReproducibleExitCode = 66
+	// the result size is limited, so only this many differences are recorded
+	maxReproducibilityDifferences = 20
+	reproducibilityBuildStep      = "reproducibility-build"
+	artifactChecksumsStep         = "artifact-checksums"
+	compareBuildsStep             = "compare-builds"
+	firstBuildChecksums           = "FIRST_BUILD_CHECKSUMS"
+	secondBuildChecksums          = "SECOND_BUILD_CHECKSUMS"
+)
+
+//go:embed scripts/artifact-checksums.sh
+var artifactChecksumsScript string
+
+//go:embed scripts/compare-builds.sh
+var compareBuildsScript string
+
+func reproducibilityCheckEnabled(jbsConfig *v1alpha1.JBSConfig, recipe *v1alpha1.BuildRecipe) bool {
+	return jbsConfig.Spec.ReproducibilityCheck != nil || (recipe.Reproducible != nil && recipe.Reproducible.Check)
+}
+
+// artifactChecksumsStepSpec records the checksums of the artifacts in the source workspace, it runs after the build
+// step of both the build and reproducibility tasks
+func artifactChecksumsStepSpec(resources v1.ResourceRequirements) pipelinev1beta1.Step {
+	zero := int64(0)
+	return pipelinev1beta1.Step{
+		Name:             artifactChecksumsStep,
+		Image:            "$(params." + PreBuildImageDigest + ")",
+		SecurityContext:  &v1.SecurityContext{RunAsUser: &zero},
+		ComputeResources: resources,
+		Script:           strings.ReplaceAll(artifactChecksumsScript, "{RESULT_PATH}", "$(results."+PipelineResultArtifactChecksums+".path)"),
+	}
+}
+
+// reproducibilityTaskSpec runs the build again from the pre-build image in its own workspace, so it can run in
+// parallel with the build task and on a different node
+func reproducibilityTaskSpec(buildTask *pipelinev1beta1.TaskSpec, workspaces []pipelinev1beta1.WorkspaceDeclaration, resources map[string]v1.ResourceRequirements) pipelinev1beta1.TaskSpec {
+	build := *buildTask.Steps[0].DeepCopy()
+	build.Name = reproducibilityBuildStep
+	build.ComputeResources = resources[reproducibilityBuildStep]
+	build.Script = withResourceUsage(reproducibilityBuildStep, OriginalContentPath+"/build.sh \"$@\"")
+	return pipelinev1beta1.TaskSpec{
+		Workspaces: workspaces,
+		Params:     buildTask.Params,
+		Volumes:    buildTask.Volumes,
+		Results:    []pipelinev1beta1.TaskResult{{Name: PipelineResultArtifactChecksums}, {Name: PipelineResultResourceUsage}},
+		Steps:      []pipelinev1beta1.Step{build, artifactChecksumsStepSpec(resources[artifactChecksumsStep])},
+	}
+}
+
+// compareBuildsStepSpec compares the checksums recorded by the two builds. It runs before the artifacts are tagged,
+// so if reproducible builds are required a build with different output fails and is never used.
+func compareBuildsStepSpec(buildRequestProcessorImage string, required bool, resources v1.ResourceRequirements) pipelinev1beta1.Step {
+	zero := int64(0)
+	script := strings.ReplaceAll(compareBuildsScript, "{RESULT_PATH}", "$(results."+PipelineResultReproducibility+".path)")
+	script = strings.ReplaceAll(script, "{MAX_DIFFERENCES}", strconv.Itoa(maxReproducibilityDifferences))
+	script = strings.ReplaceAll(script, "{REQUIRED}", strconv.FormatBool(required))
+	script = strings.ReplaceAll(script, "{EXIT_CODE}", strconv.Itoa(NotReproducibleExitCode))
+	return pipelinev1beta1.Step{
+		Name:            compareBuildsStep,
+		Image:           buildRequestProcessorImage,
+		ImagePullPolicy: pullPolicy(buildRequestProcessorImage),
+		SecurityContext: &v1.SecurityContext{RunAsUser: &zero},
+		Env: []v1.EnvVar{
+			{Name: firstBuildChecksums, Value: "$(params." + firstBuildChecksums + ")"},
+			{Name: secondBuildChecksums, Value: "$(params." + secondBuildChecksums + ")"},
+		},
+		ComputeResources: resources,
+		Script:           script,
+	}
+}
+
+// reproducibilityTaskRunSpecs keeps the second build off the node the first build runs on, if the JBSConfig asks for it
+func reproducibilityTaskRunSpecs(jbsConfig *v1alpha1.JBSConfig, recipe *v1alpha1.BuildRecipe, pipelineRunName string) []pipelinev1beta1.PipelineTaskRunSpec {
+	if !reproducibilityCheckEnabled(jbsConfig, recipe) || jbsConfig.Spec.ReproducibilityCheck == nil || !jbsConfig.Spec.ReproducibilityCheck.AntiAffinity {
+		return nil
+	}
+	affinity := &v1.Affinity{PodAntiAffinity: &v1.PodAntiAffinity{RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{{
+		LabelSelector: &v12.LabelSelector{MatchLabels: map[string]string{
+			pipeline.PipelineRunLabelKey:  pipelineRunName,
+			pipeline.PipelineTaskLabelKey: artifactbuild.BuildTaskName,
+		}},
+		TopologyKey: v1.LabelHostname,
+	}}}}
+	return []pipelinev1beta1.PipelineTaskRunSpec{{PipelineTaskName: artifactbuild.ReproducibilityTaskName, PodTemplate: &pod.PodTemplate{Affinity: affinity}}}
+}
+
+func parseReproducibilityResult(value string) (int, []v1alpha1.ReproducibilityDifference, error) {
+	lines := strings.Split(strings.TrimSpace(value), "\n")
+	total, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid reproducibility result: %w", err)
+	}
+	var differences []v1alpha1.ReproducibilityDifference
+	for _, line := range lines[1:] {
+		reason, file, found := strings.Cut(line, " ")
+		if found {
+			differences = append(differences, v1alpha1.ReproducibilityDifference{Reason: reason, File: file})
+		}
+	}
+	return total, differences, nil
+}
+
+// handleReproducibilityResult records the result of the reproducibility check on the DependencyBuild, if the
+// check was run
+func (r *ReconcileDependencyBuild) handleReproducibilityResult(ctx context.Context, log logr.Logger, pr *pipelinev1beta1.PipelineRun, db *v1alpha1.DependencyBuild) {
+	for _, trs := range pr.Status.ChildReferences {
+		tr := pipelinev1beta1.TaskRun{}
+		err := r.client.Get(ctx, types.NamespacedName{Namespace: pr.Namespace, Name: trs.Name}, &tr)
+		if err != nil {
+			log.Error(err, "Unable to retrieve TaskRun to check the reproducibility result")
+			continue
+		}
+		for _, result := range tr.Status.Results {
+			if result.Name != PipelineResultReproducibility {
+				continue
+			}
+			total, differences, err := parseReproducibilityResult(result.Value.StringVal)
+			if err != nil {
+				log.Error(err, "Unable to parse the reproducibility result")
+				continue
+			}
+			db.Status.ReproducibilityDifferences = differences
+			condition := v12.Condition{Type: v1alpha1.DependencyBuildConditionReproducible, Status: v12.ConditionTrue, Reason: "OutputMatches", Message: "Both builds produced the same artifacts"}
+			if total > 0 {
+				condition = v12.Condition{Type: v1alpha1.DependencyBuildConditionReproducible, Status: v12.ConditionFalse, Reason: "OutputDiffers", Message: fmt.Sprintf("%d files differ between the two builds", total)}
+			}
+			meta.SetStatusCondition(&db.Status.Conditions, condition)
+			return
+		}
+	}
+}
+
+// failedReproducibilityCheck checks if the compare step exited because the output was different and
+// reproducible builds are required
+func (r *ReconcileDependencyBuild) failedReproducibilityCheck(ctx context.Context, log logr.Logger, pr *pipelinev1beta1.PipelineRun) bool {
+	for _, trs := range pr.Status.ChildReferences {
+		tr := pipelinev1beta1.TaskRun{}
+		err := r.client.Get(ctx, types.NamespacedName{Namespace: pr.Namespace, Name: trs.Name}, &tr)
+		if err != nil {
+			log.Error(err, "Unable to retrieve TaskRun to check for reproducibility failure")
+		} else {
+			for _, cont := range tr.Status.Steps {
+				if cont.Name == compareBuildsStep && cont.Terminated != nil && cont.Terminated.ExitCode == NotReproducibleExitCode {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package dependencybuild
+
+import (
+	"context"
+	_ "embed"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/util"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PipelineResultResourceUsage holds a line per step with the step name, peak memory in bytes and average CPU in millicores
+	PipelineResultResourceUsage = "RESOURCE_USAGE"
+	// the headroom added to the recorded usage when recommending resources, as a percentage
+	resourceHeadroom = 25
+	cpuIncrement     = 100
+)
+
+//go:embed scripts/resource-usage.sh
+var resourceUsageScript string
+
+// withResourceUsage records the peak memory and average CPU of the step container into the resource usage result
+// when the script exits
+func withResourceUsage(step string, script string) string {
+	usage := strings.ReplaceAll(resourceUsageScript, "{STEP}", step)
+	usage = strings.ReplaceAll(usage, "{RESULT_PATH}", "$(results."+PipelineResultResourceUsage+".path)")
+	if strings.HasPrefix(script, "#!") {
+		split := strings.SplitN(script, "\n", 2)
+		if len(split) == 2 {
+			return split[0] + "\n" + usage + split[1]
+		}
+	}
+	return usage + script
+}
+
+func parseResourceUsage(value string) []v1alpha1.StepResourceUsage {
+	ret := []v1alpha1.StepResourceUsage{}
+	for _, line := range strings.Split(value, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		memory, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		cpu, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		ret = append(ret, v1alpha1.StepResourceUsage{Step: fields[0], PeakMemoryBytes: memory, AverageCPUMillicores: cpu})
+	}
+	return ret
+}
+
+// buildResourceUsage reads the resources used by each step of the build pipeline from its TaskRuns. Steps that were
+// OOM killed never get to write their usage, so they are recorded from the container state instead.
+func (r *ReconcileDependencyBuild) buildResourceUsage(ctx context.Context, log logr.Logger, pr *pipelinev1beta1.PipelineRun) []v1alpha1.StepResourceUsage {
+	var ret []v1alpha1.StepResourceUsage
+	for _, trs := range pr.Status.ChildReferences {
+		tr := pipelinev1beta1.TaskRun{}
+		err := r.client.Get(ctx, types.NamespacedName{Namespace: pr.Namespace, Name: trs.Name}, &tr)
+		if err != nil {
+			log.Error(err, "Unable to retrieve TaskRun to record resource usage")
+			continue
+		}
+		for _, result := range tr.Status.Results {
+			if result.Name == PipelineResultResourceUsage {
+				ret = append(ret, parseResourceUsage(result.Value.StringVal)...)
+			}
+		}
+		for _, cont := range tr.Status.Steps {
+			if cont.Terminated != nil && cont.Terminated.Reason == "OOMKilled" {
+				ret = append(ret, v1alpha1.StepResourceUsage{Step: cont.Name, OOMKilled: true})
+			}
+		}
+	}
+	return ret
+}
+
+// recommendedResources looks at the resources used by earlier builds of the same repository, and returns the
+// additional memory and CPU the build steps should start with. An empty CPU means the default is enough. The earlier
+// builds are found by the SCM URL label set when the ArtifactBuild creates the DependencyBuild.
+func (r *ReconcileDependencyBuild) recommendedResources(ctx context.Context, db *v1alpha1.DependencyBuild, jbsConfig *v1alpha1.JBSConfig) (int, string, error) {
+	list := v1alpha1.DependencyBuildList{}
+	err := r.client.List(ctx, &list, client.InNamespace(db.Namespace), client.MatchingLabels{artifactbuild.ScmUrlLabel: util.HashString(db.Spec.ScmInfo.SCMURL)})
+	if err != nil {
+		return 0, "", err
+	}
+	resources, err := stepResources(jbsConfig, "", 0)
+	if err != nil {
+		return 0, "", err
+	}
+	limits := resources["build"].Limits
+	baseMemory := limits.Memory().Value()
+	baseCPU := limits.Cpu().MilliValue()
+
+	additionalMemory := 0
+	var peakMemory int64
+	var cpu int64
+	for _, other := range list.Items {
+		if other.Name == db.Name || other.Spec.ScmInfo.SCMURL != db.Spec.ScmInfo.SCMURL {
+			continue
+		}
+		for _, attempt := range other.Status.BuildAttempts {
+			for _, usage := range attempt.ResourceUsage {
+				if usage.Step != "build" && usage.Step != "hermetic-build" {
+					continue
+				}
+				if usage.OOMKilled {
+					//we don't know how much it needed, only that it was more than it had
+					if attempt.Recipe != nil && increaseMemory(attempt.Recipe.AdditionalMemory) > additionalMemory {
+						additionalMemory = increaseMemory(attempt.Recipe.AdditionalMemory)
+					}
+					continue
+				}
+				if usage.PeakMemoryBytes > peakMemory {
+					peakMemory = usage.PeakMemoryBytes
+				}
+				if usage.AverageCPUMillicores > cpu {
+					cpu = usage.AverageCPUMillicores
+				}
+			}
+		}
+	}
+	needed := peakMemory*(100+resourceHeadroom)/100 - baseMemory
+	if needed > 0 {
+		increment := int64(MemoryIncrement) * 1024 * 1024
+		recommended := int((needed + increment - 1) / increment * MemoryIncrement)
+		if recommended > additionalMemory {
+			additionalMemory = recommended
+		}
+	}
+	recommendedCPU := ""
+	cpu = cpu * (100 + resourceHeadroom) / 100
+	if cpu > baseCPU {
+		recommendedCPU = strconv.FormatInt((cpu+cpuIncrement-1)/cpuIncrement*cpuIncrement, 10) + "m"
+	}
+	return additionalMemory, recommendedCPU, nil
+}
+
+// increaseMemory returns the additional memory to retry a build with after it ran out of memory
+func increaseMemory(additionalMemory int) int {
+	if additionalMemory == 0 {
+		return MemoryIncrement
+	}
+	return additionalMemory * 2
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/go-logr/logr"
 	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
 	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/util"
 	"github.com/tektoncd/cli/pkg/cli"
 	tknlogs "github.com/tektoncd/cli/pkg/log"
@@ -234,6 +235,31 @@ func (r *ReconcileDependencyBuild) handleS3SyncPipelineRun(ctx context.Context,
 		if err != nil {
 			log.Error(err, "failed to upload task to s3")
 		}
+		for _, result := range tr.Status.Results {
+			if result.Name != artifactbuild.PipelineResultSbom || len(result.Value.StringVal) == 0 {
+				continue
+			}
+			sbomPath := "build-sboms/" + dep.Name + "/" + string(dep.UID) + "/" + pr.Name + ".json"
+			log.Info("attempting to upload SBOM to s3", "path", sbomPath)
+			_, err = uploader.Upload(&s3manager.UploadInput{
+				Bucket:      aws.String(bucketName),
+				Key:         aws.String(sbomPath),
+				Body:        strings.NewReader(result.Value.StringVal),
+				ContentType: aws.String(CycloneDXMediaType),
+				Metadata: map[string]*string{
+					"dependency-build":     aws.String(dep.Name),
+					"dependency-build-uid": aws.String(string(dep.UID)),
+					"type":                 aws.String("build-sbom"),
+					"scm-uri":              aws.String(dep.Spec.ScmInfo.SCMURL),
+					"scm-tag":              aws.String(dep.Spec.ScmInfo.Tag),
+					"scm-commit":           aws.String(dep.Spec.ScmInfo.CommitHash),
+					"scm-path":             aws.String(dep.Spec.ScmInfo.Path),
+				},
+			})
+			if err != nil {
+				log.Error(err, "failed to upload SBOM to s3")
+			}
+		}
 	}
 
 	controllerutil.RemoveFinalizer(pr, util.S3Finalizer)
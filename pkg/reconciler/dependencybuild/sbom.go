@@ -0,0 +1,132 @@
+package dependencybuild
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v12 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	ggcrtypes "github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	BuildSbomKey             = "sbom.json"
+	CycloneDXMediaType       = "application/vnd.cyclonedx+json"
+	sbomConfigMapNameSuffix  = "-sbom"
+	sbomNotAttachedEventName = "SbomNotAttached"
+	// SBOMs larger than this are only attached to the deployed image, as they would not fit in a ConfigMap
+	maxSbomConfigMapSize = 512 * 1024
+)
+
+// buildSbom returns the CycloneDX SBOM written by the deploy step, or an empty string if the build did not produce one
+func (r *ReconcileDependencyBuild) buildSbom(ctx context.Context, log logr.Logger, pr *pipelinev1beta1.PipelineRun) string {
+	for _, trs := range pr.Status.ChildReferences {
+		tr := pipelinev1beta1.TaskRun{}
+		err := r.client.Get(ctx, types.NamespacedName{Namespace: pr.Namespace, Name: trs.Name}, &tr)
+		if err != nil {
+			log.Error(err, "Unable to retrieve TaskRun to read the SBOM")
+			continue
+		}
+		for _, result := range tr.Status.Results {
+			if result.Name == artifactbuild.PipelineResultSbom && len(result.Value.StringVal) > 0 {
+				return result.Value.StringVal
+			}
+		}
+	}
+	return ""
+}
+
+// handleBuildSbom stores the SBOM of a successful build in a ConfigMap, and attaches it to the deployed image as an
+// OCI referrer. The SBOM is shared by all the artifacts produced by the build. Large SBOMs are only attached to the
+// image.
+func (r *ReconcileDependencyBuild) handleBuildSbom(ctx context.Context, log logr.Logger, pr *pipelinev1beta1.PipelineRun, db *v1alpha1.DependencyBuild, image string, digest string) (*v1alpha1.SBOMReference, error) {
+	sbom := r.buildSbom(ctx, log, pr)
+	if sbom == "" {
+		return nil, nil
+	}
+	sum := sha256.Sum256([]byte(sbom))
+	ref := &v1alpha1.SBOMReference{Digest: "sha256:" + hex.EncodeToString(sum[:])}
+	if len(sbom) <= maxSbomConfigMapSize {
+		cm := v1.ConfigMap{}
+		cm.Namespace = db.Namespace
+		cm.Name = pr.Name + sbomConfigMapNameSuffix
+		cm.Labels = map[string]string{artifactbuild.DependencyBuildIdLabel: db.Name}
+		cm.Data = map[string]string{BuildSbomKey: sbom}
+		if err := controllerutil.SetOwnerReference(db, &cm, r.scheme); err != nil {
+			return nil, err
+		}
+		if err := r.client.Create(ctx, &cm); err != nil && !errors.IsAlreadyExists(err) {
+			return nil, err
+		}
+		ref.ConfigMap = cm.Name
+	} else {
+		log.Info("SBOM is too large to store in a ConfigMap, it is only attached to the deployed image", "size", len(sbom))
+	}
+	if image == "" || digest == "" {
+		return ref, nil
+	}
+
+	jbsConfig := &v1alpha1.JBSConfig{}
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: db.Namespace, Name: v1alpha1.JBSConfigName}, jbsConfig)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	keychain, err := r.sharedRegistryKeychain(ctx, jbsConfig)
+	if err != nil {
+		return nil, err
+	}
+	referrer, err := attachSbom(ctx, image, digest, []byte(sbom), keychain)
+	if err != nil {
+		//the SBOM is still available from the S3 archive, and the ConfigMap if it was small enough, so this does not fail the build
+		log.Error(err, "Unable to attach the SBOM to the deployed image", "image", image)
+		r.eventRecorder.Eventf(db, v1.EventTypeWarning, sbomNotAttachedEventName, "The SBOM for DependencyBuild %s/%s could not be attached to %s: %s", db.Namespace, db.Name, image, err.Error())
+		return ref, nil
+	}
+	ref.Referrer = referrer
+	return ref, nil
+}
+
+// attachSbom pushes the SBOM as an OCI artifact with the deployed image as its subject, so it can be discovered
+// through the referrers API. Registries without the referrers API are handled by the fallback tag.
+func attachSbom(ctx context.Context, image string, digest string, sbom []byte, keychain authn.Keychain) (string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+	options := []remote.Option{remote.WithContext(ctx), remote.WithAuthFromKeychain(keychain)}
+	subject, err := remote.Head(ref.Context().Digest(digest), options...)
+	if err != nil {
+		return "", fmt.Errorf("unable to find deployed image %s@%s: %w", image, digest, err)
+	}
+	artifact, err := mutate.AppendLayers(empty.Image, static.NewLayer(sbom, CycloneDXMediaType))
+	if err != nil {
+		return "", err
+	}
+	artifact = mutate.MediaType(artifact, ggcrtypes.OCIManifestSchema1)
+	artifact = mutate.ConfigMediaType(artifact, CycloneDXMediaType)
+	artifact = mutate.Subject(artifact, *subject).(v12.Image)
+	artifactDigest, err := artifact.Digest()
+	if err != nil {
+		return "", err
+	}
+	dst := ref.Context().Digest(artifactDigest.String())
+	if err := remote.Write(dst, artifact, options...); err != nil {
+		return "", err
+	}
+	return dst.String(), nil
+}
@@ -0,0 +1,285 @@
+package dependencybuild
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	ScmTypeGit           = "git"
+	ScmTypeHg            = "hg"
+	ScmTypeSvn           = "svn"
+	ScmTypeSourceArchive = "source-archive"
+
+	sourceArchiveFile = "/tmp/source-archive"
+
+	PipelineResultSignatureVerification = "SIGNATURE_VERIFICATION"
+	// the exit code of the checkout step if the signature verification fails, used to fail the build without retrying
+	SignatureVerificationExitCode = 65
+	signatureKeyringVolume        = "signature-keyring"
+	signatureKeyringPath          = "/var/signature-keyring"
+)
+
+// scmProvider generates the shell commands that check out the source for a build. The checkout script is used by the
+// build pipeline, the diagnostic Dockerfile and (for everything except git) the build discovery pipeline, so it
+// must be a single command line that leaves the current directory at the root of the checkout.
+type scmProvider interface {
+	checkoutScript(jbsConfig *v1alpha1.JBSConfig, db *v1alpha1.DependencyBuild, recipe *v1alpha1.BuildRecipe, dir string) string
+	// the environment variables that the checkout script reads its credentials from
+	credentials() []v1.EnvVar
+}
+
+func scmProviderForType(scmType string) (scmProvider, error) {
+	switch scmType {
+	case "", ScmTypeGit:
+		return gitScmProvider{}, nil
+	case ScmTypeHg:
+		return hgScmProvider{}, nil
+	case ScmTypeSvn:
+		return svnScmProvider{}, nil
+	case ScmTypeSourceArchive:
+		return sourceArchiveScmProvider{}, nil
+	}
+	return nil, fmt.Errorf("unsupported SCM type %s", scmType)
+}
+
+func usernamePasswordCredentials(prefix string, secretName string) []v1.EnvVar {
+	trueBool := true
+	return []v1.EnvVar{
+		{Name: prefix + "_USERNAME", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: secretName}, Key: v1alpha1.ScmSecretUsernameKey, Optional: &trueBool}}},
+		{Name: prefix + "_PASSWORD", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: secretName}, Key: v1alpha1.ScmSecretPasswordKey, Optional: &trueBool}}},
+	}
+}
+
+// initGitRepository turns a non git checkout into a git repository, as parts of the build (e.g. the gradle tag
+// handling) expect one to be present
+func initGitRepository() string {
+	return " && git init -q && git add -A && git -c user.email=HACBS@redhat.com -c user.name=HACBS commit -q -m \"Source checkout\""
+}
+
+type gitScmProvider struct{}
+
+func (g gitScmProvider) checkoutScript(jbsConfig *v1alpha1.JBSConfig, db *v1alpha1.DependencyBuild, recipe *v1alpha1.BuildRecipe, dir string) string {
+	return gitArgs(jbsConfig, db, recipe, dir)
+}
+
+func (g gitScmProvider) credentials() []v1.EnvVar {
+	trueBool := true
+	return []v1.EnvVar{
+		{Name: "GIT_TOKEN", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: v1alpha1.GitSecretName}, Key: v1alpha1.GitSecretTokenKey, Optional: &trueBool}}},
+	}
+}
+
+func gitArgs(jbsConfig *v1alpha1.JBSConfig, db *v1alpha1.DependencyBuild, recipe *v1alpha1.BuildRecipe, dir string) string {
+	gitArgs := ""
+	if db.Spec.ScmInfo.Private {
+		gitArgs = "echo \"$GIT_TOKEN\"  > $HOME/.git-credentials\nchmod 400 $HOME/.git-credentials\n"
+		gitArgs = gitArgs + "echo '[credential]\n        helper=store\n' > $HOME/.gitconfig\n"
+	}
+	mirrors := make([]string, 0, len(jbsConfig.Spec.GitMirrors))
+	for k := range jbsConfig.Spec.GitMirrors {
+		mirrors = append(mirrors, k)
+	}
+	sort.Strings(mirrors)
+	//these are chained onto the clone command so they also work in the RUN line of the diagnostic Dockerfile
+	for _, i := range mirrors {
+		gitArgs = gitArgs + "git config --global url.\"" + jbsConfig.Spec.GitMirrors[i] + "\".insteadOf \"" + i + "\" && "
+	}
+	options := gitCloneOptions(jbsConfig, recipe)
+	if options.Depth > 0 {
+		//fetch just the commit we need, falling back to the tag if the server does not allow fetching by commit
+		depth := "--depth=" + strconv.Itoa(options.Depth)
+		gitArgs = gitArgs + "git init " + dir + " && cd " + dir + " && git remote add origin $(params." + PipelineParamScmUrl + ")" +
+			" && (git fetch " + depth + " origin $(params." + PipelineParamScmHash + ") || git fetch " + depth + " origin tag $(params." + PipelineParamScmTag + "))"
+	} else if options.SparseCheckout {
+		gitArgs = gitArgs + "git clone --no-checkout $(params." + PipelineParamScmUrl + ") " + dir + " && cd " + dir
+	} else {
+		gitArgs = gitArgs + "git clone $(params." + PipelineParamScmUrl + ") " + dir + " && cd " + dir
+	}
+	if options.SparseCheckout && db.Spec.ScmInfo.Path != "" {
+		gitArgs = gitArgs + " && git sparse-checkout set $(params." + PipelineParamPath + ")"
+	}
+	gitArgs = gitArgs + " && git reset --hard $(params." + PipelineParamScmHash + ")"
+	if options.LFS {
+		gitArgs = gitArgs + " && git lfs install --local && git lfs pull"
+	}
+
+	if !recipe.DisableSubmodules {
+		gitArgs = gitArgs + " && git submodule init && git submodule update --recursive"
+		if options.Depth > 0 {
+			gitArgs = gitArgs + " --depth=" + strconv.Itoa(options.Depth)
+		}
+	}
+	return gitArgs
+}
+
+// gitCloneOptions merges the clone options from the recipe with the defaults from the JBSConfig
+func gitCloneOptions(jbsConfig *v1alpha1.JBSConfig, recipe *v1alpha1.BuildRecipe) v1alpha1.GitCloneOptions {
+	options := jbsConfig.Spec.GitOptions
+	if recipe.GitOptions != nil {
+		if recipe.GitOptions.Depth > 0 {
+			options.Depth = recipe.GitOptions.Depth
+		}
+		options.SparseCheckout = options.SparseCheckout || recipe.GitOptions.SparseCheckout
+		options.LFS = options.LFS || recipe.GitOptions.LFS
+	}
+	return options
+}
+
+type hgScmProvider struct{}
+
+func (h hgScmProvider) checkoutScript(jbsConfig *v1alpha1.JBSConfig, db *v1alpha1.DependencyBuild, recipe *v1alpha1.BuildRecipe, dir string) string {
+	script := ""
+	if db.Spec.ScmInfo.Private {
+		script = "printf '[auth]\\nscm.prefix = *\\nscm.username = %s\\nscm.password = %s\\n' \"$HG_USERNAME\" \"$HG_PASSWORD\" > $HOME/.hgrc && chmod 400 $HOME/.hgrc && "
+	}
+	return script + "hg clone --noupdate \"$(params." + PipelineParamScmUrl + ")\" " + dir + " && cd " + dir + " && hg update --clean --rev \"$(params." + PipelineParamScmHash + ")\"" + initGitRepository()
+}
+
+func (h hgScmProvider) credentials() []v1.EnvVar {
+	return usernamePasswordCredentials("HG", v1alpha1.HgSecretName)
+}
+
+type svnScmProvider struct{}
+
+func (s svnScmProvider) checkoutScript(jbsConfig *v1alpha1.JBSConfig, db *v1alpha1.DependencyBuild, recipe *v1alpha1.BuildRecipe, dir string) string {
+	auth := ""
+	if db.Spec.ScmInfo.Private {
+		auth = " --username \"$SVN_USERNAME\" --password \"$SVN_PASSWORD\" --no-auth-cache"
+	}
+	//for svn the hash is the revision number, and the tag is part of the URL
+	return "svn checkout --non-interactive" + auth + " --revision \"$(params." + PipelineParamScmHash + ")\" \"$(params." + PipelineParamScmUrl + ")\" " + dir + " && cd " + dir + initGitRepository()
+}
+
+func (s svnScmProvider) credentials() []v1.EnvVar {
+	return usernamePasswordCredentials("SVN", v1alpha1.SvnSecretName)
+}
+
+type sourceArchiveScmProvider struct{}
+
+func (s sourceArchiveScmProvider) checkoutScript(jbsConfig *v1alpha1.JBSConfig, db *v1alpha1.DependencyBuild, recipe *v1alpha1.BuildRecipe, dir string) string {
+	auth := ""
+	if db.Spec.ScmInfo.Private {
+		auth = " --user \"$ARCHIVE_USERNAME:$ARCHIVE_PASSWORD\""
+	}
+	extracted := sourceArchiveFile + "-extracted"
+	//for source archives the hash is the sha256 of the archive
+	//if the archive contains a single top level directory (e.g. project-1.0/) then that becomes the checkout
+	return "curl --fail --silent --show-error --location" + auth + " --output " + sourceArchiveFile + " \"$(params." + PipelineParamScmUrl + ")\"" +
+		" && echo \"$(params." + PipelineParamScmHash + ") " + sourceArchiveFile + "\" | sha256sum --check -" +
+		" && mkdir -p " + extracted +
+		" && case \"$(params." + PipelineParamScmUrl + ")\" in *.zip) unzip -q " + sourceArchiveFile + " -d " + extracted + " ;; *) tar -xf " + sourceArchiveFile + " -C " + extracted + " ;; esac" +
+		" && if [ \"$(ls -A " + extracted + " | wc -l)\" = \"1\" ] && [ -d " + extracted + "/* ]; then mv " + extracted + "/* " + dir + "; else mv " + extracted + " " + dir + "; fi" +
+		" && cd " + dir + initGitRepository()
+}
+
+func (s sourceArchiveScmProvider) credentials() []v1.EnvVar {
+	return usernamePasswordCredentials("ARCHIVE", v1alpha1.SourceArchiveSecretName)
+}
+
+// scmCredentialsEnv returns the environment variables for the per host credentials in the JBSConfig
+func scmCredentialsEnv(jbsConfig *v1alpha1.JBSConfig) []v1.EnvVar {
+	trueBool := true
+	env := []v1.EnvVar{}
+	for c, i := range jbsConfig.Spec.ScmCredentials {
+		prefix := "SCM_CREDENTIALS_" + strconv.Itoa(c)
+		if i.Type == v1alpha1.ScmCredentialsTypeSsh {
+			env = append(env,
+				v1.EnvVar{Name: prefix + "_SSH_KEY", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: i.SecretName}, Key: v1alpha1.ScmSecretSshKey, Optional: &trueBool}}},
+				v1.EnvVar{Name: prefix + "_KNOWN_HOSTS", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: i.SecretName}, Key: v1alpha1.ScmSecretKnownHostsKey, Optional: &trueBool}}})
+		} else {
+			env = append(env, usernamePasswordCredentials(prefix, i.SecretName)...)
+		}
+	}
+	return env
+}
+
+// scmCredentialsScript installs the per host credentials from the JBSConfig. Basic auth credentials are added to the
+// git credential store, and GIT_TOKEN is updated to match so the build request processor also uses them. SSH keys
+// are added to the ssh config for their host, which is used by both git and the build request processor.
+func scmCredentialsScript(jbsConfig *v1alpha1.JBSConfig) string {
+	if len(jbsConfig.Spec.ScmCredentials) == 0 {
+		return ""
+	}
+	script := "touch $HOME/.git-credentials && chmod 600 $HOME/.git-credentials\n"
+	//the credential store expects the username and password to be percent encoded
+	script += "scm_urlencode() ( LC_ALL=C; s=\"$1\"; while [ -n \"$s\" ]; do c=\"${s%\"${s#?}\"}\"; s=\"${s#?}\"; case \"$c\" in [a-zA-Z0-9._~-]) printf '%s' \"$c\" ;; *) printf '%%%02X' \"'$c\" ;; esac; done )\n"
+	script += "if [ -n \"${GIT_TOKEN:-}\" ]; then echo \"$GIT_TOKEN\" > $HOME/.git-credentials; fi\n"
+	script += "mkdir -p $HOME/.ssh && chmod 700 $HOME/.ssh\n"
+	for c, i := range jbsConfig.Spec.ScmCredentials {
+		prefix := "$SCM_CREDENTIALS_" + strconv.Itoa(c)
+		if i.Type == v1alpha1.ScmCredentialsTypeSsh {
+			keyFile := "$HOME/.ssh/id_scm_" + strconv.Itoa(c)
+			script += "printf '%s\\n' \"" + prefix + "_SSH_KEY\" > " + keyFile + " && chmod 600 " + keyFile + "\n"
+			script += "printf '%s\\n' \"" + prefix + "_KNOWN_HOSTS\" >> $HOME/.ssh/known_hosts\n"
+			script += "printf 'Host %s\\n    IdentityFile %s\\n    IdentitiesOnly yes\\n' '" + i.Host + "' \"" + keyFile + "\" >> $HOME/.ssh/config\n"
+		} else {
+			script += "printf 'https://%s:%s@%s\\n' \"$(scm_urlencode \"" + prefix + "_USERNAME\")\" \"$(scm_urlencode \"" + prefix + "_PASSWORD\")\" '" + i.Host + "' >> $HOME/.git-credentials\n"
+		}
+	}
+	script += "printf '[credential]\\n    helper = store\\n' >> $HOME/.gitconfig\n"
+	script += "export GIT_TOKEN=\"$(cat $HOME/.git-credentials)\"\n"
+	return script
+}
+
+// gitMirrorUrl applies the GitMirrors rewrites to the SCM URL. Like git insteadOf the longest matching prefix wins.
+// This is used where the URL is not cloned by git, such as the JGit clone in the build request processor.
+func gitMirrorUrl(jbsConfig *v1alpha1.JBSConfig, scmUrl string) string {
+	prefix := ""
+	for k := range jbsConfig.Spec.GitMirrors {
+		if strings.HasPrefix(scmUrl, k) && len(k) > len(prefix) {
+			prefix = k
+		}
+	}
+	if prefix == "" {
+		return scmUrl
+	}
+	return jbsConfig.Spec.GitMirrors[prefix] + strings.TrimPrefix(scmUrl, prefix)
+}
+
+// withScmCredentials runs the per host credentials script before the given script, keeping any shebang line first
+func withScmCredentials(jbsConfig *v1alpha1.JBSConfig, script string) string {
+	credentials := scmCredentialsScript(jbsConfig)
+	if credentials == "" {
+		return script
+	}
+	if strings.HasPrefix(script, "#!") {
+		split := strings.SplitN(script, "\n", 2)
+		if len(split) == 2 {
+			return split[0] + "\n" + credentials + split[1]
+		}
+	}
+	return credentials + script
+}
+
+// signatureVerificationScript verifies the signature of the tag, or if the tag is not signed (or does not point at
+// the commit being built) the signature of the commit. It must run in the root of the git checkout.
+func signatureVerificationScript(policy *v1alpha1.SignatureVerificationPolicy) string {
+	script := ""
+	if policy.Type == v1alpha1.SignatureTypeSsh {
+		script = "git config --global gpg.ssh.allowedSignersFile " + signatureKeyringPath + "/" + v1alpha1.SignatureKeyringAllowedSignersKey + "\n"
+	} else {
+		script = "gpg --batch --quiet --import " + signatureKeyringPath + "/*\n"
+	}
+	result := "$(results." + PipelineResultSignatureVerification + ".path)"
+	script += "if [ -n \"$(params." + PipelineParamScmTag + ")\" ] && [ \"$(git rev-parse -q --verify \"refs/tags/$(params." + PipelineParamScmTag + ")^{commit}\")\" = \"$(params." + PipelineParamScmHash + ")\" ] && git verify-tag \"$(params." + PipelineParamScmTag + ")\"; then\n"
+	script += "  echo -n \"" + string(policy.Type) + ":tag\" > " + result + "\n"
+	script += "elif git verify-commit \"$(params." + PipelineParamScmHash + ")\"; then\n"
+	script += "  echo -n \"" + string(policy.Type) + ":commit\" > " + result + "\n"
+	script += "else\n"
+	script += "  echo \"Signature verification failed for $(params." + PipelineParamScmTag + ") $(params." + PipelineParamScmHash + ")\"\n"
+	script += "  exit " + strconv.Itoa(SignatureVerificationExitCode) + "\n"
+	script += "fi\n"
+	return script
+}
+
+// parseSignatureVerificationResult parses the result written by the signature verification script
+func parseSignatureVerificationResult(result string) *v1alpha1.SignatureVerificationResult {
+	signatureType, signedObject, _ := strings.Cut(result, ":")
+	return &v1alpha1.SignatureVerificationResult{Verified: true, Type: v1alpha1.SignatureType(signatureType), SignedObject: signedObject}
+}
@@ -0,0 +1,3 @@
+#record the checksums of the artifacts so the output of the two builds can be compared, maven metadata is generated at deploy time so it is ignored
+mkdir -p $(workspaces.source.path)/artifacts
+cd $(workspaces.source.path)/artifacts && find . -type f ! -name 'maven-metadata*' -exec sha256sum {} + | sed 's|  \./|  |' | sort -k2 > "{RESULT_PATH}"
@@ -36,6 +36,11 @@ echo "PATH:$PATH"
 #fix this when we no longer need to run as root
 export HOME=/root
 
+#SOURCE_DATE_EPOCH, LANG, LC_ALL and TZ are set in the environment so the output is reproducible
+umask {{UMASK}}
+
+{{TOOL_CACHES}}
+
 mkdir -p $(workspaces.source.path)/logs $(workspaces.source.path)/packages $(workspaces.source.path)/build-info
 
 {{INSTALL_PACKAGE_SCRIPT}}
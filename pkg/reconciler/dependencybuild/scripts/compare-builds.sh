@@ -0,0 +1,32 @@
+#!/usr/bin/env bash
+#compare the artifact checksums recorded by the two builds
+declare -A FIRST_SUMS
+while read -r sum file; do
+    [ -n "$file" ] || continue
+    FIRST_SUMS["$file"]=$sum
+done <<< "$FIRST_BUILD_CHECKSUMS"
+DIFFERENCES=""
+while read -r sum file; do
+    [ -n "$file" ] || continue
+    if [ -z "${FIRST_SUMS[$file]+x}" ]; then
+        DIFFERENCES+="OnlyInSecondBuild $file"$'\n'
+    elif [ "${FIRST_SUMS[$file]}" != "$sum" ]; then
+        DIFFERENCES+="ChecksumMismatch $file"$'\n'
+    fi
+    unset "FIRST_SUMS[$file]"
+done <<< "$SECOND_BUILD_CHECKSUMS"
+for file in "${!FIRST_SUMS[@]}"; do
+    DIFFERENCES+="OnlyInFirstBuild $file"$'\n'
+done
+REPRODUCIBILITY_LOG=$(mktemp)
+echo -n "$DIFFERENCES" | sort -k2 > "$REPRODUCIBILITY_LOG"
+COUNT=$(wc -l < "$REPRODUCIBILITY_LOG")
+#the result size is limited, the full list is in the logs
+{ echo "$COUNT"; head -n {MAX_DIFFERENCES} "$REPRODUCIBILITY_LOG"; } > "{RESULT_PATH}"
+if [ "$COUNT" -gt 0 ]; then
+    echo "$COUNT files differ between the two builds"
+    cat "$REPRODUCIBILITY_LOG"
+    if [ "{REQUIRED}" = "true" ]; then
+        exit {EXIT_CODE}
+    fi
+fi
@@ -1,5 +1,6 @@
 #!/usr/bin/env bash
-export GRADLE_USER_HOME="$(workspaces.build-settings.path)/.gradle"
+#GRADLE_USER_HOME_CACHE is set if the gradle tool cache is enabled
+export GRADLE_USER_HOME="${GRADLE_USER_HOME_CACHE:-$(workspaces.build-settings.path)/.gradle}"
 mkdir -p "${GRADLE_USER_HOME}"
 mkdir -p "$HOME/.m2/"
 
@@ -26,6 +27,10 @@ RELEASE_REPOSITORY_URL=file:$(workspaces.source.path)/artifacts
 RELEASE_SIGNING_ENABLED=false
 mavenCentralUsername=
 mavenCentralPassword=
+
+# Use the JDKs installed in the builder image for toolchains rather than downloading them
+org.gradle.java.installations.auto-download=false
+org.gradle.java.installations.paths={{JAVA_INSTALLATIONS}}
 EOF
 cat > "${GRADLE_USER_HOME}"/init.gradle << EOF
 allprojects {
@@ -63,6 +68,25 @@ settingsEvaluated { settings ->
 }
 EOF
 
+#reproducible archives are not the default, they are supported from Gradle 3.4
+case "${TOOL_VERSION}" in
+    [12].*|3.[0-3]|3.[0-3].*)
+        ;;
+    *)
+        if [ -n "${OUTPUT_TIMESTAMP:-}" ]; then
+            cat >> "${GRADLE_USER_HOME}"/init.gradle << EOF
+
+allprojects {
+    tasks.withType(AbstractArchiveTask) {
+        preserveFileTimestamps = false
+        reproducibleFileOrder = true
+    }
+}
+EOF
+        fi
+        ;;
+esac
+
 #if we run out of memory we want the JVM to die with error code 134
 export JAVA_OPTS="-XX:+CrashOnOutOfMemoryError"
 
@@ -93,9 +117,6 @@ case "${TOOL_VERSION}" in
         ;;
 esac
 
-export LANG="en_US.UTF-8"
-export LC_ALL="en_US.UTF-8"
-
 #we want to pass in additional params to GME
 #but not the actual goals
 #TODO: add GME params and remove this
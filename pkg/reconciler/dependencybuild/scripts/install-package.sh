@@ -4,19 +4,31 @@ set -o verbose
 if [ "rpm" = "{TYPE}" ]; then
     microdnf --setopt=install_weak_deps=0 --setopt=tsflags=nodocs install -y {PACKAGE_NAME}
 else
+    mkdir -p $(workspaces.source.path)/packages
     export PATH="$(workspaces.source.path)/packages:${PATH}"
 
-    wget --no-verbose --output-document=$(workspaces.source.path)/packages/{FILENAME} {URL} \
-    && echo "${SHA256} $(workspaces.source.path)/packages/{FILENAME}" | sha256sum --check -
+    wget --no-verbose --output-document=$(workspaces.source.path)/packages/{FILENAME} "{URI}"
+    echo "{SHA256} $(workspaces.source.path)/packages/{FILENAME}" | sha256sum --check -
 
-    if [ "executable" = "{TYPE}" ]; then
-        chmod +x $(workspaces.source.path)/packages/{FILENAME}
-    elif [  "tar" = "{TYPE}"  ]; then
-        mkdir $(workspaces.source.path)/packages/{FILENAME}-extracted
-        tar -xvf $(workspaces.source.path)/packages/{FILENAME} --directory $(workspaces.source.path)/packages/{FILENAME}-extracted
-        export PATH="$(workspaces.source.path)/packages/{FILENAME}-extracted/{BINARY_PATH}:${PATH}"
-    else
-        echo "Unknown Type {TYPE}"
-        exit 1
-    fi
+    case "{TYPE}" in
+        executable|maven)
+            chmod +x $(workspaces.source.path)/packages/{FILENAME}
+            ;;
+        zip)
+            mkdir -p $(workspaces.source.path)/packages/{FILENAME}-unzipped $(workspaces.source.path)/packages/{FILENAME}-extracted
+            unzip -q $(workspaces.source.path)/packages/{FILENAME} -d $(workspaces.source.path)/packages/{FILENAME}-unzipped
+            # unzip has no equivalent of --strip-components, so we move the entries at the right depth instead
+            find $(workspaces.source.path)/packages/{FILENAME}-unzipped -mindepth $(({STRIP_COMPONENTS} + 1)) -maxdepth $(({STRIP_COMPONENTS} + 1)) -exec mv {} $(workspaces.source.path)/packages/{FILENAME}-extracted/ \;
+            export PATH="$(workspaces.source.path)/packages/{FILENAME}-extracted/{BINARY_PATH}:${PATH}"
+            ;;
+        tar|tar.gz|tar.bz2|tar.xz)
+            mkdir -p $(workspaces.source.path)/packages/{FILENAME}-extracted
+            tar -xf $(workspaces.source.path)/packages/{FILENAME} --strip-components={STRIP_COMPONENTS} --directory $(workspaces.source.path)/packages/{FILENAME}-extracted
+            export PATH="$(workspaces.source.path)/packages/{FILENAME}-extracted/{BINARY_PATH}:${PATH}"
+            ;;
+        *)
+            echo "Unknown Type {TYPE}"
+            exit 1
+            ;;
+    esac
 fi
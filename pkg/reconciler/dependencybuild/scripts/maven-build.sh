@@ -14,34 +14,7 @@ fi
 TOOLCHAINS_XML="$(workspaces.build-settings.path)"/toolchains.xml
 
 cat >"$TOOLCHAINS_XML" <<EOF
-<?xml version="1.0" encoding="UTF-8"?>
-<toolchains>
-EOF
-
-if [ "$(params.JAVA_VERSION)" = "1.7" ]; then
-    JAVA_VERSIONS="1.7:1.7.0 1.8:1.8.0 11:11"
-else
-    JAVA_VERSIONS="1.8:1.8.0 11:11 17:17"
-fi
-
-for i in $JAVA_VERSIONS; do
-    version=$(echo $i | cut -d : -f 1)
-    home=$(echo $i | cut -d : -f 2)
-    cat >>"$TOOLCHAINS_XML" <<EOF
-  <toolchain>
-    <type>jdk</type>
-    <provides>
-      <version>$version</version>
-    </provides>
-    <configuration>
-      <jdkHome>/usr/lib/jvm/java-$home-openjdk</jdkHome>
-    </configuration>
-  </toolchain>
-EOF
-done
-
-cat >>"$TOOLCHAINS_XML" <<EOF
-</toolchains>
+{{TOOLCHAINS}}
 EOF
 
 
@@ -57,6 +30,11 @@ fi
 #if we run out of memory we want the JVM to die with error code 134
 export MAVEN_OPTS="-XX:+CrashOnOutOfMemoryError"
 
+#use the commit time for the timestamps in the archives, unless the recipe disables it
+if [ -n "${OUTPUT_TIMESTAMP:-}" ]; then
+  set -- "$@" "-Dproject.build.outputTimestamp=${OUTPUT_TIMESTAMP}"
+fi
+
 echo "Running Maven command with arguments: $@"
 
 if [ ! -d $(workspaces.source.path)/source ]; then
@@ -0,0 +1,25 @@
+RESOURCE_USAGE_START=$(date +%s)
+record_resource_usage() {
+    MEMORY=0
+    if [ -f /sys/fs/cgroup/memory.peak ]; then
+        MEMORY=$(cat /sys/fs/cgroup/memory.peak)
+    elif [ -f /sys/fs/cgroup/memory/memory.max_usage_in_bytes ]; then
+        MEMORY=$(cat /sys/fs/cgroup/memory/memory.max_usage_in_bytes)
+    fi
+    CPU_MICROS=0
+    if [ -f /sys/fs/cgroup/cpu.stat ]; then
+        while read -r key value; do
+            if [ "$key" = "usage_usec" ]; then
+                CPU_MICROS=$value
+            fi
+        done < /sys/fs/cgroup/cpu.stat
+    elif [ -f /sys/fs/cgroup/cpuacct/cpuacct.usage ]; then
+        CPU_MICROS=$(( $(cat /sys/fs/cgroup/cpuacct/cpuacct.usage) / 1000 ))
+    fi
+    ELAPSED=$(( $(date +%s) - RESOURCE_USAGE_START ))
+    if [ "$ELAPSED" -lt 1 ]; then
+        ELAPSED=1
+    fi
+    echo "{STEP} $MEMORY $(( CPU_MICROS / ELAPSED / 1000 ))" >> "{RESULT_PATH}"
+}
+trap record_resource_usage EXIT
@@ -0,0 +1,143 @@
+package dependencybuild
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/systemconfig"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// the prefix of the image labels the deploy step records the provenance of the build in
+	provenanceLabelPrefix = "io.jvmbuildservice."
+	ProvenanceScmUri      = "scm-uri"
+	ProvenanceScmCommit   = "scm-commit"
+	ProvenanceHermetic    = "hermetic"
+	ProvenanceBuildId     = "build-id"
+)
+
+// sharedRegistryTrustPolicy returns the trust policy of the shared registry the image is in, if it has one
+func sharedRegistryTrustPolicy(jbsConfig *v1alpha1.JBSConfig, image string) *v1alpha1.SharedRegistryTrustPolicy {
+	for _, registry := range jbsConfig.Spec.SharedRegistries {
+		host := registry.Host
+		if registry.Port != "" && registry.Port != "443" {
+			host += ":" + registry.Port
+		}
+		if registry.TrustPolicy != nil && strings.HasPrefix(image, host+"/"+registry.Owner+"/"+registry.Repository+":") {
+			return registry.TrustPolicy
+		}
+	}
+	return nil
+}
+
+// verifySharedImage checks that an image found in a shared registry can be reused. If the registry has a trust policy
+// the image must be signed by all the keys it lists and have the required provenance, otherwise if image signing is
+// enabled it must be signed with our own key. The cosign signature reference is returned if it was verified.
+func (r *ReconcileDependencyBuild) verifySharedImage(ctx context.Context, jbsConfig *v1alpha1.JBSConfig, db *v1alpha1.DependencyBuild, image string, digest string) (string, error) {
+	var keySecrets []string
+	var requiredProvenance []string
+	if policy := sharedRegistryTrustPolicy(jbsConfig, image); policy != nil {
+		keySecrets = policy.SignerKeySecrets
+		requiredProvenance = policy.RequiredProvenance
+	}
+	if len(keySecrets) == 0 && jbsConfig.Spec.ImageSigning != nil {
+		keySecrets = []string{jbsConfig.Spec.ImageSigning.KeySecret}
+	}
+	if len(keySecrets) == 0 && len(requiredProvenance) == 0 {
+		return "", nil
+	}
+	keychain, err := r.sharedRegistryKeychain(ctx, jbsConfig)
+	if err != nil {
+		return "", err
+	}
+	signature := ""
+	for _, keySecret := range keySecrets {
+		secret := v1.Secret{}
+		err := r.client.Get(ctx, types.NamespacedName{Namespace: jbsConfig.Namespace, Name: keySecret}, &secret)
+		if err != nil {
+			return "", err
+		}
+		signature, err = verifyImageSignature(ctx, image, digest, secret.Data[v1alpha1.CosignPublicKeyKey], keychain)
+		if err != nil {
+			return "", fmt.Errorf("signature verification with the key from %s failed: %w", keySecret, err)
+		}
+	}
+	if len(requiredProvenance) > 0 {
+		labels, err := imageLabels(ctx, image, digest, keychain)
+		if err != nil {
+			return "", err
+		}
+		if err := checkProvenance(labels, requiredProvenance, db); err != nil {
+			return "", err
+		}
+	}
+	return signature, nil
+}
+
+// checkProvenance checks the image has the required provenance labels, and that they match the DependencyBuild
+func checkProvenance(labels map[string]string, required []string, db *v1alpha1.DependencyBuild) error {
+	for _, field := range required {
+		value := labels[provenanceLabelPrefix+field]
+		if value == "" {
+			return fmt.Errorf("the image does not record the %s provenance", field)
+		}
+		switch field {
+		case ProvenanceScmUri:
+			if value != db.Spec.ScmInfo.SCMURL {
+				return fmt.Errorf("the image was built from %s rather than %s", value, db.Spec.ScmInfo.SCMURL)
+			}
+		case ProvenanceScmCommit:
+			if db.Spec.ScmInfo.CommitHash != "" && value != db.Spec.ScmInfo.CommitHash {
+				return fmt.Errorf("the image was built from commit %s rather than %s", value, db.Spec.ScmInfo.CommitHash)
+			}
+		case ProvenanceHermetic:
+			if value != "true" {
+				return fmt.Errorf("the image was not built hermetically")
+			}
+		}
+	}
+	return nil
+}
+
+func imageLabels(ctx context.Context, image string, digest string, keychain authn.Keychain) (map[string]string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+	img, err := remote.Image(ref.Context().Digest(digest), remote.WithContext(ctx), remote.WithAuthFromKeychain(keychain))
+	if err != nil {
+		return nil, err
+	}
+	config, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	return config.Config.Labels, nil
+}
+
+// sharedRegistryKeychain creates a keychain from the secrets of our own and the shared registries
+func (r *ReconcileDependencyBuild) sharedRegistryKeychain(ctx context.Context, jbsConfig *v1alpha1.JBSConfig) (authn.Keychain, error) {
+	var registrySecrets []v1.Secret
+	for _, registry := range append([]v1alpha1.ImageRegistry{jbsConfig.ImageRegistry()}, jbsConfig.Spec.SharedRegistries...) {
+		if registry.SecretName == "" {
+			continue
+		}
+		secret := v1.Secret{}
+		err := r.client.Get(ctx, types.NamespacedName{Namespace: jbsConfig.Namespace, Name: registry.SecretName}, &secret)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		registrySecrets = append(registrySecrets, secret)
+	}
+	return systemconfig.DockerConfigKeychain(registrySecrets)
+}
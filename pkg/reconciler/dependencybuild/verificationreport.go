@@ -0,0 +1,62 @@
+package dependencybuild
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-logr/logr"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// reports larger than this are stored in a ConfigMap rather than the DependencyBuild status
+	maxVerificationReportSize = 16 * 1024
+	VerificationReportKey     = "report.json"
+)
+
+// handleVerificationReport reads the verification report written by the verify step and records it on the build attempt.
+// It is read from the TaskRuns as the report is wanted for builds that failed verification as well.
+func (r *ReconcileDependencyBuild) handleVerificationReport(ctx context.Context, log logr.Logger, pr *pipelinev1beta1.PipelineRun, db *v1alpha1.DependencyBuild, attempt *v1alpha1.BuildAttempt) error {
+	for _, trs := range pr.Status.ChildReferences {
+		tr := pipelinev1beta1.TaskRun{}
+		err := r.client.Get(ctx, types.NamespacedName{Namespace: pr.Namespace, Name: trs.Name}, &tr)
+		if err != nil {
+			log.Error(err, "Unable to retrieve TaskRun to read the verification report")
+			continue
+		}
+		for _, result := range tr.Status.Results {
+			if result.Name != artifactbuild.PipelineResultVerificationReport || len(result.Value.StringVal) == 0 {
+				continue
+			}
+			report := v1alpha1.VerificationReport{}
+			if err := json.Unmarshal([]byte(result.Value.StringVal), &report); err != nil {
+				log.Error(err, "Unable to parse the verification report")
+				return nil
+			}
+			if len(result.Value.StringVal) <= maxVerificationReportSize {
+				attempt.VerificationReport = &report
+				return nil
+			}
+			cm := v1.ConfigMap{}
+			cm.Namespace = db.Namespace
+			cm.Name = pr.Name + "-verification"
+			cm.Labels = map[string]string{artifactbuild.DependencyBuildIdLabel: db.Name}
+			cm.Data = map[string]string{VerificationReportKey: result.Value.StringVal}
+			if err := controllerutil.SetOwnerReference(db, &cm, r.scheme); err != nil {
+				return err
+			}
+			if err := r.client.Create(ctx, &cm); err != nil && !errors.IsAlreadyExists(err) {
+				return err
+			}
+			attempt.VerificationReport = &v1alpha1.VerificationReport{ConfigMap: cm.Name}
+			return nil
+		}
+	}
+	return nil
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -173,7 +172,12 @@ func (r *ReconcileImageScan) handleStateNew(ctx context.Context, log logr.Logger
 		ia.Status.Message = "invalid image name"
 		return reconcile.Result{}, r.client.Status().Update(ctx, ia)
 	}
-	spec, err := r.createLookupPipeline(ctx, log, ia.Spec.Image)
+	jbsConfig := &v1alpha1.JBSConfig{}
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: ia.Namespace, Name: v1alpha1.JBSConfigName}, jbsConfig)
+	if err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	spec, err := r.createLookupPipeline(ctx, log, ia.Spec.Image, jbsConfig)
 	if err != nil {
 		ia.Status.State = v1alpha1.JvmImageScanStateFailed
 		ia.Status.Message = err.Error()
@@ -229,7 +233,7 @@ func (r *ReconcileImageScan) handleJavaDependencies(ctx context.Context, deps []
 	return r.client.Status().Update(ctx, ia)
 }
 
-func (r *ReconcileImageScan) createLookupPipeline(ctx context.Context, log logr.Logger, image string) (*pipelinev1beta1.PipelineSpec, error) {
+func (r *ReconcileImageScan) createLookupPipeline(ctx context.Context, log logr.Logger, image string, jbsConfig *v1alpha1.JBSConfig) (*pipelinev1beta1.PipelineSpec, error) {
 
 	buildReqProcessorImages, err := util.GetImageName(ctx, r.client, log, "build-request-processor", "JVM_BUILD_SERVICE_REQPROCESSOR_IMAGE")
 	if err != nil {
@@ -256,6 +260,14 @@ func (r *ReconcileImageScan) createLookupPipeline(ctx context.Context, log logr.
 	envVars := []corev1.EnvVar{
 		{Name: "JAVA_OPTS", Value: "-XX:+CrashOnOutOfMemoryError"},
 	}
+	syftResources, err := util.StepResources(jbsConfig, "run-syft", v1alpha1.StepResources{RequestMemory: "1024Mi", RequestCPU: "10m", LimitMemory: "1024Mi", LimitCPU: "300m"}, 0)
+	if err != nil {
+		return nil, err
+	}
+	analyzeResources, err := util.StepResources(jbsConfig, "analyze-dependencies", v1alpha1.StepResources{RequestMemory: "512Mi", RequestCPU: "10m", LimitMemory: "512Mi", LimitCPU: "300m"}, 0)
+	if err != nil {
+		return nil, err
+	}
 	//TODO: this pulls twice
 
 	return &pipelinev1beta1.PipelineSpec{
@@ -269,32 +281,24 @@ func (r *ReconcileImageScan) createLookupPipeline(ctx context.Context, log logr.
 						Results: []pipelinev1beta1.TaskResult{{Name: JvmDependenciesResult}},
 						Steps: []pipelinev1beta1.Step{
 							{
-								Name:            "run-syft",
-								Image:           "quay.io/redhat-appstudio/syft:v0.95.0", //TODO: hard coded
-								ImagePullPolicy: pullPolicy,
-								SecurityContext: &corev1.SecurityContext{RunAsUser: &zero},
-								Script:          "syft \"" + image + "\" --output cyclonedx-json=/data/syft.json",
-								ComputeResources: corev1.ResourceRequirements{
-									//TODO: make configurable
-									Requests: corev1.ResourceList{"memory": resource.MustParse("1024Mi")},
-									Limits:   corev1.ResourceList{"memory": resource.MustParse("1024Mi")},
-								},
-								Env:          envVars,
-								VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data"}},
+								Name:             "run-syft",
+								Image:            "quay.io/redhat-appstudio/syft:v0.95.0", //TODO: hard coded
+								ImagePullPolicy:  pullPolicy,
+								SecurityContext:  &corev1.SecurityContext{RunAsUser: &zero},
+								Script:           "syft \"" + image + "\" --output cyclonedx-json=/data/syft.json",
+								ComputeResources: syftResources,
+								Env:              envVars,
+								VolumeMounts:     []corev1.VolumeMount{{Name: "data", MountPath: "/data"}},
 							},
 							{
-								Name:            "analyze-dependencies",
-								Image:           buildReqProcessorImages,
-								ImagePullPolicy: pullPolicy,
-								SecurityContext: &corev1.SecurityContext{RunAsUser: &zero},
-								Args:            args,
-								ComputeResources: corev1.ResourceRequirements{
-									//TODO: make configurable
-									Requests: corev1.ResourceList{"memory": resource.MustParse("512Mi")},
-									Limits:   corev1.ResourceList{"memory": resource.MustParse("512Mi")},
-								},
-								Env:          envVars,
-								VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data"}},
+								Name:             "analyze-dependencies",
+								Image:            buildReqProcessorImages,
+								ImagePullPolicy:  pullPolicy,
+								SecurityContext:  &corev1.SecurityContext{RunAsUser: &zero},
+								Args:             args,
+								ComputeResources: analyzeResources,
+								Env:              envVars,
+								VolumeMounts:     []corev1.VolumeMount{{Name: "data", MountPath: "/data"}},
 							},
 						},
 					},
@@ -0,0 +1,68 @@
+package systemconfig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"k8s.io/utils/strings/slices"
+)
+
+var supportedArchitectures = []string{"amd64", "arm64", "ppc64le", "s390x"}
+
+// BuilderCapabilities returns the tools installed in a builder image. Builders that still use the old tag format
+// are converted, so the rest of the operator only has to deal with capabilities.
+func BuilderCapabilities(builder v1alpha1.BuilderImageInfo) ([]v1alpha1.BuilderCapability, error) {
+	if len(builder.Capabilities) > 0 {
+		return builder.Capabilities, nil
+	}
+	ret := []v1alpha1.BuilderCapability{}
+	if len(strings.TrimSpace(builder.Tag)) == 0 {
+		return ret, nil
+	}
+	for _, tag := range strings.Split(builder.Tag, ",") {
+		tool, versions, found := strings.Cut(strings.TrimSpace(tag), ":")
+		if !found || tool == "" || versions == "" {
+			return nil, fmt.Errorf("invalid tag entry '%s', expected tool:version[;version]", tag)
+		}
+		ret = append(ret, v1alpha1.BuilderCapability{Tool: tool, Versions: strings.Split(versions, ";")})
+	}
+	return ret, nil
+}
+
+// ValidateBuilder returns the problems with a builder image, or an empty list if it is valid
+func ValidateBuilder(builder v1alpha1.BuilderImageInfo) []string {
+	var messages []string
+	if len(strings.TrimSpace(builder.Image)) == 0 {
+		messages = append(messages, "missing image")
+	}
+	if len(builder.Capabilities) == 0 && len(strings.TrimSpace(builder.Tag)) == 0 {
+		messages = append(messages, "missing capabilities")
+	}
+	capabilities, err := BuilderCapabilities(builder)
+	if err != nil {
+		messages = append(messages, err.Error())
+	}
+	for _, capability := range capabilities {
+		if len(strings.TrimSpace(capability.Tool)) == 0 {
+			messages = append(messages, "capability without a tool")
+		}
+		if len(capability.Versions) == 0 {
+			messages = append(messages, fmt.Sprintf("capability %s has no versions", capability.Tool))
+		}
+		for _, version := range capability.Versions {
+			if len(strings.TrimSpace(version)) == 0 {
+				messages = append(messages, fmt.Sprintf("capability %s has an empty version", capability.Tool))
+			}
+		}
+		if capability.Architecture != "" && !slices.Contains(supportedArchitectures, capability.Architecture) {
+			messages = append(messages, fmt.Sprintf("capability %s has unsupported architecture %s", capability.Tool, capability.Architecture))
+		}
+	}
+	for _, path := range builder.ToolPaths {
+		if len(strings.TrimSpace(path.Tool)) == 0 || len(strings.TrimSpace(path.Version)) == 0 || len(strings.TrimSpace(path.Path)) == 0 {
+			messages = append(messages, "tool path without a tool, version and path")
+		}
+	}
+	return messages
+}
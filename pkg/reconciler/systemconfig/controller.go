@@ -2,11 +2,24 @@ package systemconfig
 
 import (
 	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 func SetupNewReconcilerWithManager(mgr ctrl.Manager) error {
 	r := newReconciler(mgr)
 	return ctrl.NewControllerManagedBy(mgr).For(&v1alpha1.SystemConfig{}).
+		//the cosign image is only resolved once a JBSConfig enables image signing
+		Watches(&source.Kind{Type: &v1alpha1.JBSConfig{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+			jbsConfig, ok := o.(*v1alpha1.JBSConfig)
+			if !ok || jbsConfig.Spec.ImageSigning == nil {
+				return []reconcile.Request{}
+			}
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: SystemConfigKey}}}
+		})).
 		Complete(r)
 }
@@ -0,0 +1,112 @@
+package systemconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// resolveImageDigest returns the digest the image currently points to
+func resolveImageDigest(ctx context.Context, image string, keychain authn.Keychain) (string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+	desc, err := remote.Head(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(keychain))
+	if err != nil {
+		return "", err
+	}
+	return desc.Digest.String(), nil
+}
+
+// PinnedImage returns the image reference with the tag replaced by the digest
+func PinnedImage(image string, digest string) (string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+	return ref.Context().Digest(digest).String(), nil
+}
+
+// CosignImage returns the cosign image configured in the SystemConfig
+func CosignImage(systemConfig *v1alpha1.SystemConfig) string {
+	if systemConfig.Spec.CosignImage != "" {
+		return systemConfig.Spec.CosignImage
+	}
+	return v1alpha1.DefaultCosignImage
+}
+
+// PinnedCosignImage returns the cosign image pinned to its digest, the image signing task is never run by tag
+func PinnedCosignImage(systemConfig *v1alpha1.SystemConfig) (string, error) {
+	image := CosignImage(systemConfig)
+	if strings.Contains(image, "@") {
+		return image, nil
+	}
+	if systemConfig.Status.CosignImageDigest == "" {
+		return "", fmt.Errorf("the cosign image %s has not been resolved to a digest", image)
+	}
+	return PinnedImage(image, systemConfig.Status.CosignImageDigest)
+}
+
+// pullSecretKeychain provides the credentials from docker config pull secrets, registries without credentials are
+// accessed anonymously
+type pullSecretKeychain struct {
+	auths map[string]authn.AuthConfig
+}
+
+func (k *pullSecretKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	if auth, ok := k.auths[target.RegistryStr()]; ok {
+		return authn.FromConfig(auth), nil
+	}
+	return authn.Anonymous, nil
+}
+
+func (r *ReconcilerSystemConfig) keychain(ctx context.Context, pullSecrets []string) (authn.Keychain, error) {
+	var secrets []v1.Secret
+	for _, secretName := range pullSecrets {
+		secret := v1.Secret{}
+		err := r.client.Get(ctx, types.NamespacedName{Namespace: util.ControllerNamespace, Name: secretName}, &secret)
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+	return DockerConfigKeychain(secrets)
+}
+
+// DockerConfigKeychain creates a keychain from the given docker config secrets
+func DockerConfigKeychain(secrets []v1.Secret) (authn.Keychain, error) {
+	keychain := &pullSecretKeychain{auths: map[string]authn.AuthConfig{}}
+	for _, secret := range secrets {
+		config := struct {
+			Auths map[string]authn.AuthConfig `json:"auths"`
+		}{}
+		err := json.Unmarshal(secret.Data[v1.DockerConfigJsonKey], &config)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse pull secret %s: %w", secret.Name, err)
+		}
+		for registry, auth := range config.Auths {
+			keychain.auths[registryHost(registry)] = auth
+		}
+	}
+	return keychain, nil
+}
+
+// registryHost normalises the registry keys used in docker config files, which may be URLs, to the registry host
+func registryHost(registry string) string {
+	registry = strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+	registry, _, _ = strings.Cut(registry, "/")
+	if registry == "docker.io" {
+		return name.DefaultRegistry
+	}
+	return registry
+}
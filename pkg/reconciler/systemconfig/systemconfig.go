@@ -3,9 +3,11 @@ package systemconfig
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
@@ -24,6 +26,7 @@ type ReconcilerSystemConfig struct {
 	eventRecorder record.EventRecorder
 	config        *rest.Config
 	mgr           ctrl.Manager
+	resolveDigest func(ctx context.Context, image string, keychain authn.Keychain) (string, error)
 }
 
 func newReconciler(mgr ctrl.Manager) reconcile.Reconciler {
@@ -33,6 +36,7 @@ func newReconciler(mgr ctrl.Manager) reconcile.Reconciler {
 		eventRecorder: mgr.GetEventRecorderFor("ArtifactBuild"),
 		config:        mgr.GetConfig(),
 		mgr:           mgr,
+		resolveDigest: resolveImageDigest,
 	}
 }
 
@@ -49,14 +53,58 @@ func (r *ReconcilerSystemConfig) Reconcile(ctx context.Context, request reconcil
 	if systemConfig.Name == SystemConfigKey {
 		logMsg := ""
 		logChunk := "jvm-build-service 'cluster' instance of its system config has incorrect builder related information %s\n"
+		keychain, err := r.keychain(ctx, systemConfig.Spec.ImagePullSecrets)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		status := map[string]v1alpha1.BuilderImageStatus{}
 		for key, bldr := range systemConfig.Spec.Builders {
-			if len(strings.TrimSpace(bldr.Image)) == 0 {
-				logMsg = logMsg + fmt.Sprintf(logChunk, key+" has missing image\n")
+			builderStatus := v1alpha1.BuilderImageStatus{Messages: ValidateBuilder(bldr)}
+			//there is no point looking up an image we already know is not usable
+			if len(builderStatus.Messages) == 0 {
+				digest, err := r.resolveDigest(ctx, bldr.Image, keychain)
+				if err != nil {
+					builderStatus.Messages = append(builderStatus.Messages, "unable to resolve image "+bldr.Image+": "+err.Error())
+				} else {
+					builderStatus.Reachable = true
+					builderStatus.Digest = digest
+				}
+			}
+			for _, msg := range builderStatus.Messages {
+				logMsg = logMsg + fmt.Sprintf(logChunk, key+": "+msg)
+			}
+			builderStatus.Valid = len(builderStatus.Messages) == 0
+			status[key] = builderStatus
+		}
+		//the image signing task is only run by digest, the image is only resolved if a JBSConfig signs images
+		cosignDigest := systemConfig.Status.CosignImageDigest
+		var cosignErr error
+		if cosignImage := CosignImage(&systemConfig); !strings.Contains(cosignImage, "@") {
+			signing, err := r.imageSigningEnabled(ctx)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			if signing {
+				digest, err := r.resolveDigest(ctx, cosignImage, keychain)
+				if err != nil {
+					//keep the previous digest so signing builds can still run, the reconcile is retried
+					cosignErr = fmt.Errorf("unable to resolve the cosign image %s: %w", cosignImage, err)
+				} else {
+					cosignDigest = digest
+				}
 			}
-			if len(strings.TrimSpace(bldr.Tag)) == 0 {
-				logMsg = logMsg + fmt.Sprintf(logChunk, key+" has missing tags\n")
+		}
+		if !reflect.DeepEqual(systemConfig.Status.Builders, status) || systemConfig.Status.CosignImageDigest != cosignDigest {
+			systemConfig.Status.Builders = status
+			systemConfig.Status.CosignImageDigest = cosignDigest
+			err = r.client.Status().Update(ctx, &systemConfig)
+			if err != nil {
+				return reconcile.Result{}, err
 			}
 		}
+		if cosignErr != nil {
+			return reconcile.Result{}, cosignErr
+		}
 		if len(logMsg) > 1 {
 			return reconcile.Result{}, fmt.Errorf(logMsg)
 		}
@@ -65,3 +113,17 @@ func (r *ReconcilerSystemConfig) Reconcile(ctx context.Context, request reconcil
 	}
 	return reconcile.Result{}, nil
 }
+
+// imageSigningEnabled checks if any JBSConfig in the cluster signs the images of its builds
+func (r *ReconcilerSystemConfig) imageSigningEnabled(ctx context.Context) (bool, error) {
+	jbsConfigs := v1alpha1.JBSConfigList{}
+	if err := r.client.List(ctx, &jbsConfigs); err != nil {
+		return false, err
+	}
+	for _, i := range jbsConfigs.Items {
+		if i.Spec.ImageSigning != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
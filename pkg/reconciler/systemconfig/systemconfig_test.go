@@ -2,10 +2,21 @@ package systemconfig
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	. "github.com/onsi/gomega"
 	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/util"
 	v1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 
 	v1 "k8s.io/api/core/v1"
@@ -24,24 +35,40 @@ func setupClientAndReconciler(objs ...runtimeclient.Object) (runtimeclient.Clien
 	_ = v1beta1.AddToScheme(scheme)
 	_ = v1.AddToScheme(scheme)
 	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
-	reconciler := &ReconcilerSystemConfig{client: client, scheme: scheme, eventRecorder: &record.FakeRecorder{}}
+	reconciler := &ReconcilerSystemConfig{client: client, scheme: scheme, eventRecorder: &record.FakeRecorder{}, resolveDigest: resolveImageDigest}
 	return client, reconciler
 }
 
+// testRegistryImage pushes a random image to an in-process registry and returns the reference and digest
+func testRegistryImage(t *testing.T, g *WithT) (string, string) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	image := strings.TrimPrefix(server.URL, "http://") + "/builder:latest"
+	img, err := random.Image(1024, 1)
+	g.Expect(err).NotTo(HaveOccurred())
+	ref, err := name.ParseReference(image)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(remote.Write(ref, img)).Should(Succeed())
+	digest, err := img.Digest()
+	g.Expect(err).NotTo(HaveOccurred())
+	return image, digest.String()
+}
+
 func TestValidSystemConfig(t *testing.T) {
 	g := NewGomegaWithT(t)
 	client, reconciler := setupClientAndReconciler()
+	image, digest := testRegistryImage(t, g)
 
 	validCfg := v1alpha1.SystemConfig{
 		Spec: v1alpha1.SystemConfigSpec{
 			Builders: map[string]v1alpha1.BuilderImageInfo{
 				"ubi7": {
-					Image: "foo",
-					Tag:   "bar",
+					Image: image,
+					Tag:   "jdk:7,maven:3.8.8",
 				},
 				"ubi8": {
-					Image: "foo",
-					Tag:   "bar",
+					Image:        image,
+					Capabilities: []v1alpha1.BuilderCapability{{Tool: "jdk", Versions: []string{"8", "11"}, InstallPath: "/usr/lib/jvm/java-{version}", Architecture: "amd64"}},
 				},
 			},
 		},
@@ -61,21 +88,70 @@ func TestValidSystemConfig(t *testing.T) {
 	})
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(result).NotTo(BeNil())
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: validCfg.Namespace, Name: validCfg.Name}, &validCfg)).Should(Succeed())
+	g.Expect(validCfg.Status.Builders).Should(Equal(map[string]v1alpha1.BuilderImageStatus{"ubi7": {Valid: true, Reachable: true, Digest: digest}, "ubi8": {Valid: true, Reachable: true, Digest: digest}}))
+	//no JBSConfig signs images, so the cosign image is not looked up
+	g.Expect(validCfg.Status.CosignImageDigest).Should(BeEmpty())
+}
+
+func TestCosignImageDigest(t *testing.T) {
+	g := NewGomegaWithT(t)
+	jbsConfig := &v1alpha1.JBSConfig{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: v1alpha1.JBSConfigName}}
+	jbsConfig.Spec.ImageSigning = &v1alpha1.ImageSigning{}
+	client, reconciler := setupClientAndReconciler(jbsConfig)
+	image, digest := testRegistryImage(t, g)
+
+	cfg := v1alpha1.SystemConfig{Spec: v1alpha1.SystemConfigSpec{CosignImage: image}}
+	cfg.Name = SystemConfigKey
+	ctx := context.TODO()
+	g.Expect(client.Create(ctx, &cfg)).Should(Succeed())
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: SystemConfigKey}}
+	_, err := reconciler.Reconcile(ctx, request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(client.Get(ctx, request.NamespacedName, &cfg)).Should(Succeed())
+	g.Expect(cfg.Status.CosignImageDigest).Should(Equal(digest))
+
+	//a failed lookup is retried and keeps the digest that is already known
+	reconciler.resolveDigest = func(ctx context.Context, image string, keychain authn.Keychain) (string, error) {
+		return "", fmt.Errorf("registry unavailable")
+	}
+	_, err = reconciler.Reconcile(ctx, request)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(client.Get(ctx, request.NamespacedName, &cfg)).Should(Succeed())
+	g.Expect(cfg.Status.CosignImageDigest).Should(Equal(digest))
+}
+
+func TestPinnedCosignImage(t *testing.T) {
+	g := NewGomegaWithT(t)
+	cfg := &v1alpha1.SystemConfig{}
+	g.Expect(CosignImage(cfg)).Should(Equal(v1alpha1.DefaultCosignImage))
+	//never run by tag
+	_, err := PinnedCosignImage(cfg)
+	g.Expect(err).To(HaveOccurred())
+
+	digest := "sha256:" + strings.Repeat("a", 64)
+	cfg.Status.CosignImageDigest = digest
+	g.Expect(PinnedCosignImage(cfg)).Should(Equal("gcr.io/projectsigstore/cosign@" + digest))
+
+	cfg.Spec.CosignImage = "quay.io/example/cosign@" + digest
+	cfg.Status.CosignImageDigest = ""
+	g.Expect(PinnedCosignImage(cfg)).Should(Equal("quay.io/example/cosign@" + digest))
 }
 
 func TestSystemConfigMissingImage(t *testing.T) {
 	g := NewGomegaWithT(t)
 	client, reconciler := setupClientAndReconciler()
+	image, _ := testRegistryImage(t, g)
 
 	validCfg := v1alpha1.SystemConfig{
 		Spec: v1alpha1.SystemConfigSpec{
 			Builders: map[string]v1alpha1.BuilderImageInfo{
 				"ubi7": {
-					Tag: "bar",
+					Tag: "jdk:7",
 				},
 				"ubi8": {
-					Image: "foo",
-					Tag:   "bar",
+					Image: image,
+					Tag:   "jdk:8",
 				},
 			},
 		},
@@ -100,16 +176,17 @@ func TestSystemConfigMissingImage(t *testing.T) {
 func TestSystemConfigMissingTag(t *testing.T) {
 	g := NewGomegaWithT(t)
 	client, reconciler := setupClientAndReconciler()
+	image, _ := testRegistryImage(t, g)
 
 	validCfg := v1alpha1.SystemConfig{
 		Spec: v1alpha1.SystemConfigSpec{
 			Builders: map[string]v1alpha1.BuilderImageInfo{
 				"ubi7": {
-					Image: "foo",
+					Image: image,
 				},
 				"ubi8": {
-					Image: "foo",
-					Tag:   "bar",
+					Image: image,
+					Tag:   "jdk:8",
 				},
 			},
 		},
@@ -130,3 +207,104 @@ func TestSystemConfigMissingTag(t *testing.T) {
 	g.Expect(err).To(HaveOccurred())
 	g.Expect(result).NotTo(BeNil())
 }
+
+func TestSystemConfigInvalidCapabilities(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+
+	invalidCfg := v1alpha1.SystemConfig{
+		Spec: v1alpha1.SystemConfigSpec{
+			Builders: map[string]v1alpha1.BuilderImageInfo{
+				"ubi7": {
+					Image: "foo",
+					Tag:   "bar",
+				},
+				"ubi8": {
+					Image:        "foo",
+					Capabilities: []v1alpha1.BuilderCapability{{Tool: "jdk", Architecture: "sparc"}},
+				},
+			},
+		},
+	}
+	invalidCfg.Name = SystemConfigKey
+
+	ctx := context.TODO()
+	g.Expect(client.Create(ctx, &invalidCfg)).Should(Succeed())
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: invalidCfg.Name}})
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(client.Get(ctx, types.NamespacedName{Name: invalidCfg.Name}, &invalidCfg)).Should(Succeed())
+	g.Expect(invalidCfg.Status.Builders["ubi7"]).Should(Equal(v1alpha1.BuilderImageStatus{Messages: []string{"invalid tag entry 'bar', expected tool:version[;version]"}}))
+	g.Expect(invalidCfg.Status.Builders["ubi8"]).Should(Equal(v1alpha1.BuilderImageStatus{Messages: []string{"capability jdk has no versions", "capability jdk has unsupported architecture sparc"}}))
+}
+
+func TestBuilderCapabilities(t *testing.T) {
+	g := NewGomegaWithT(t)
+	capabilities, err := BuilderCapabilities(v1alpha1.BuilderImageInfo{Tag: "jdk:8;11,maven:3.8.8"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(capabilities).Should(Equal([]v1alpha1.BuilderCapability{{Tool: "jdk", Versions: []string{"8", "11"}}, {Tool: "maven", Versions: []string{"3.8.8"}}}))
+
+	typed := []v1alpha1.BuilderCapability{{Tool: "gradle", Versions: []string{"8.0.2"}}}
+	capabilities, err = BuilderCapabilities(v1alpha1.BuilderImageInfo{Tag: "jdk:8", Capabilities: typed})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(capabilities).Should(Equal(typed))
+
+	_, err = BuilderCapabilities(v1alpha1.BuilderImageInfo{Tag: "jdk:8,maven"})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestSystemConfigUnreachableImage(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	image, _ := testRegistryImage(t, g)
+	missing := strings.Replace(image, "builder:latest", "buidler:latest", 1)
+
+	cfg := v1alpha1.SystemConfig{Spec: v1alpha1.SystemConfigSpec{Builders: map[string]v1alpha1.BuilderImageInfo{"jdk17": {Image: missing, Tag: "jdk:17"}}}}
+	cfg.Name = SystemConfigKey
+
+	ctx := context.TODO()
+	g.Expect(client.Create(ctx, &cfg)).Should(Succeed())
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: cfg.Name}})
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(client.Get(ctx, types.NamespacedName{Name: cfg.Name}, &cfg)).Should(Succeed())
+	g.Expect(cfg.Status.Builders["jdk17"].Valid).Should(BeFalse())
+	g.Expect(cfg.Status.Builders["jdk17"].Reachable).Should(BeFalse())
+	g.Expect(cfg.Status.Builders["jdk17"].Messages).Should(ConsistOf(HavePrefix("unable to resolve image " + missing)))
+}
+
+func TestSystemConfigPullSecret(t *testing.T) {
+	g := NewGomegaWithT(t)
+	image, digest := testRegistryImage(t, g)
+	host, _, _ := strings.Cut(image, "/")
+	dockerConfig := `{"auths":{"https://` + host + `/v1/":{"username":"builder","password":"secret"}}}`
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "builder-pull", Namespace: util.ControllerNamespace}, Data: map[string][]byte{v1.DockerConfigJsonKey: []byte(dockerConfig)}}
+	client, reconciler := setupClientAndReconciler(secret)
+
+	ctx := context.TODO()
+	keychain, err := reconciler.keychain(ctx, []string{"builder-pull"})
+	g.Expect(err).NotTo(HaveOccurred())
+	ref, err := name.ParseReference(image)
+	g.Expect(err).NotTo(HaveOccurred())
+	auth, err := keychain.Resolve(ref.Context())
+	g.Expect(err).NotTo(HaveOccurred())
+	config, err := auth.Authorization()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(config.Username).Should(Equal("builder"))
+	g.Expect(config.Password).Should(Equal("secret"))
+	auth, err = keychain.Resolve(name.MustParseReference("quay.io/redhat-appstudio/builder:latest").Context())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(auth).Should(Equal(authn.Anonymous))
+	g.Expect(registryHost("https://index.docker.io/v1/")).Should(Equal("index.docker.io"))
+	g.Expect(registryHost("docker.io")).Should(Equal("index.docker.io"))
+
+	cfg := v1alpha1.SystemConfig{Spec: v1alpha1.SystemConfigSpec{ImagePullSecrets: []string{"builder-pull"}, Builders: map[string]v1alpha1.BuilderImageInfo{"jdk17": {Image: image, Tag: "jdk:17"}}}}
+	cfg.Name = SystemConfigKey
+	g.Expect(client.Create(ctx, &cfg)).Should(Succeed())
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: cfg.Name}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(client.Get(ctx, types.NamespacedName{Name: cfg.Name}, &cfg)).Should(Succeed())
+	g.Expect(cfg.Status.Builders["jdk17"].Digest).Should(Equal(digest))
+
+	pinned, err := PinnedImage(image, digest)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pinned).Should(Equal(host + "/builder@" + digest))
+}
@@ -9,9 +9,12 @@ import (
 	"strings"
 
 	"github.com/go-logr/logr"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -37,6 +40,8 @@ var (
 	ImageTag  string
 	ImageRepo string
 	S3Enabled bool
+	// the directory the operator wide OSV vulnerability database is read from, empty if there isn't one
+	VulnerabilityDatabasePath string
 )
 
 func GetImageName(ctx context.Context, client client.Client, log logr.Logger, substr, envvar string) (string, error) {
@@ -87,3 +92,39 @@ func HashString(unique string) string {
 	depId := hex.EncodeToString(hash[:])
 	return depId
 }
+
+// StepResources returns the resources for a pipeline step. Values set for the step in the JBSConfig override the
+// defaults, and additionalMemory (in Mi) is added to both the memory request and limit.
+func StepResources(jbsConfig *v1alpha1.JBSConfig, step string, defaults v1alpha1.StepResources, additionalMemory int) (corev1.ResourceRequirements, error) {
+	configured := jbsConfig.Spec.BuildSettings.StepResources[step]
+	values := []string{
+		settingOrDefault(configured.RequestMemory, defaults.RequestMemory),
+		settingOrDefault(configured.RequestCPU, defaults.RequestCPU),
+		settingOrDefault(configured.LimitMemory, defaults.LimitMemory),
+		settingOrDefault(configured.LimitCPU, defaults.LimitCPU),
+	}
+	quantities := []resource.Quantity{}
+	for _, i := range values {
+		qty, err := resource.ParseQuantity(i)
+		if err != nil {
+			return corev1.ResourceRequirements{}, fmt.Errorf("invalid resources for step %s: %w", step, err)
+		}
+		quantities = append(quantities, qty)
+	}
+	if additionalMemory > 0 {
+		additional := resource.MustParse(fmt.Sprintf("%dMi", additionalMemory))
+		quantities[0].Add(additional)
+		quantities[2].Add(additional)
+	}
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceMemory: quantities[0], corev1.ResourceCPU: quantities[1]},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: quantities[2], corev1.ResourceCPU: quantities[3]},
+	}, nil
+}
+
+func settingOrDefault(setting, def string) string {
+	if len(strings.TrimSpace(setting)) == 0 {
+		return def
+	}
+	return setting
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/go-logr/logr"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
 	v1core "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -109,3 +110,32 @@ func TestGetImageName(t *testing.T) {
 		})
 	}
 }
+
+func TestStepResources(t *testing.T) {
+	jbsConfig := &v1alpha1.JBSConfig{}
+	jbsConfig.Spec.BuildSettings.StepResources = map[string]v1alpha1.StepResources{"build": {RequestMemory: "2Gi", LimitCPU: "2"}, "tag": {RequestCPU: "lots"}}
+	defaults := v1alpha1.StepResources{RequestMemory: "1024Mi", RequestCPU: "300m", LimitMemory: "1024Mi", LimitCPU: "300m"}
+
+	resources, err := StepResources(jbsConfig, "build", defaults, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resources.Requests.Memory().String() != "2148Mi" || resources.Limits.Memory().String() != "1124Mi" {
+		t.Errorf("unexpected memory %s %s", resources.Requests.Memory(), resources.Limits.Memory())
+	}
+	if resources.Requests.Cpu().String() != "300m" || resources.Limits.Cpu().String() != "2" {
+		t.Errorf("unexpected cpu %s %s", resources.Requests.Cpu(), resources.Limits.Cpu())
+	}
+
+	resources, err = StepResources(jbsConfig, "preprocessor", defaults, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resources.Limits.Memory().String() != "1Gi" {
+		t.Errorf("unexpected memory limit %s", resources.Limits.Memory())
+	}
+
+	if _, err = StepResources(jbsConfig, "tag", defaults, 0); err == nil {
+		t.Errorf("expected invalid quantity to fail")
+	}
+}
@@ -0,0 +1,41 @@
+package vulnerability
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+)
+
+// SetupNewReconcilerWithManager creates the reconciler that checks the RebuiltArtifacts and JvmImageScans of a namespace
+// against the vulnerability database. Every change is mapped to the namespace, as the whole namespace is checked
+// when the database changes.
+func SetupNewReconcilerWithManager(mgr ctrl.Manager, databasePath string) error {
+	r := newReconciler(mgr, databasePath)
+	toNamespace := handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+		return []reconcile.Request{
+			{
+				NamespacedName: types.NamespacedName{
+					Name:      v1alpha1.JBSConfigName,
+					Namespace: o.GetNamespace(),
+				},
+			},
+		}
+	})
+	return ctrl.NewControllerManagedBy(mgr).Named("vulnerability").
+		Watches(&source.Kind{Type: &v1alpha1.JBSConfig{}}, toNamespace).
+		Watches(&source.Kind{Type: &v1alpha1.RebuiltArtifact{}}, toNamespace).
+		Watches(&source.Kind{Type: &v1alpha1.JvmImageScan{}}, toNamespace).
+		Watches(&source.Kind{Type: &v1.ConfigMap{}}, toNamespace, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			_, ok := o.GetLabels()[VulnerabilityDatabaseLabel]
+			return ok
+		}))).
+		Complete(r)
+}
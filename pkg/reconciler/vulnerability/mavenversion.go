@@ -0,0 +1,237 @@
+package vulnerability
+
+import (
+	"strings"
+	"unicode"
+)
+
+// This is a port of Maven's ComparableVersion, so that versions are ordered the same way Maven orders them
+
+var (
+	mavenQualifiers = []string{"alpha", "beta", "milestone", "rc", "snapshot", "", "sp"}
+	mavenAliases    = map[string]string{"ga": "", "final": "", "release": "", "cr": "rc"}
+	// the comparable form of the empty qualifier, which is a release
+	mavenReleaseIndex = "5"
+)
+
+type versionItem interface {
+	compare(other versionItem) int
+	isNull() bool
+}
+
+type intItem string
+
+type stringItem string
+
+type listItem []versionItem
+
+func newIntItem(value string) intItem {
+	value = strings.TrimLeft(value, "0")
+	return intItem(value)
+}
+
+func newStringItem(value string, followedByDigit bool) stringItem {
+	if followedByDigit && len(value) == 1 {
+		switch value {
+		case "a":
+			value = "alpha"
+		case "b":
+			value = "beta"
+		case "m":
+			value = "milestone"
+		}
+	}
+	if alias, ok := mavenAliases[value]; ok {
+		value = alias
+	}
+	return stringItem(value)
+}
+
+func comparableQualifier(qualifier string) string {
+	for i, q := range mavenQualifiers {
+		if q == qualifier {
+			return string(rune('0' + i))
+		}
+	}
+	return mavenReleaseIndex + "-" + qualifier
+}
+
+func (i intItem) isNull() bool {
+	return i == ""
+}
+
+func (i intItem) compare(other versionItem) int {
+	switch o := other.(type) {
+	case nil:
+		if i.isNull() {
+			return 0
+		}
+		return 1
+	case intItem:
+		if len(i) != len(o) {
+			if len(i) < len(o) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(string(i), string(o))
+	case stringItem:
+		return 1
+	case listItem:
+		return 1
+	}
+	return 0
+}
+
+func (s stringItem) isNull() bool {
+	return comparableQualifier(string(s)) == mavenReleaseIndex
+}
+
+func (s stringItem) compare(other versionItem) int {
+	switch o := other.(type) {
+	case nil:
+		return strings.Compare(comparableQualifier(string(s)), mavenReleaseIndex)
+	case intItem:
+		return -1
+	case stringItem:
+		return strings.Compare(comparableQualifier(string(s)), comparableQualifier(string(o)))
+	case listItem:
+		return -1
+	}
+	return 0
+}
+
+func (l listItem) isNull() bool {
+	return len(l) == 0
+}
+
+func (l listItem) compare(other versionItem) int {
+	switch o := other.(type) {
+	case nil:
+		if len(l) == 0 {
+			return 0
+		}
+		return l[0].compare(nil)
+	case intItem:
+		return -1
+	case stringItem:
+		return 1
+	case listItem:
+		for i := 0; i < len(l) || i < len(o); i++ {
+			var left, right versionItem
+			if i < len(l) {
+				left = l[i]
+			}
+			if i < len(o) {
+				right = o[i]
+			}
+			result := 0
+			if left == nil {
+				if right != nil {
+					result = -1 * right.compare(nil)
+				}
+			} else {
+				result = left.compare(right)
+			}
+			if result != 0 {
+				return result
+			}
+		}
+	}
+	return 0
+}
+
+// versionList is used while parsing, as sub lists are added to their parent before they are complete
+type versionList struct {
+	items []versionItem
+	lists []*versionList
+}
+
+func (v *versionList) add(item versionItem) {
+	v.items = append(v.items, item)
+	v.lists = append(v.lists, nil)
+}
+
+func (v *versionList) addList() *versionList {
+	child := &versionList{}
+	v.items = append(v.items, nil)
+	v.lists = append(v.lists, child)
+	return child
+}
+
+// build converts the parsed list into a listItem, removing trailing null items the same way Maven normalizes them
+func (v *versionList) build() listItem {
+	ret := listItem{}
+	for i := range v.items {
+		if v.lists[i] != nil {
+			ret = append(ret, v.lists[i].build())
+		} else {
+			ret = append(ret, v.items[i])
+		}
+	}
+	for i := len(ret) - 1; i >= 0; i-- {
+		if ret[i].isNull() {
+			ret = append(ret[:i], ret[i+1:]...)
+		} else if _, ok := ret[i].(listItem); !ok {
+			break
+		}
+	}
+	return ret
+}
+
+func parseVersionItem(isDigit bool, value string) versionItem {
+	if isDigit {
+		return newIntItem(value)
+	}
+	return newStringItem(value, false)
+}
+
+func parseMavenVersion(version string) listItem {
+	version = strings.ToLower(version)
+	root := &versionList{}
+	list := root
+	isDigit := false
+	start := 0
+	for i, c := range version {
+		switch {
+		case c == '.':
+			if i == start {
+				list.add(intItem(""))
+			} else {
+				list.add(parseVersionItem(isDigit, version[start:i]))
+			}
+			start = i + 1
+		case c == '-':
+			if i == start {
+				list.add(intItem(""))
+			} else {
+				list.add(parseVersionItem(isDigit, version[start:i]))
+			}
+			start = i + 1
+			list = list.addList()
+		case unicode.IsDigit(c):
+			if !isDigit && i > start {
+				list.add(newStringItem(version[start:i], true))
+				start = i
+				list = list.addList()
+			}
+			isDigit = true
+		default:
+			if isDigit && i > start {
+				list.add(parseVersionItem(true, version[start:i]))
+				start = i
+				list = list.addList()
+			}
+			isDigit = false
+		}
+	}
+	if len(version) > start {
+		list.add(parseVersionItem(isDigit, version[start:]))
+	}
+	return root.build()
+}
+
+// compareMavenVersions compares two versions using Maven's ordering, returning -1, 0 or 1
+func compareMavenVersions(a string, b string) int {
+	return parseMavenVersion(a).compare(parseMavenVersion(b))
+}
@@ -0,0 +1,216 @@
+package vulnerability
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+)
+
+const (
+	mavenEcosystem = "Maven"
+	// the version OSV uses for a range that starts at the first release
+	osvInitialVersion = "0"
+)
+
+// osvRecord is the subset of the OSV schema that is used to match Maven artifacts, see https://ossf.github.io/osv-schema/
+type osvRecord struct {
+	ID        string        `json:"id"`
+	Aliases   []string      `json:"aliases"`
+	Summary   string        `json:"summary"`
+	Withdrawn string        `json:"withdrawn"`
+	Severity  []osvSeverity `json:"severity"`
+	Affected  []osvAffected `json:"affected"`
+	// GitHub advisories record the severity level here
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+type osvSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type osvAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges   []osvRange `json:"ranges"`
+	Versions []string   `json:"versions"`
+}
+
+type osvRange struct {
+	Type   string     `json:"type"`
+	Events []osvEvent `json:"events"`
+}
+
+type osvEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+func (e osvEvent) version() string {
+	switch {
+	case e.Introduced != "":
+		return e.Introduced
+	case e.Fixed != "":
+		return e.Fixed
+	case e.LastAffected != "":
+		return e.LastAffected
+	}
+	return e.Limit
+}
+
+// database is the OSV records indexed by the Maven package they affect
+type database struct {
+	records map[string][]*osvRecord
+	// identifies the content the database was loaded from, so resources are only checked again when it changes
+	digest string
+}
+
+func newDatabase() *database {
+	return &database{records: map[string][]*osvRecord{}}
+}
+
+// addRecords parses a single OSV record, or a JSON array of them, and adds the Maven records to the database
+func (d *database) addRecords(data []byte) error {
+	var records []*osvRecord
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return err
+		}
+	} else {
+		record := osvRecord{}
+		if err := json.Unmarshal(trimmed, &record); err != nil {
+			return err
+		}
+		records = append(records, &record)
+	}
+	for _, record := range records {
+		if record.Withdrawn != "" {
+			continue
+		}
+		packages := map[string]bool{}
+		for _, affected := range record.Affected {
+			if affected.Package.Ecosystem == mavenEcosystem && !packages[affected.Package.Name] {
+				packages[affected.Package.Name] = true
+				d.records[affected.Package.Name] = append(d.records[affected.Package.Name], record)
+			}
+		}
+	}
+	return nil
+}
+
+// vulnerabilities returns the vulnerabilities that affect a GAV, sorted by ID
+func (d *database) vulnerabilities(gav string) []v1alpha1.Vulnerability {
+	group, artifact, version, ok := splitGav(gav)
+	if !ok {
+		return nil
+	}
+	pkg := group + ":" + artifact
+	var ret []v1alpha1.Vulnerability
+	for _, record := range d.records[pkg] {
+		affected := false
+		var fixed []string
+		for _, a := range record.Affected {
+			if a.Package.Ecosystem != mavenEcosystem || a.Package.Name != pkg {
+				continue
+			}
+			if a.affects(version) {
+				affected = true
+			}
+			for _, r := range a.Ranges {
+				for _, e := range r.Events {
+					if e.Fixed != "" {
+						fixed = append(fixed, e.Fixed)
+					}
+				}
+			}
+		}
+		if !affected {
+			continue
+		}
+		sort.Slice(fixed, func(i, j int) bool {
+			return compareMavenVersions(fixed[i], fixed[j]) < 0
+		})
+		ret = append(ret, v1alpha1.Vulnerability{ID: record.ID, Aliases: record.Aliases, Summary: record.Summary, Severity: record.severity(), FixedVersions: fixed})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].ID < ret[j].ID
+	})
+	return ret
+}
+
+func (r *osvRecord) severity() string {
+	if r.DatabaseSpecific.Severity != "" {
+		return r.DatabaseSpecific.Severity
+	}
+	if len(r.Severity) > 0 {
+		return r.Severity[0].Score
+	}
+	return ""
+}
+
+// affects checks if a version is affected, either because it is listed explicitly or because it is in one of
+// the ranges. The range events are evaluated in version order as described by the OSV schema.
+func (a *osvAffected) affects(version string) bool {
+	for _, v := range a.Versions {
+		if compareMavenVersions(v, version) == 0 {
+			return true
+		}
+	}
+	for _, r := range a.Ranges {
+		if r.Type != "ECOSYSTEM" {
+			continue
+		}
+		events := append([]osvEvent{}, r.Events...)
+		sort.SliceStable(events, func(i, j int) bool {
+			return compareEventVersions(events[i].version(), events[j].version()) < 0
+		})
+		affected := false
+		for _, e := range events {
+			if e.Limit != "" && compareMavenVersions(version, e.Limit) >= 0 {
+				affected = false
+				break
+			} else if e.Introduced != "" && compareEventVersions(e.Introduced, version) <= 0 {
+				affected = true
+			} else if e.Fixed != "" && compareMavenVersions(e.Fixed, version) <= 0 {
+				affected = false
+			} else if e.LastAffected != "" && compareMavenVersions(e.LastAffected, version) < 0 {
+				affected = false
+			}
+		}
+		if affected {
+			return true
+		}
+	}
+	return false
+}
+
+// compareEventVersions compares two versions, treating the OSV initial version as lower than every other version
+func compareEventVersions(a string, b string) int {
+	if a == osvInitialVersion || b == osvInitialVersion {
+		if a == b {
+			return 0
+		} else if a == osvInitialVersion {
+			return -1
+		}
+		return 1
+	}
+	return compareMavenVersions(a, b)
+}
+
+func splitGav(gav string) (string, string, string, bool) {
+	parts := strings.Split(gav, ":")
+	if len(parts) != 3 {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
@@ -0,0 +1,74 @@
+package vulnerability
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+const rangeRecord = `{
+  "id": "GHSA-test-0001",
+  "aliases": ["CVE-2023-0001"],
+  "summary": "Remote code execution",
+  "database_specific": {"severity": "CRITICAL"},
+  "affected": [{
+    "package": {"ecosystem": "Maven", "name": "com.test:test"},
+    "ranges": [{
+      "type": "ECOSYSTEM",
+      "events": [{"introduced": "0"}, {"fixed": "1.2.1"}, {"introduced": "2.0.0-beta1"}, {"fixed": "2.0.3"}]
+    }]
+  }]
+}`
+
+func TestCompareMavenVersions(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ordered := []string{"1.0-alpha1", "1.0-beta", "1.0-milestone1", "1.0-rc1", "1.0-SNAPSHOT", "1.0", "1.0-sp1", "1.0.1", "1.1", "1.10"}
+	for i := 0; i < len(ordered)-1; i++ {
+		g.Expect(compareMavenVersions(ordered[i], ordered[i+1])).Should(Equal(-1), ordered[i]+" < "+ordered[i+1])
+		g.Expect(compareMavenVersions(ordered[i+1], ordered[i])).Should(Equal(1), ordered[i+1]+" > "+ordered[i])
+	}
+	for _, equal := range []string{"1.0.0", "1", "1.0-ga", "1.0.FINAL", "1-0"} {
+		g.Expect(compareMavenVersions("1.0", equal)).Should(Equal(0), "1.0 = "+equal)
+	}
+	g.Expect(compareMavenVersions("1.0-a1", "1.0-alpha-1")).Should(Equal(0))
+	g.Expect(compareMavenVersions("1.0.redhat-00001", "1.0")).Should(Equal(1))
+}
+
+func TestVulnerabilityRanges(t *testing.T) {
+	g := NewGomegaWithT(t)
+	db := newDatabase()
+	g.Expect(db.addRecords([]byte(rangeRecord))).Should(Succeed())
+
+	for _, version := range []string{"1.0", "1.2.0", "1.2.1-rc1", "2.0.0-beta1", "2.0.2"} {
+		vulnerabilities := db.vulnerabilities("com.test:test:" + version)
+		g.Expect(vulnerabilities).Should(HaveLen(1), version)
+		g.Expect(vulnerabilities[0].ID).Should(Equal("GHSA-test-0001"))
+		g.Expect(vulnerabilities[0].Severity).Should(Equal("CRITICAL"))
+		g.Expect(vulnerabilities[0].FixedVersions).Should(Equal([]string{"1.2.1", "2.0.3"}))
+	}
+	for _, version := range []string{"1.2.1", "1.5", "2.0.0-alpha1", "2.0.3", "3.0"} {
+		g.Expect(db.vulnerabilities("com.test:test:"+version)).Should(BeEmpty(), version)
+	}
+	g.Expect(db.vulnerabilities("com.test:other:1.0")).Should(BeEmpty())
+}
+
+func TestVulnerabilityLastAffectedAndVersions(t *testing.T) {
+	g := NewGomegaWithT(t)
+	db := newDatabase()
+	g.Expect(db.addRecords([]byte(`[
+  {"id": "OSV-2", "affected": [{"package": {"ecosystem": "Maven", "name": "com.test:test"},
+    "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "1.0"}, {"last_affected": "1.3"}]}]}]},
+  {"id": "OSV-1", "affected": [{"package": {"ecosystem": "Maven", "name": "com.test:test"}, "versions": ["1.5.0"]}]},
+  {"id": "OSV-3", "withdrawn": "2023-01-01T00:00:00Z", "affected": [{"package": {"ecosystem": "Maven", "name": "com.test:test"}, "versions": ["1.3"]}]},
+  {"id": "OSV-4", "affected": [{"package": {"ecosystem": "PyPI", "name": "com.test:test"}, "versions": ["1.3"]}]}
+]`))).Should(Succeed())
+	g.Expect(db.vulnerabilities("com.test:test:0.9")).Should(BeEmpty())
+	vulnerabilities := db.vulnerabilities("com.test:test:1.3")
+	g.Expect(vulnerabilities).Should(HaveLen(1))
+	g.Expect(vulnerabilities[0].ID).Should(Equal("OSV-2"))
+	g.Expect(db.vulnerabilities("com.test:test:1.3.1")).Should(BeEmpty())
+	vulnerabilities = db.vulnerabilities("com.test:test:1.5")
+	g.Expect(vulnerabilities).Should(HaveLen(1))
+	g.Expect(vulnerabilities[0].ID).Should(Equal("OSV-1"))
+	g.Expect(db.addRecords([]byte("not json"))).ShouldNot(Succeed())
+}
@@ -0,0 +1,282 @@
+package vulnerability
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+)
+
+const (
+	//TODO eventually we'll need to decide if we want to make this tuneable
+	contextTimeout = 300 * time.Second
+	// ConfigMaps with this label are watched, so that changes to the vulnerability database are seen
+	VulnerabilityDatabaseLabel = "jvmbuildservice.io/vulnerability-database"
+	// the operator database is on a volume that can't be watched, so it is checked for changes periodically
+	databaseRefreshInterval = 10 * time.Minute
+)
+
+type ReconcileVulnerabilities struct {
+	client        client.Client
+	scheme        *runtime.Scheme
+	eventRecorder record.EventRecorder
+	// the directory the operator wide OSV records are read from, normally a mounted PVC
+	databasePath string
+	// the operator database is only parsed again when the files change
+	operatorDatabase *database
+	operatorLock     sync.Mutex
+}
+
+func newReconciler(mgr ctrl.Manager, databasePath string) reconcile.Reconciler {
+	return &ReconcileVulnerabilities{
+		client:        mgr.GetClient(),
+		scheme:        mgr.GetScheme(),
+		eventRecorder: mgr.GetEventRecorderFor("Vulnerability"),
+		databasePath:  databasePath,
+	}
+}
+
+func (r *ReconcileVulnerabilities) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	var cancel context.CancelFunc
+	ctx, cancel = context.WithTimeout(ctx, contextTimeout)
+	defer cancel()
+	log := ctrl.Log.WithName("vulnerability").WithValues("namespace", request.Namespace)
+
+	jbsConfig := &v1alpha1.JBSConfig{}
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: request.Namespace, Name: v1alpha1.JBSConfigName}, jbsConfig)
+	if errors.IsNotFound(err) {
+		return reconcile.Result{}, nil
+	} else if err != nil {
+		return reconcile.Result{}, err
+	}
+	result := reconcile.Result{}
+	if r.databasePath != "" {
+		result.RequeueAfter = databaseRefreshInterval
+	}
+	databases, err := r.loadDatabases(ctx, log, jbsConfig)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if len(databases) == 0 {
+		return result, nil
+	}
+	digest := databases.digest()
+
+	ras := v1alpha1.RebuiltArtifactList{}
+	if err := r.client.List(ctx, &ras, client.InNamespace(request.Namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+	for i := range ras.Items {
+		ra := &ras.Items[i]
+		if ra.Status.VulnerabilityDatabaseDigest == digest {
+			continue
+		}
+		ra.Status.Vulnerabilities = databases.vulnerabilities(ra.Spec.GAV)
+		ra.Status.VulnerabilityDatabaseDigest = digest
+		if len(ra.Status.Vulnerabilities) > 0 {
+			log.Info(fmt.Sprintf("RebuiltArtifact %s is affected by %d vulnerabilities", ra.Name, len(ra.Status.Vulnerabilities)))
+		}
+		if err := r.client.Update(ctx, ra); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	scans := v1alpha1.JvmImageScanList{}
+	if err := r.client.List(ctx, &scans, client.InNamespace(request.Namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+	for i := range scans.Items {
+		scan := &scans.Items[i]
+		//the results are only known once the scan is complete
+		if scan.Status.State != v1alpha1.JvmImageScanStateComplete || scan.Status.VulnerabilityDatabaseDigest == digest {
+			continue
+		}
+		for j := range scan.Status.Results {
+			scan.Status.Results[j].Vulnerabilities = databases.vulnerabilities(scan.Status.Results[j].GAV)
+		}
+		scan.Status.VulnerabilityDatabaseDigest = digest
+		if err := r.client.Status().Update(ctx, scan); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return result, nil
+}
+
+// databaseList is the operator database and the namespace database, either of which may not be configured
+type databaseList []*database
+
+func (d databaseList) digest() string {
+	digests := []string{}
+	for _, i := range d {
+		digests = append(digests, i.digest)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(digests, ",")))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (d databaseList) vulnerabilities(gav string) []v1alpha1.Vulnerability {
+	var ret []v1alpha1.Vulnerability
+	seen := map[string]bool{}
+	for _, i := range d {
+		for _, v := range i.vulnerabilities(gav) {
+			if !seen[v.ID] {
+				seen[v.ID] = true
+				ret = append(ret, v)
+			}
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].ID < ret[j].ID
+	})
+	return ret
+}
+
+func (r *ReconcileVulnerabilities) loadDatabases(ctx context.Context, log logr.Logger, jbsConfig *v1alpha1.JBSConfig) (databaseList, error) {
+	databases := databaseList{}
+	if r.databasePath != "" {
+		operatorDatabase, err := r.loadOperatorDatabase(log)
+		if err != nil {
+			return nil, err
+		}
+		databases = append(databases, operatorDatabase)
+	}
+	if jbsConfig.Spec.VulnerabilityDatabase != nil && jbsConfig.Spec.VulnerabilityDatabase.ConfigMap != "" {
+		cm := v1.ConfigMap{}
+		err := r.client.Get(ctx, types.NamespacedName{Namespace: jbsConfig.Namespace, Name: jbsConfig.Spec.VulnerabilityDatabase.ConfigMap}, &cm)
+		if errors.IsNotFound(err) {
+			r.eventRecorder.Eventf(jbsConfig, v1.EventTypeWarning, "VulnerabilityDatabaseMissing", "The vulnerability database ConfigMap %s/%s does not exist", jbsConfig.Namespace, jbsConfig.Spec.VulnerabilityDatabase.ConfigMap)
+			return databases, nil
+		} else if err != nil {
+			return nil, err
+		}
+		databases = append(databases, configMapDatabase(log, &cm))
+	}
+	return databases, nil
+}
+
+// configMapDatabase loads the OSV records from every entry of the ConfigMap. Entries that can't be parsed are skipped.
+func configMapDatabase(log logr.Logger, cm *v1.ConfigMap) *database {
+	db := newDatabase()
+	keys := []string{}
+	for k := range cm.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	hash := sha256.New()
+	for _, k := range keys {
+		hash.Write([]byte(k))
+		hash.Write([]byte(cm.Data[k]))
+		if err := db.addRecords([]byte(cm.Data[k])); err != nil {
+			log.Error(err, "Unable to parse OSV record", "configMap", cm.Name, "key", k)
+		}
+	}
+	db.digest = hex.EncodeToString(hash.Sum(nil))
+	return db
+}
+
+// loadOperatorDatabase loads the OSV records from the JSON and zip files in the database directory, such as the
+// all.zip export from osv.dev. The records are only parsed again if the files have changed.
+func (r *ReconcileVulnerabilities) loadOperatorDatabase(log logr.Logger) (*database, error) {
+	r.operatorLock.Lock()
+	defer r.operatorLock.Unlock()
+	var files []string
+	hash := sha256.New()
+	err := filepath.WalkDir(r.databasePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || (!strings.HasSuffix(path, ".json") && !strings.HasSuffix(path, ".zip")) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, path)
+		hash.Write([]byte(fmt.Sprintf("%s:%d:%d,", path, info.Size(), info.ModTime().UnixNano())))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	digest := hex.EncodeToString(hash.Sum(nil))
+	if r.operatorDatabase != nil && r.operatorDatabase.digest == digest {
+		return r.operatorDatabase, nil
+	}
+	log.Info("Loading the vulnerability database", "path", r.databasePath)
+	db := newDatabase()
+	db.digest = digest
+	for _, file := range files {
+		if strings.HasSuffix(file, ".zip") {
+			err = addZipRecords(db, file)
+		} else {
+			err = addFileRecords(db, file)
+		}
+		if err != nil {
+			log.Error(err, "Unable to load OSV records", "file", file)
+		}
+	}
+	r.operatorDatabase = db
+	return db, nil
+}
+
+func addFileRecords(db *database, file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return db.addRecords(data)
+}
+
+// addZipRecords adds the records from every JSON file in the archive, a record that can't be parsed does not stop
+// the rest being loaded
+func addZipRecords(db *database, file string) error {
+	archive, err := zip.OpenReader(file)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+	var failed []string
+	for _, entry := range archive.File {
+		if !strings.HasSuffix(entry.Name, ".json") {
+			continue
+		}
+		reader, err := entry.Open()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(reader)
+		_ = reader.Close()
+		if err != nil {
+			return err
+		}
+		if err := db.addRecords(data); err != nil {
+			failed = append(failed, entry.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("unable to parse %d records: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
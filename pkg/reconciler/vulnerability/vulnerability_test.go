@@ -0,0 +1,184 @@
+package vulnerability
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	databaseName = "osv"
+	fixedRecord  = `{"id": "GHSA-test-0002", "affected": [{"package": {"ecosystem": "Maven", "name": "com.test:fixed"},
+  "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "2.0"}]}]}]}`
+)
+
+func setupClientAndReconciler(databasePath string, objs ...runtimeclient.Object) (runtimeclient.Client, *ReconcileVulnerabilities) {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	reconciler := &ReconcileVulnerabilities{
+		client:        client,
+		scheme:        scheme,
+		eventRecorder: &record.FakeRecorder{},
+		databasePath:  databasePath,
+	}
+	return client, reconciler
+}
+
+func setupJBSConfig() *v1alpha1.JBSConfig {
+	jbsConfig := v1alpha1.JBSConfig{}
+	jbsConfig.Namespace = metav1.NamespaceDefault
+	jbsConfig.Name = v1alpha1.JBSConfigName
+	jbsConfig.Spec.VulnerabilityDatabase = &v1alpha1.VulnerabilityDatabase{ConfigMap: databaseName}
+	return &jbsConfig
+}
+
+func setupDatabase(records ...string) *corev1.ConfigMap {
+	cm := corev1.ConfigMap{Data: map[string]string{}}
+	cm.Namespace = metav1.NamespaceDefault
+	cm.Name = databaseName
+	cm.Labels = map[string]string{VulnerabilityDatabaseLabel: "true"}
+	for i, record := range records {
+		cm.Data[string(rune('a'+i))+".json"] = record
+	}
+	return &cm
+}
+
+func setupRebuiltArtifact(name string, gav string) *v1alpha1.RebuiltArtifact {
+	ra := v1alpha1.RebuiltArtifact{}
+	ra.Namespace = metav1.NamespaceDefault
+	ra.Name = name
+	ra.Spec.GAV = gav
+	return &ra
+}
+
+func setupImageScan(state v1alpha1.JvmImageDependenciesState, gavs ...string) *v1alpha1.JvmImageScan {
+	scan := v1alpha1.JvmImageScan{}
+	scan.Namespace = metav1.NamespaceDefault
+	scan.Name = "scan"
+	scan.Spec.Image = "quay.io/test/image:latest"
+	scan.Status.State = state
+	for _, gav := range gavs {
+		scan.Status.Results = append(scan.Status.Results, v1alpha1.JavaDependency{GAV: gav, Source: "rebuilt"})
+	}
+	return &scan
+}
+
+func reconcileNamespace(g *WithT, reconciler *ReconcileVulnerabilities) reconcile.Result {
+	result, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: v1alpha1.JBSConfigName}})
+	g.Expect(err).NotTo(HaveOccurred())
+	return result
+}
+
+func getRebuiltArtifact(g *WithT, client runtimeclient.Client, name string) *v1alpha1.RebuiltArtifact {
+	ra := v1alpha1.RebuiltArtifact{}
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: name}, &ra)).Should(Succeed())
+	return &ra
+}
+
+func TestConfigMapDatabase(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler("", setupJBSConfig(), setupDatabase(rangeRecord),
+		setupRebuiltArtifact("vulnerable", "com.test:test:1.0"),
+		setupRebuiltArtifact("fixed", "com.test:test:1.2.1"),
+		setupRebuiltArtifact("other", "com.test:fixed:1.0"),
+		setupImageScan(v1alpha1.JvmImageScanStateComplete, "com.test:test:2.0.0", "com.test:test:3.0"))
+	result := reconcileNamespace(g, reconciler)
+	g.Expect(result.RequeueAfter).Should(BeZero())
+
+	ra := getRebuiltArtifact(g, client, "vulnerable")
+	g.Expect(ra.Status.Vulnerabilities).Should(HaveLen(1))
+	g.Expect(ra.Status.Vulnerabilities[0].ID).Should(Equal("GHSA-test-0001"))
+	g.Expect(ra.Status.Vulnerabilities[0].Aliases).Should(Equal([]string{"CVE-2023-0001"}))
+	g.Expect(ra.Status.VulnerabilityDatabaseDigest).ShouldNot(BeEmpty())
+	digest := ra.Status.VulnerabilityDatabaseDigest
+	ra = getRebuiltArtifact(g, client, "fixed")
+	g.Expect(ra.Status.Vulnerabilities).Should(BeEmpty())
+	g.Expect(ra.Status.VulnerabilityDatabaseDigest).Should(Equal(digest))
+
+	scan := v1alpha1.JvmImageScan{}
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: "scan"}, &scan)).Should(Succeed())
+	g.Expect(scan.Status.VulnerabilityDatabaseDigest).Should(Equal(digest))
+	g.Expect(scan.Status.Results[0].Vulnerabilities).Should(HaveLen(1))
+	g.Expect(scan.Status.Results[1].Vulnerabilities).Should(BeEmpty())
+
+	t.Run("database update is re-evaluated", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		cm := corev1.ConfigMap{}
+		g.Expect(client.Get(context.TODO(), types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: databaseName}, &cm)).Should(Succeed())
+		cm.Data["b.json"] = fixedRecord
+		g.Expect(client.Update(context.TODO(), &cm)).Should(Succeed())
+		reconcileNamespace(g, reconciler)
+
+		ra := getRebuiltArtifact(g, client, "other")
+		g.Expect(ra.Status.Vulnerabilities).Should(HaveLen(1))
+		g.Expect(ra.Status.Vulnerabilities[0].ID).Should(Equal("GHSA-test-0002"))
+		g.Expect(ra.Status.Vulnerabilities[0].FixedVersions).Should(Equal([]string{"2.0"}))
+		g.Expect(ra.Status.VulnerabilityDatabaseDigest).ShouldNot(Equal(digest))
+		g.Expect(getRebuiltArtifact(g, client, "vulnerable").Status.Vulnerabilities).Should(HaveLen(1))
+	})
+}
+
+func TestImageScanNotComplete(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler("", setupJBSConfig(), setupDatabase(rangeRecord), setupImageScan(v1alpha1.JvmImageScanStateNew))
+	reconcileNamespace(g, reconciler)
+	scan := v1alpha1.JvmImageScan{}
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: "scan"}, &scan)).Should(Succeed())
+	g.Expect(scan.Status.VulnerabilityDatabaseDigest).Should(BeEmpty())
+}
+
+func TestMissingConfigMapDatabase(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler("", setupJBSConfig(), setupRebuiltArtifact("vulnerable", "com.test:test:1.0"))
+	reconcileNamespace(g, reconciler)
+	g.Expect(getRebuiltArtifact(g, client, "vulnerable").Status.VulnerabilityDatabaseDigest).Should(BeEmpty())
+}
+
+func TestOperatorDatabase(t *testing.T) {
+	g := NewGomegaWithT(t)
+	dir := t.TempDir()
+	g.Expect(os.WriteFile(filepath.Join(dir, "record.json"), []byte(fixedRecord), 0644)).Should(Succeed())
+	archive, err := os.Create(filepath.Join(dir, "all.zip"))
+	g.Expect(err).NotTo(HaveOccurred())
+	writer := zip.NewWriter(archive)
+	entry, err := writer.Create("GHSA-test-0001.json")
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = entry.Write([]byte(rangeRecord))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(writer.Close()).Should(Succeed())
+	g.Expect(archive.Close()).Should(Succeed())
+
+	jbsConfig := setupJBSConfig()
+	jbsConfig.Spec.VulnerabilityDatabase = nil
+	client, reconciler := setupClientAndReconciler(dir, jbsConfig,
+		setupRebuiltArtifact("vulnerable", "com.test:test:1.0"),
+		setupRebuiltArtifact("other", "com.test:fixed:1.0"))
+	result := reconcileNamespace(g, reconciler)
+	g.Expect(result.RequeueAfter).Should(Equal(databaseRefreshInterval))
+	g.Expect(getRebuiltArtifact(g, client, "vulnerable").Status.Vulnerabilities).Should(HaveLen(1))
+	g.Expect(getRebuiltArtifact(g, client, "other").Status.Vulnerabilities).Should(HaveLen(1))
+
+	//the database is only loaded again when the files change
+	loaded := reconciler.operatorDatabase
+	reconcileNamespace(g, reconciler)
+	g.Expect(reconciler.operatorDatabase).Should(BeIdenticalTo(loaded))
+	g.Expect(os.Remove(filepath.Join(dir, "record.json"))).Should(Succeed())
+	reconcileNamespace(g, reconciler)
+	g.Expect(reconciler.operatorDatabase).ShouldNot(BeIdenticalTo(loaded))
+	g.Expect(getRebuiltArtifact(g, client, "other").Status.Vulnerabilities).Should(BeEmpty())
+}